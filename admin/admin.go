@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+// Package admin tells the site's admin apart from everyone else, by
+// ADMIN_TOKEN on the API and by a session cookie on the admin pages.
+package admin
+
+import (
+    "crypto/hmac"
+    "crypto/sha256"
+    "crypto/subtle"
+    "encoding/base64"
+    "strconv"
+    "strings"
+    "time"
+
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+    "github.com/gofiber/fiber/v3"
+)
+
+const (
+    sessionCookie = "admin_session"
+    sessionTTL    = 12 * time.Hour
+    // sessionPath is where the session cookie is sent to, the admin pages.
+    // The API only takes the bearer token.
+    sessionPath = "/analytics"
+)
+
+// ValidToken tells whether given is `ADMIN_TOKEN`. Nothing is while it
+// isn't set.
+func ValidToken(given string) bool {
+    var token = types.EVAdminToken.Get().Value
+    return len(token) != 0 && subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
+
+// StartSession signs whoever sent c in to the admin pages, once they gave
+// ADMIN_TOKEN, with a cookie that lasts sessionTTL. The cookie looks like
+// `<expiry>.<signature>` and is signed with the token itself, so changing
+// the token signs everyone out.
+func StartSession(c fiber.Ctx) {
+    var expires = time.Now().Add(sessionTTL)
+    var unix = strconv.FormatInt(expires.Unix(), 10)
+
+    c.Cookie(&fiber.Cookie{
+        Name:     sessionCookie,
+        Value:    unix + "." + signSession(unix),
+        Path:     sessionPath,
+        Expires:  expires,
+        HTTPOnly: true,
+        Secure:   types.EVEnv.Get().Value == types.EMProd.String(),
+        SameSite: fiber.CookieSameSiteStrictMode,
+    })
+}
+
+// EndSession signs whoever sent c out of the admin pages.
+func EndSession(c fiber.Ctx) {
+    c.Cookie(&fiber.Cookie{
+        Name:     sessionCookie,
+        Path:     sessionPath,
+        Expires:  time.Unix(0, 0),
+        HTTPOnly: true,
+        Secure:   types.EVEnv.Get().Value == types.EMProd.String(),
+        SameSite: fiber.CookieSameSiteStrictMode,
+    })
+}
+
+// HasSession tells whether c comes from someone signed in to the admin
+// pages with StartSession.
+func HasSession(c fiber.Ctx) bool {
+    if len(types.EVAdminToken.Get().Value) == 0 {
+        return false
+    }
+
+    var unix, signature, ok = strings.Cut(c.Cookies(sessionCookie), ".")
+    if !ok {
+        return false
+    }
+    if expires, err := strconv.ParseInt(unix, 10, 64); err != nil || time.Now().Unix() > expires {
+        return false
+    }
+    return hmac.Equal([]byte(signature), []byte(signSession(unix)))
+}
+
+func signSession(expires string) string {
+    var mac = hmac.New(sha256.New, []byte(types.EVAdminToken.Get().Value))
+    mac.Write([]byte(sessionCookie + "\x00" + expires))
+    return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
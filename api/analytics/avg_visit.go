@@ -5,6 +5,7 @@ package analytics
 
 import (
     "strconv"
+    "time"
 
     "git.jelius.dev/jelius-sama/Portfolio/db"
     "git.jelius.dev/jelius-sama/Portfolio/types"
@@ -24,23 +25,45 @@ func GetAvgVisitsPerHour(c fiber.Ctx) error {
         return c.Status(fiber.StatusBadRequest).JSON(*parseErr)
     }
 
-    var hours, hrsParseErr = strconv.Atoi(hoursStr)
-    if hrsParseErr != nil || hours < 1 {
-        return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
-            Code:    fiber.StatusBadRequest,
-            Message: "hours must be a positive integer",
-        })
+    var filter, filterErr = parseReportFilter(c)
+    if filterErr != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(*filterErr)
+    }
+
+    // Without from, the window is the current hour and the hours-1 before
+    // it. Either way it is made of whole hours, as the rollups can't tell
+    // apart the minutes of an hour.
+    var hours int
+    if filter.from.IsZero() {
+        var hrsParseErr error
+        if hours, hrsParseErr = strconv.Atoi(hoursStr); hrsParseErr != nil || hours < 1 {
+            return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
+                Code:    fiber.StatusBadRequest,
+                Message: "hours must be a positive integer",
+            })
+        }
+        filter.from = time.Now().UTC().Truncate(time.Hour).Add(-time.Duration(hours-1) * time.Hour)
+    } else {
+        var to = filter.to
+        if to.IsZero() {
+            to = time.Now().UTC()
+        }
+        hours = int(to.Add(time.Hour-1).Truncate(time.Hour).Sub(filter.from.Truncate(time.Hour)) / time.Hour)
     }
 
+    var visits, visitArgs = hourlyVisits(includeBots(c), filter)
+
     // Get total count
     var countQuery = `
-        SELECT COUNT(*)
-        FROM analytics_events
-        WHERE timestamp >= datetime('now', '-' || ? || ' hours')
+        SELECT COUNT(*) FROM (
+            SELECT 1
+            FROM ` + visits + `
+            GROUP BY hour, country_code, page_path
+        )
     `
 
     var totalRows int
-    if err := db.DB.QueryRow(countQuery, hours).Scan(&totalRows); err != nil {
+    if err := db.DB.QueryRow(countQuery, visitArgs...).Scan(&totalRows); err != nil {
         logger.Error(c.Path(), err.Error())
         return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
             Code:    fiber.StatusInternalServerError,
@@ -59,19 +82,18 @@ func GetAvgVisitsPerHour(c fiber.Ctx) error {
 
     var query = `
         SELECT 
-            strftime('%Y-%m-%d %H:00:00', timestamp) as time_window,
+            hour as time_window,
             country_code,
             page_path,
-            COUNT(*) as visit_count,
-            strftime('%Y-%m-%d', timestamp) as date
-        FROM analytics_events
-        WHERE timestamp >= datetime('now', '-' || ? || ' hours')
+            SUM(visits) as visit_count,
+            date(hour) as date
+        FROM ` + visits + `
         GROUP BY time_window, country_code, page_path
         ORDER BY time_window ` + sortDir + `, country_code
         LIMIT ? OFFSET ?
     `
 
-    var rows, queryErr = db.DB.Query(query, hours, limit, offset)
+    var rows, queryErr = db.DB.Query(query, append(visitArgs, limit, offset)...)
     if queryErr != nil {
         logger.Error(c.Path(), queryErr.Error())
         return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
@@ -98,6 +120,12 @@ func GetAvgVisitsPerHour(c fiber.Ctx) error {
                 Message: "Internal Server Error",
             })
         }
+        // Hours are grouped in UTC, which are whole hours in the zones that
+        // are a whole number of hours off it as well
+        if hour, err := time.Parse(time.DateTime, resp.TimeWindow); err == nil {
+            hour = hour.In(filter.loc)
+            resp.TimeWindow, resp.Date = hour.Format(time.DateTime), hour.Format(time.DateOnly)
+        }
         resp.AvgVisits = float64(visitCount) / float64(hours)
         data = append(data, resp)
     }
@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package analytics
+
+import (
+    "fmt"
+    "net/netip"
+    "strings"
+    "sync"
+    "time"
+
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+    "github.com/gofiber/fiber/v3"
+    "github.com/jelius-sama/logger"
+)
+
+// botRateLimit is how many hits an address can send in a minute before the
+// rest are taken for a bot. The tracking script waits a few seconds on every
+// page, so a person doesn't come close even with a few of them sharing an
+// address behind NAT.
+const botRateLimit = 60
+
+// botHit is why a hit was taken for a bot, with what gave it away.
+type botHit struct {
+    reason types.BotReason
+    detail string
+}
+
+// ipDenylist is BOT_IP_DENYLIST, a comma separated list of addresses and
+// CIDR ranges, parsed once.
+var ipDenylist = sync.OnceValue(func() []netip.Prefix {
+    var prefixes []netip.Prefix
+    for entry := range strings.SplitSeq(types.EVBotIPDenylist.Get().Value, ",") {
+        if entry = strings.TrimSpace(entry); len(entry) == 0 {
+            continue
+        }
+
+        if prefix, err := netip.ParsePrefix(entry); err == nil {
+            prefixes = append(prefixes, prefix.Masked())
+        } else if addr, err := netip.ParseAddr(entry); err == nil {
+            prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+        } else {
+            logger.Error("Ignoring invalid entry in", types.EVBotIPDenylist.Get().Key+":", entry)
+        }
+    }
+    return prefixes
+})
+
+// ipHits counts the hits of every address in the current minute. It is
+// emptied as each minute starts, so addresses are never kept any longer.
+var ipHits struct {
+    mu     sync.Mutex
+    minute time.Time
+    counts map[string]int
+}
+
+func countHit(ip string, now time.Time) int {
+    ipHits.mu.Lock()
+    defer ipHits.mu.Unlock()
+
+    if minute := now.Truncate(time.Minute); !minute.Equal(ipHits.minute) {
+        ipHits.minute = minute
+        ipHits.counts = make(map[string]int)
+    }
+    ipHits.counts[ip]++
+    return ipHits.counts[ip]
+}
+
+// detectBot tells whether a hit comes from a bot rather than a person.
+// webdriver is what the page reported for `navigator.webdriver`, source is
+// whether the hit is a beacon of the tracking script or a page the server
+// recorded itself.
+func detectBot(c fiber.Ctx, ua userAgent, webdriver bool, source types.PageviewSource) *botHit {
+    var ip = c.IP()
+
+    if addr, err := netip.ParseAddr(ip); err == nil {
+        addr = addr.Unmap()
+        for _, prefix := range ipDenylist() {
+            if prefix.Contains(addr) {
+                return &botHit{types.BRDenylist, prefix.String()}
+            }
+        }
+    }
+
+    // Headless browsers run the tracking script like any other, but give
+    // themselves away one way or another
+    switch {
+    case webdriver:
+        return &botHit{types.BRHeadless, "navigator.webdriver"}
+    case ua.browser == "Headless Chrome" || ua.browser == "Lighthouse":
+        return &botHit{types.BRHeadless, ua.browser}
+    case strings.Contains(c.Get("Sec-CH-UA"), "HeadlessChrome"):
+        return &botHit{types.BRHeadless, "Sec-CH-UA"}
+    case ua.device != types.DCBot && ua.device != types.DCTerminal && len(c.Get(fiber.HeaderAcceptLanguage)) == 0:
+        // Browsers send it with every request, scripted clients rarely do
+        return &botHit{types.BRHeadless, "no Accept-Language"}
+    }
+
+    switch {
+    case ua.device == types.DCBot:
+        var name = ua.browser
+        if len(name) == 0 {
+            name = "no User-Agent"
+        }
+        return &botHit{types.BRUserAgent, name}
+    case ua.device == types.DCTerminal && source == types.PSBeacon:
+        // Terminal clients read pages but can't run the tracking script, a
+        // beacon from one is made up
+        return &botHit{types.BRUserAgent, ua.browser}
+    }
+
+    if hits := countHit(ip, time.Now()); hits > botRateLimit {
+        return &botHit{types.BRRate, fmt.Sprintf("over %d hits a minute", botRateLimit)}
+    }
+
+    return nil
+}
+
+// includeBots tells whether a report was asked to count bots too, with
+// `include_bots=1`.
+func includeBots(c fiber.Ctx) bool {
+    return c.Query("include_bots") == "1"
+}
+
+// eventsTable is what a report reads raw events from: analytics_events, or
+// the union of it and bot_events when bots are included. The union has the
+// columns of analytics_events and an is_bot column on top.
+func eventsTable(bots bool) string {
+    if !bots {
+        return "analytics_events"
+    }
+    return `(
+        SELECT ` + eventColumns + `, 0 AS is_bot FROM analytics_events
+        UNION ALL
+        SELECT ` + eventColumns + `, 1 AS is_bot FROM bot_events
+    )`
+}
+
+// eventColumns are the columns analytics_events and bot_events share.
+const eventColumns = `event_id, country_code, page_path, timestamp, visitor_id, session_id,
+        referrer, referrer_kind, utm_source, utm_medium, utm_campaign, utm_term, utm_content,
+        browser, browser_version, os, device, source, engaged_seconds, max_scroll, pageview_id`
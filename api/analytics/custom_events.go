@@ -0,0 +1,289 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package analytics
+
+import (
+    "context"
+    "database/sql"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "time"
+    "unicode/utf8"
+
+    "git.jelius.dev/jelius-sama/Portfolio/db"
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+    "github.com/gofiber/fiber/v3"
+    "github.com/jelius-sama/logger"
+)
+
+// maxPropertyLength is how long a string property can be, in characters.
+// Outbound URLs are the longest there are.
+const maxPropertyLength = 512
+
+// customEvents is the schema of the custom events: their names and the
+// properties each can have. Nothing else is stored, an event that doesn't
+// fit it is turned away.
+//
+// The tracking script sends an event for clicks on any element with a
+// data-analytics-event attribute naming it, and data-analytics-props
+// holding its properties as a JSON object.
+var customEvents = map[string]map[string]types.PropertyType{
+    // A click on one of the entries of /links
+    "outbound_click": {"url": types.PTString, "title": types.PTString},
+    // A download of the CV, from the hero section
+    "cv_download": {"cv_path": types.PTString},
+    // The QR code to the site being opened
+    "qr_open": {"placement": types.PTString},
+    // The copy button of a code block
+    "code_copy": {"language": types.PTString, "lines": types.PTNumber},
+}
+
+// checkProperties tells what is wrong with properties for the custom event
+// name, if anything.
+func checkProperties(name string, properties map[string]any) error {
+    var schema, ok = customEvents[name]
+    if !ok {
+        return fmt.Errorf("unknown event %q", name)
+    }
+
+    for key, value := range properties {
+        var want, ok = schema[key]
+        if !ok {
+            return fmt.Errorf("%s has no property %q", name, key)
+        }
+
+        var valid bool
+        switch v := value.(type) {
+        case string:
+            valid = want == types.PTString && utf8.RuneCountInString(v) <= maxPropertyLength
+        case float64:
+            valid = want == types.PTNumber
+        case bool:
+            valid = want == types.PTBool
+        }
+        if !valid {
+            return fmt.Errorf("property %q of %s must be a %s", key, name, want)
+        }
+    }
+
+    return nil
+}
+
+// trackCustomEvent records a custom event the tracking script sent, in the
+// session of the pageview it names, the last one the script got recorded.
+// Events of bots are answered all the same but not recorded.
+func trackCustomEvent(c fiber.Ctx, req types.TrackAnalyticsRequest) error {
+    if len(req.Name) == 0 || len(req.PagePath) == 0 {
+        return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
+            Code:    fiber.StatusBadRequest,
+            Message: "name and page_path are required",
+        })
+    }
+    if err := checkProperties(req.Name, req.Properties); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
+            Code:    fiber.StatusBadRequest,
+            Message: err.Error(),
+        })
+    }
+
+    var ua = parseUserAgent(c.Get(fiber.HeaderUserAgent))
+    if detectBot(c, ua, req.Webdriver, types.PSBeacon) != nil {
+        return c.SendStatus(fiber.StatusCreated)
+    }
+
+    if err := recordCustomEvent(c.RequestCtx(), pageview{
+        visitor:     visitorID(time.Now(), c.IP(), c.Get(fiber.HeaderUserAgent)),
+        countryCode: resolver.GetCountryCode(req.UserTimeZone),
+        pagePath:    req.PagePath,
+    }, req.PageviewID, req.Name, req.Properties); err != nil {
+        logger.Error(c.Path(), err.Error())
+        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+            Code:    fiber.StatusInternalServerError,
+            Message: "Internal Server Error",
+        })
+    }
+
+    return c.SendStatus(fiber.StatusCreated)
+}
+
+// recordCustomEvent stores the custom event name, which happened on the
+// page of pv, with properties that fit its schema. It goes in the session of
+// the pageview with the ID pageviewID, if there is one. Looking it up by the
+// visitor would lose it past midnight, when visitor IDs change.
+func recordCustomEvent(ctx context.Context, pv pageview, pageviewID, name string, properties map[string]any) error {
+    if properties == nil {
+        properties = map[string]any{}
+    }
+    var encoded, err = json.Marshal(properties)
+    if err != nil {
+        return err
+    }
+
+    var session sql.NullString
+    if len(pageviewID) != 0 {
+        if err := db.DB.QueryRowContext(ctx, `
+            SELECT session_id FROM analytics_events WHERE pageview_id = ?
+        `, pageviewID).Scan(&session); err != nil && !errors.Is(err, sql.ErrNoRows) {
+            return err
+        }
+    }
+
+    _, err = db.DB.ExecContext(ctx, `
+        INSERT INTO analytics_custom_events (name, properties, country_code, page_path, visitor_id, session_id, timestamp)
+        VALUES (?, ?, ?, ?, ?, ?, datetime('now', 'utc'))
+    `, name, string(encoded), pv.countryCode, pv.pagePath, pv.visitor, session)
+    return err
+}
+
+// GetCustomEvents counts the custom events by name and by each value of each
+// of their properties, the most common first. `event` narrows it down to
+// one event and `property` to one of its properties. The report filters
+// apply to the page the events happened on. There are no bots in it, their
+// events aren't recorded.
+func GetCustomEvents(c fiber.Ctx) error {
+    // Query parameters
+    var pageStr = c.Query("page", "0")
+    var limitStr = c.Query("limit", "10")
+    var sortOrder = c.Query("sort", "1") // asc or desc
+
+    var page, limit, sort, parseErr = parsePaginationParam(pageStr, limitStr, sortOrder)
+    if parseErr != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(*parseErr)
+    }
+
+    var filter, filterErr = parseReportFilter(c)
+    if filterErr != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(*filterErr)
+    }
+    var where, args = filter.eventsWhere("e")
+
+    if event := c.Query("event"); len(event) != 0 {
+        if _, ok := customEvents[event]; !ok {
+            return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
+                Code:    fiber.StatusBadRequest,
+                Message: fmt.Sprintf("unknown event %q", event),
+            })
+        }
+        where += " AND e.name = ?"
+        args = append(args, event)
+    }
+
+    var propertyWhere, propertyArgs = "", []any(nil)
+    if property := c.Query("property"); len(property) != 0 {
+        if _, ok := customEvents[c.Query("event")][property]; !ok {
+            return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
+                Code:    fiber.StatusBadRequest,
+                Message: "property has to be one of the properties of event",
+            })
+        }
+        propertyWhere, propertyArgs = "WHERE p.key = ?", []any{property}
+    }
+
+    // Every event is a row per property, or a single one without properties.
+    // Values are kept as JSON so strings, numbers and booleans stay apart.
+    var grouped = `
+        SELECT e.name, COALESCE(p.key, '') AS property,
+            CASE WHEN p.key IS NULL THEN NULL WHEN p.type IN ('true', 'false') THEN p.type ELSE json_quote(p.value) END AS value,
+            COUNT(*) AS event_count, COUNT(DISTINCT e.visitor_id) AS unique_visitors
+        FROM (SELECT * FROM analytics_custom_events e WHERE ` + where + `) e
+        LEFT JOIN json_each(e.properties) p
+        ` + propertyWhere + `
+        GROUP BY e.name, property, value
+    `
+    var groupedArgs = append(args, propertyArgs...)
+
+    // Get total count of rows
+    var totalRows int
+    if err := db.DB.QueryRow(`SELECT COUNT(*) FROM (`+grouped+`)`, groupedArgs...).Scan(&totalRows); err != nil {
+        logger.Error(c.Path(), err.Error())
+        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+            Code:    fiber.StatusInternalServerError,
+            Message: "Internal Server Error",
+        })
+    }
+
+    // Calculate offset
+    var offset = page * limit
+
+    // Get the events of every name for percentage calculation
+    var totals = map[string]int{}
+    var totalsRows, totalsErr = db.DB.Query(`SELECT e.name, COUNT(*) FROM analytics_custom_events e WHERE `+where+` GROUP BY e.name`, args...)
+    if totalsErr != nil {
+        logger.Error(c.Path(), totalsErr.Error())
+        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+            Code:    fiber.StatusInternalServerError,
+            Message: "Internal Server Error",
+        })
+    }
+    for totalsRows.Next() {
+        var name string
+        var count int
+        if err := totalsRows.Scan(&name, &count); err != nil {
+            totalsRows.Close()
+            logger.Error(c.Path(), err.Error())
+            return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+                Code:    fiber.StatusInternalServerError,
+                Message: "Internal Server Error",
+            })
+        }
+        totals[name] = count
+    }
+    totalsRows.Close()
+
+    // Query with pagination
+    var sortDir = "DESC"
+    if sort == types.SOAsc {
+        sortDir = "ASC"
+    }
+
+    var rows, queryErr = db.DB.Query(grouped+`
+        ORDER BY event_count `+sortDir+`, e.name, property, value
+        LIMIT ? OFFSET ?
+    `, append(groupedArgs, limit, offset)...)
+    if queryErr != nil {
+        logger.Error(c.Path(), queryErr.Error())
+        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+            Code:    fiber.StatusInternalServerError,
+            Message: "Internal Server Error",
+        })
+    }
+    defer rows.Close()
+
+    var data []types.CustomEventResponse
+    for rows.Next() {
+        var resp types.CustomEventResponse
+        var value sql.NullString
+        if err := rows.Scan(
+            &resp.Event,
+            &resp.Property,
+            &value,
+            &resp.Count,
+            &resp.UniqueVisitors,
+        ); err != nil {
+            logger.Error(c.Path(), err.Error())
+            return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+                Code:    fiber.StatusInternalServerError,
+                Message: "Internal Server Error",
+            })
+        }
+        if value.Valid {
+            resp.Value = json.RawMessage(value.String)
+        }
+        if total := totals[resp.Event]; total > 0 {
+            resp.Percentage = (float64(resp.Count) / float64(total)) * 100
+        }
+        data = append(data, resp)
+    }
+
+    var hasMore bool = (offset + limit) < totalRows
+
+    return c.Status(fiber.StatusOK).JSON(types.PaginatedCustomEventsResponse{
+        Data:      data,
+        Page:      page,
+        Limit:     limit,
+        HasMore:   hasMore,
+        TotalRows: totalRows,
+    })
+}
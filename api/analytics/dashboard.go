@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package analytics
+
+import (
+    "time"
+
+    "git.jelius.dev/jelius-sama/Portfolio/db"
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+    "github.com/gofiber/fiber/v3"
+)
+
+const (
+    // dashboardDays is how far back the dashboard goes when from isn't given
+    dashboardDays = 30
+    dashboardTop  = 10
+)
+
+// Dashboard gathers what the analytics dashboard shows for the range the
+// `from`, `to` and `tz` query parameters of c ask for, the last 30 days
+// without from. It is for the site as a whole, page_path and country are
+// left out. Invalid parameters are a *fiber.Error saying what is wrong with
+// them.
+func (s *AnalyticsService) Dashboard(c fiber.Ctx) (*types.AnalyticsDashboard, error) {
+    var filter, filterErr = parseReportFilter(c)
+    if filterErr != nil {
+        return nil, fiber.NewError(int(filterErr.Code), filterErr.Message)
+    }
+    filter.pagePath, filter.country = "", ""
+
+    var d = types.AnalyticsDashboard{To: filter.to, Location: filter.loc, Bots: includeBots(c)}
+    if d.To.IsZero() {
+        d.To = time.Now()
+    }
+    d.To = d.To.In(filter.loc)
+    if filter.from.IsZero() {
+        var y, m, day = d.To.Add(-time.Nanosecond).Date()
+        filter.from = time.Date(y, m, day-dashboardDays+1, 0, 0, 0, 0, filter.loc).UTC()
+    }
+    d.From = filter.from.In(filter.loc)
+
+    var interval = dashboardInterval(d.To.Sub(d.From))
+    var buckets = timeBuckets(filter, interval)
+    if buckets == nil {
+        return nil, fiber.NewError(fiber.StatusBadRequest, "the range is too long to chart")
+    }
+
+    var err error
+    if d.Series, err = timeseries(filter, buckets, interval, types.TGNone, 1, d.Bots); err != nil {
+        return nil, err
+    }
+
+    var pages, pagesErr = topPages(filter, d.Bots, 0, dashboardTop, types.SODesc)
+    if pagesErr != nil {
+        return nil, pagesErr
+    }
+    d.TopPages = pages.Data
+
+    var countries, countriesErr = topCountries(filter, d.Bots, 0, dashboardTop, types.SODesc)
+    if countriesErr != nil {
+        return nil, countriesErr
+    }
+    d.TopCountries = countries.Data
+
+    var referrers, referrersErr = topReferrers(filter, d.Bots, 0, dashboardTop, types.SODesc)
+    if referrersErr != nil {
+        return nil, referrersErr
+    }
+    d.TopReferrers = referrers.Data
+
+    // Every visit is from one country, and so is every visitor within a day
+    // as long as they don't travel, so the countries add up to the totals
+    var stats, args = dailyStats("country_code", d.Bots, filter)
+    if err := db.DB.QueryRowContext(c.RequestCtx(), `
+        SELECT COALESCE(SUM(visits), 0), COALESCE(SUM(visitors), 0), COALESCE(SUM(sessions), 0) FROM `+stats,
+        args...,
+    ).Scan(&d.Visits, &d.Visitors, &d.Sessions); err != nil {
+        return nil, err
+    }
+
+    return &d, nil
+}
+
+// dashboardInterval picks buckets that chart a range of span well, between
+// a few dozen and a few hundred of them.
+func dashboardInterval(span time.Duration) types.TimeseriesInterval {
+    switch {
+    case span <= 3*24*time.Hour:
+        return types.TIHour
+    case span <= 180*24*time.Hour:
+        return types.TIDay
+    case span <= 3*365*24*time.Hour:
+        return types.TIWeek
+    default:
+        return types.TIMonth
+    }
+}
@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package analytics
+
+import (
+    "context"
+    "fmt"
+    "strconv"
+    "time"
+
+    "git.jelius.dev/jelius-sama/Portfolio/db"
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+    "github.com/gofiber/fiber/v3"
+    "github.com/jelius-sama/logger"
+)
+
+const (
+    // engagementWindow is how long after a pageview the tracking script can
+    // still report how it was read. Days are only rolled up once it is over
+    // for their last pageviews, and no pageview is engaged with for longer.
+    engagementWindow = time.Hour
+    // readDepth is how far down a page, in percent, a pageview has to be
+    // scrolled to count as read
+    readDepth = 75
+)
+
+// trackEngagement records an engagement report of the tracking script. It
+// is sent with sendBeacon, which doesn't look at the response, so a report
+// for a pageview that wasn't recorded is only answered with a 404.
+//
+// The report names its pageview by the ID the pageview was answered with.
+// The visitor ID can't be used, it changes at midnight while a page is read.
+func trackEngagement(c fiber.Ctx, req types.TrackAnalyticsRequest) error {
+    if len(req.PageviewID) == 0 {
+        return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
+            Code:    fiber.StatusBadRequest,
+            Message: "pageview_id is required",
+        })
+    }
+    if req.EngagedSeconds < 0 || req.EngagedSeconds > int(engagementWindow.Seconds()) || req.MaxScroll < 0 || req.MaxScroll > 100 {
+        return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
+            Code:    fiber.StatusBadRequest,
+            Message: fmt.Sprintf("engaged_seconds must be between 0 and %d and max_scroll between 0 and 100", int(engagementWindow.Seconds())),
+        })
+    }
+
+    var found, err = recordEngagement(c.RequestCtx(), req.PageviewID, req.EngagedSeconds, req.MaxScroll)
+    if err != nil {
+        logger.Error(c.Path(), err.Error())
+        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+            Code:    fiber.StatusInternalServerError,
+            Message: "Internal Server Error",
+        })
+    }
+    if !found {
+        return c.Status(fiber.StatusNotFound).JSON(types.ErrorResp{
+            Code:    fiber.StatusNotFound,
+            Message: "No pageview to report on",
+        })
+    }
+
+    return c.SendStatus(fiber.StatusNoContent)
+}
+
+// recordEngagement raises the engagement of the pageview with the ID
+// pageviewID to what was reported, if it was in the last engagementWindow,
+// and tells whether there was one. The script reports its running totals
+// and may report them more than once, so they are only ever raised.
+func recordEngagement(ctx context.Context, pageviewID string, seconds, scroll int) (bool, error) {
+    var window = fmt.Sprintf("-%d seconds", int(engagementWindow.Seconds()))
+    for _, table := range []string{"analytics_events", "bot_events"} {
+        var res, err = db.DB.ExecContext(ctx, `
+            UPDATE `+table+`
+            SET engaged_seconds = max(COALESCE(engaged_seconds, 0), ?), max_scroll = max(COALESCE(max_scroll, 0), ?)
+            WHERE pageview_id = ? AND timestamp > datetime('now', 'utc', ?)
+        `, seconds, scroll, pageviewID, window)
+        if err != nil {
+            return false, err
+        }
+        if n, err := res.RowsAffected(); err != nil {
+            return false, err
+        } else if n != 0 {
+            return true, nil
+        }
+    }
+
+    return false, nil
+}
+
+// engagementColumns are the engagement sums analytics_daily_pages has on
+// top of the counts, for dayCounts of column. Countries don't have them.
+func engagementColumns(column string) string {
+    if column != "page_path" {
+        return ""
+    }
+    return ", engaged_views, engaged_seconds, scroll_total, read_views"
+}
+
+// engagementSums adds up the engagement columns from the raw events e, for
+// dayCounts of column.
+func engagementSums(column string) string {
+    if column != "page_path" {
+        return ""
+    }
+    return `, COUNT(e.engaged_seconds) AS engaged_views, COALESCE(SUM(e.engaged_seconds), 0) AS engaged_seconds,
+            COALESCE(SUM(e.max_scroll), 0) AS scroll_total,
+            COUNT(CASE WHEN e.max_scroll >= ` + strconv.Itoa(readDepth) + ` THEN 1 END) AS read_views`
+}
+
+// pageEngagement averages the sums of the engagement columns.
+func pageEngagement(views, seconds, scroll, reads int) types.PageEngagement {
+    var e = types.PageEngagement{EngagedViews: views}
+    if views > 0 {
+        e.AvgEngagedSeconds = float64(seconds) / float64(views)
+        e.AvgScrollDepth = float64(scroll) / float64(views)
+        e.ReadRate = float64(reads) / float64(views) * 100
+    }
+    return e
+}
@@ -0,0 +1,195 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package analytics
+
+import (
+    "bufio"
+    "encoding/csv"
+    "encoding/json"
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+
+    "git.jelius.dev/jelius-sama/Portfolio/db"
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+    "github.com/gofiber/fiber/v3"
+    "github.com/jelius-sama/logger"
+)
+
+// exportBatch is how many events an export reads at a time. It never holds
+// more than that in memory, however many events there are.
+const exportBatch = 1000
+
+// exportHeader is the first row of a CSV export, in the order of
+// AnalyticsEventExport and named after its JSON fields.
+var exportHeader = []string{
+    "event_id", "timestamp", "page_path", "country_code", "visitor_id", "session_id",
+    "referrer", "referrer_kind", "utm_source", "utm_medium", "utm_campaign", "utm_term", "utm_content",
+    "browser", "browser_version", "os", "device", "source", "engaged_seconds", "max_scroll", "bot", "bot_reason", "bot_detail",
+}
+
+// ExportAnalyticsEvents streams every event the report filters match as CSV
+// or NDJSON, by `format`, in the order they were recorded. With
+// `include_bots=1` the bots come after the people, numbered on their own.
+//
+// It is for admins only, unlike the reports it has the visitor and session
+// IDs in it. Events are read in batches after the last event_id sent, so an
+// export doesn't hold the database for as long as the client takes to
+// download it.
+func ExportAnalyticsEvents(c fiber.Ctx) error {
+    var format, ok = parseEnum(c.Query("format", "csv"), types.EFCSV, types.EFNDJSON)
+    if !ok {
+        return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
+            Code:    fiber.StatusBadRequest,
+            Message: "format must be csv or ndjson",
+        })
+    }
+
+    var filter, filterErr = parseReportFilter(c)
+    if filterErr != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(*filterErr)
+    }
+    var bots = includeBots(c)
+
+    var contentType = "text/csv; charset=utf-8"
+    if format == types.EFNDJSON {
+        contentType = "application/x-ndjson"
+    }
+    var name = "analytics-events-" + time.Now().UTC().Format(time.DateOnly) + "." + format.String()
+
+    c.Set(fiber.HeaderContentType, contentType)
+    c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, name))
+
+    // The handler has returned by the time this runs, nothing of c can be
+    // used in it. The status is sent by then too, so errors are only logged
+    // and cut the export short.
+    return c.SendStreamWriter(func(w *bufio.Writer) {
+        if err := writeExport(w, format, filter, bots); err != nil {
+            logger.Error("Analytics export failed:", err.Error())
+        }
+    })
+}
+
+func writeExport(w *bufio.Writer, format types.ExportFormat, filter reportFilter, bots bool) error {
+    var encode func(types.AnalyticsEventExport) error
+    var flush = w.Flush
+
+    switch format {
+    case types.EFCSV:
+        var cw = csv.NewWriter(w)
+        if err := cw.Write(exportHeader); err != nil {
+            return err
+        }
+        encode = func(e types.AnalyticsEventExport) error { return cw.Write(csvRecord(e)) }
+        flush = func() error {
+            if cw.Flush(); cw.Error() != nil {
+                return cw.Error()
+            }
+            return w.Flush()
+        }
+    default:
+        var enc = json.NewEncoder(w)
+        enc.SetEscapeHTML(false)
+        encode = func(e types.AnalyticsEventExport) error { return enc.Encode(e) }
+    }
+
+    var tables = []string{"analytics_events"}
+    if bots {
+        tables = append(tables, "bot_events")
+    }
+
+    for _, table := range tables {
+        var after int64
+        for {
+            var batch, err = exportEvents(table, filter, after)
+            if err != nil {
+                return err
+            }
+
+            for _, e := range batch {
+                if err := encode(e); err != nil {
+                    return err
+                }
+            }
+            // Flushing fails once the client has gone, which ends the export
+            if err := flush(); err != nil {
+                return err
+            }
+
+            if len(batch) < exportBatch {
+                break
+            }
+            after = batch[len(batch)-1].EventID
+        }
+    }
+
+    return nil
+}
+
+// exportEvents reads the next batch of events of table after the event_id
+// after. The rows are closed before any of it is sent.
+func exportEvents(table string, filter reportFilter, after int64) ([]types.AnalyticsEventExport, error) {
+    var bots = table == "bot_events"
+    var botColumns = "NULL, NULL"
+    if bots {
+        botColumns = "e.reason, e.detail"
+    }
+
+    var where, args = filter.eventsWhere("e")
+    var rows, err = db.DB.Query(`
+        SELECT
+            e.event_id, strftime('%Y-%m-%d %H:%M:%S', e.timestamp), e.page_path, e.country_code, e.visitor_id, e.session_id,
+            e.referrer, e.referrer_kind, e.utm_source, e.utm_medium, e.utm_campaign, e.utm_term, e.utm_content,
+            e.browser, e.browser_version, e.os, e.device, e.source, e.engaged_seconds, e.max_scroll, `+botColumns+`
+        FROM `+table+` e
+        WHERE `+where+` AND e.event_id > ?
+        ORDER BY e.event_id
+        LIMIT ?
+    `, append(args, after, exportBatch)...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var batch = make([]types.AnalyticsEventExport, 0, exportBatch)
+    for rows.Next() {
+        var e = types.AnalyticsEventExport{Bot: bots}
+        if err := rows.Scan(
+            &e.EventID, &e.TimestampUTC, &e.PagePath, &e.CountryCode, &e.VisitorID, &e.SessionID,
+            &e.Referrer, &e.ReferrerKind, &e.UTMSource, &e.UTMMedium, &e.UTMCampaign, &e.UTMTerm, &e.UTMContent,
+            &e.Browser, &e.BrowserVersion, &e.OS, &e.Device, &e.Source, &e.EngagedSeconds, &e.MaxScroll, &e.BotReason, &e.BotDetail,
+        ); err != nil {
+            return nil, err
+        }
+        batch = append(batch, e)
+    }
+    return batch, rows.Err()
+}
+
+func csvRecord(e types.AnalyticsEventExport) []string {
+    var record = []string{
+        strconv.FormatInt(e.EventID, 10), e.TimestampUTC, e.PagePath, e.CountryCode, csvField(e.VisitorID), csvField(e.SessionID),
+        csvField(e.Referrer), csvField(e.ReferrerKind), csvField(e.UTMSource), csvField(e.UTMMedium), csvField(e.UTMCampaign), csvField(e.UTMTerm), csvField(e.UTMContent),
+        csvField(e.Browser), csvField(e.BrowserVersion), csvField(e.OS), csvField(e.Device), e.Source.String(),
+        csvField(e.EngagedSeconds), csvField(e.MaxScroll), strconv.FormatBool(e.Bot), csvField(e.BotReason), csvField(e.BotDetail),
+    }
+
+    // UTM parameters are whatever a link had in it, and spreadsheets run
+    // fields starting like a formula
+    for i, field := range record {
+        if len(field) != 0 && strings.ContainsRune("=+-@\t\r", rune(field[0])) {
+            record[i] = "'" + field
+        }
+    }
+    return record
+}
+
+// csvField is v as text, empty when it wasn't recorded.
+func csvField[T any](v *T) string {
+    if v == nil {
+        return ""
+    }
+    return fmt.Sprint(*v)
+}
@@ -21,11 +21,17 @@ func GetAllAnalyticsEvents(c fiber.Ctx) error {
         return c.Status(fiber.StatusBadRequest).JSON(*parseErr)
     }
 
+    var filter, filterErr = parseReportFilter(c)
+    if filterErr != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(*filterErr)
+    }
+    var events, eventArgs = filter.events(includeBots(c))
+
     // Get total count
-    var countQuery = `SELECT COUNT(*) FROM analytics_events`
+    var countQuery = `SELECT COUNT(*) FROM ` + events
 
     var totalRows int
-    if err := db.DB.QueryRow(countQuery).Scan(&totalRows); err != nil {
+    if err := db.DB.QueryRow(countQuery, eventArgs...).Scan(&totalRows); err != nil {
         logger.Error(c.Path(), err.Error())
         return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
             Code:    fiber.StatusInternalServerError,
@@ -42,18 +48,24 @@ func GetAllAnalyticsEvents(c fiber.Ctx) error {
         sortDir = "DESC"
     }
 
+    var botColumn = "0"
+    if includeBots(c) {
+        botColumn = "is_bot"
+    }
+
     var query = `
         SELECT 
             event_id,
             country_code,
             page_path,
-            strftime('%Y-%m-%d %H:%M:%S', timestamp) as timestamp
-        FROM analytics_events
+            strftime('%Y-%m-%d %H:%M:%S', timestamp) as timestamp,
+            ` + botColumn + ` as is_bot
+        FROM ` + events + `
         ORDER BY timestamp ` + sortDir + `
         LIMIT ? OFFSET ?
     `
 
-    var rows, queryErr = db.DB.Query(query, limit, offset)
+    var rows, queryErr = db.DB.Query(query, append(eventArgs, limit, offset)...)
     if queryErr != nil {
         logger.Error(c.Path(), queryErr.Error())
         return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
@@ -71,6 +83,7 @@ func GetAllAnalyticsEvents(c fiber.Ctx) error {
             &resp.CountryCode,
             &resp.PagePath,
             &resp.TimestampUTC,
+            &resp.Bot,
         ); err != nil {
             logger.Error(c.Path(), err.Error())
             return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
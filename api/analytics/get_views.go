@@ -4,80 +4,46 @@
 package analytics
 
 import (
-    "database/sql"
-    "errors"
     "strconv"
-    "strings"
 
-    "git.jelius.dev/jelius-sama/Portfolio/db"
     "git.jelius.dev/jelius-sama/Portfolio/types"
     "github.com/gofiber/fiber/v3"
     "github.com/jelius-sama/logger"
 )
 
-// GetPageVisitCount returns the total number of visits for a specific page
-func GetPageVisitCount(c fiber.Ctx, buf ...*strings.Builder) error {
-    var pagePath string
-
-    if len(buf) != 0 {
-        pagePath = buf[0].String()
-    } else {
-        pagePath = c.Query("page")
-        if len(pagePath) == 0 {
-            return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-                "error": "page query parameter is required",
-            })
-        }
+// GetPageVisitCount returns the total number of visits for a specific page,
+// as a bare number, or all of its counts when JSON is asked for.
+func (s *AnalyticsService) GetPageVisitCount(c fiber.Ctx) error {
+    var pagePath = c.Query("page")
+    if len(pagePath) == 0 {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": "page query parameter is required",
+        })
     }
 
-    if _, exists := types.Pages[pagePath]; !exists {
-        // coule be a dynamic route if not a direct match
-        for templatePattern, _ := range types.Pages {
-            // Evaluate the raw URL against the map key pattern
-            if fiber.RoutePatternMatch(pagePath, templatePattern) {
-                exists = true
-                break
-            }
-        }
+    // The page to count is page here, page_path is left out
+    var filter, filterErr = parseReportFilter(c)
+    if filterErr != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(*filterErr)
+    }
 
-        if !exists && len(buf) == 0 {
+    var stats, err = pageStats(c.RequestCtx(), pagePath, includeBots(c), filter)
+    if err != nil {
+        if err == ErrInvalidPage {
             return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
                 "error": "invalid page parameter provided",
             })
         }
 
-        if !exists && len(buf) != 0 {
-            return errors.New("invalid page path")
-        }
-    }
-
-    // Query to count visits for the specific page
-    var query = `
-    SELECT COUNT(*) as visit_count
-    FROM analytics_events
-    WHERE page_path = ?
-    `
-
-    var visitCount int
-    if err := db.DB.QueryRow(query, pagePath).Scan(&visitCount); err != nil && err != sql.ErrNoRows {
-        if len(buf) == 0 {
-            logger.Error(c.Path(), err.Error())
-            return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
-                Code:    fiber.StatusInternalServerError,
-                Message: "Internal Server Error",
-            })
-        } else {
-            return err
-        }
+        logger.Error(c.Path(), err.Error())
+        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+            Code:    fiber.StatusInternalServerError,
+            Message: "Internal Server Error",
+        })
     }
 
-    if len(buf) == 0 {
-        return c.SendString(strconv.Itoa(visitCount))
-    } else {
-        buf[0].Reset()
-        buf[0].WriteString(strconv.Itoa(visitCount))
+    if c.Accepts(fiber.MIMETextPlain, fiber.MIMEApplicationJSON) == fiber.MIMEApplicationJSON {
+        return c.JSON(stats)
     }
-
-    return nil
+    return c.SendString(strconv.FormatUint(uint64(stats.VisitCount), 10))
 }
-
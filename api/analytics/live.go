@@ -0,0 +1,208 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package analytics
+
+import (
+    "bufio"
+    "encoding/json"
+    "fmt"
+    "sync"
+    "time"
+
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+    "github.com/gofiber/fiber/v3"
+)
+
+const (
+    // liveWindow is how long a visitor counts as active after a pageview
+    liveWindow = 5 * time.Minute
+    // liveRecent is how many of the latest pageviews a new stream starts with
+    liveRecent = 20
+    // liveBuffer is how many pageviews a stream can fall behind by before it
+    // is cut off
+    liveBuffer = 64
+    // liveMaxStreams caps the open streams, each holds a connection
+    liveMaxStreams = 64
+    // liveTick is how often a stream sends the active count, it keeps idle
+    // connections from timing out and finds clients that are gone
+    liveTick = 15 * time.Second
+)
+
+// liveHub fans the pageviews recordVisit stores out to the open live
+// streams. It only lives in memory, after a restart the active count starts
+// from nothing.
+//
+// Publishing never waits on a stream: one whose buffer is full is closed
+// instead, and the client reconnects and starts over from the recent
+// pageviews.
+type liveHub struct {
+    mu      sync.Mutex
+    seen    map[string]time.Time
+    pruned  time.Time
+    recent  []types.LivePageview
+    streams map[chan types.LivePageview]struct{}
+    closed  bool
+}
+
+var live = &liveHub{
+    seen:    make(map[string]time.Time),
+    streams: make(map[chan types.LivePageview]struct{}),
+}
+
+func (h *liveHub) publish(visitor string, pv types.LivePageview) {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+
+    if h.closed {
+        return
+    }
+
+    h.seen[visitor] = pv.Timestamp
+    if pv.Timestamp.Sub(h.pruned) > time.Minute {
+        h.prune(pv.Timestamp)
+    }
+
+    h.recent = append(h.recent, pv)
+    if len(h.recent) > liveRecent {
+        h.recent = h.recent[len(h.recent)-liveRecent:]
+    }
+
+    for stream := range h.streams {
+        select {
+        case stream <- pv:
+        default:
+            delete(h.streams, stream)
+            close(stream)
+        }
+    }
+}
+
+// prune forgets the visitors that aren't active any more, h.mu is held.
+func (h *liveHub) prune(now time.Time) {
+    for visitor, last := range h.seen {
+        if now.Sub(last) > liveWindow {
+            delete(h.seen, visitor)
+        }
+    }
+    h.pruned = now
+}
+
+func (h *liveHub) active() types.LiveActiveResponse {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+
+    h.prune(time.Now())
+    return types.LiveActiveResponse{Active: len(h.seen), WindowSeconds: int(liveWindow.Seconds())}
+}
+
+// subscribe opens a stream and returns it with the recent pageviews, oldest
+// first. It fails when there are liveMaxStreams already or the hub is
+// closed.
+func (h *liveHub) subscribe() (chan types.LivePageview, []types.LivePageview, bool) {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+
+    if h.closed || len(h.streams) >= liveMaxStreams {
+        return nil, nil, false
+    }
+
+    var stream = make(chan types.LivePageview, liveBuffer)
+    h.streams[stream] = struct{}{}
+    return stream, append([]types.LivePageview(nil), h.recent...), true
+}
+
+func (h *liveHub) unsubscribe(stream chan types.LivePageview) {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+
+    if _, ok := h.streams[stream]; ok {
+        delete(h.streams, stream)
+        close(stream)
+    }
+}
+
+func (h *liveHub) close() {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+
+    h.closed = true
+    for stream := range h.streams {
+        delete(h.streams, stream)
+        close(stream)
+    }
+}
+
+// CloseLiveStreams ends every live stream and turns new ones away. Streams
+// don't end on their own, the server can't shut down gracefully until they
+// do.
+func CloseLiveStreams() {
+    live.close()
+}
+
+// StreamLiveVisitors is a Server-Sent Events stream of the visitors on the
+// site right now. An `active` event with a types.LiveActiveResponse comes
+// first, every 15 seconds and whenever the count changes, a `pageview` event
+// with a types.LivePageview for each pageview as it is recorded. The latest
+// pageviews are sent right away. Bots are left out.
+//
+// It is for the admin only, behind ADMIN_TOKEN like the rest of the admin
+// API. A browser's EventSource can't send the token, so clients read it with
+// fetch or curl instead.
+func StreamLiveVisitors(c fiber.Ctx) error {
+    var stream, recent, ok = live.subscribe()
+    if !ok {
+        return c.Status(fiber.StatusServiceUnavailable).JSON(types.ErrorResp{
+            Code:    fiber.StatusServiceUnavailable,
+            Message: "Too many live streams are open, try again later",
+        })
+    }
+
+    c.Set(fiber.HeaderContentType, "text/event-stream")
+    c.Set(fiber.HeaderCacheControl, "no-cache")
+    // Keeps nginx from holding the events back in its buffer
+    c.Set("X-Accel-Buffering", "no")
+
+    // Like an export, this runs after the handler has returned. A client that
+    // went away is found when flushing to it fails.
+    return c.SendStreamWriter(func(w *bufio.Writer) {
+        defer live.unsubscribe(stream)
+
+        var tick = time.NewTicker(liveTick)
+        defer tick.Stop()
+
+        fmt.Fprintf(w, "retry: %d\n\n", (5 * time.Second).Milliseconds())
+        var active = live.active()
+        writeLiveEvent(w, "active", active)
+        for _, pv := range recent {
+            writeLiveEvent(w, "pageview", pv)
+        }
+
+        for {
+            if err := w.Flush(); err != nil {
+                return
+            }
+
+            select {
+            case pv, open := <-stream:
+                if !open {
+                    return
+                }
+                writeLiveEvent(w, "pageview", pv)
+                // A new visitor changes the count, it is sent right away
+                if now := live.active(); now.Active != active.Active {
+                    active = now
+                    writeLiveEvent(w, "active", active)
+                }
+            case <-tick.C:
+                active = live.active()
+                writeLiveEvent(w, "active", active)
+            }
+        }
+    })
+}
+
+func writeLiveEvent(w *bufio.Writer, event string, data any) {
+    var payload, _ = json.Marshal(data)
+    fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+}
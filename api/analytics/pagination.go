@@ -5,6 +5,8 @@ package analytics
 
 import (
     "strconv"
+    "strings"
+    "time"
 
     "git.jelius.dev/jelius-sama/Portfolio/types"
     "github.com/gofiber/fiber/v3"
@@ -45,3 +47,134 @@ func parsePaginationParam(pageStr, limitStr, sortOrder string) (int, int, types.
     return page, limit, sort, nil
 }
 
+// reportFilter narrows the events a report counts, from the `from`, `to`,
+// `tz`, `page_path` and `country` query parameters every report takes.
+type reportFilter struct {
+    // from and to bound the events, to itself excluded. Zero leaves that
+    // side open.
+    from time.Time
+    to   time.Time
+    // loc is the time zone from and to are read in and buckets follow
+    loc      *time.Location
+    pagePath string
+    country  string
+}
+
+// localLayouts are the forms from and to can take in the time zone of tz,
+// as well as RFC 3339 with an offset of its own. A bare date is the start of
+// that day for from and its end for to.
+var localLayouts = []string{time.DateOnly, "2006-01-02T15:04", "2006-01-02T15:04:05"}
+
+func parseReportFilter(c fiber.Ctx) (reportFilter, *types.ErrorResp) {
+    var f = reportFilter{loc: time.UTC}
+
+    if tz := c.Query("tz"); len(tz) != 0 {
+        var loc, err = time.LoadLocation(tz)
+        // Local would be the server's own zone, which the caller can't know
+        if err != nil || tz == "Local" {
+            return f, &types.ErrorResp{
+                Code:    fiber.StatusBadRequest,
+                Message: "tz must be an IANA time zone",
+            }
+        }
+        f.loc = loc
+    }
+
+    for _, bound := range []struct {
+        name string
+        end  bool
+        dst  *time.Time
+    }{{"from", false, &f.from}, {"to", true, &f.to}} {
+        var value = c.Query(bound.name)
+        if len(value) == 0 {
+            continue
+        }
+
+        if t, err := time.Parse(time.RFC3339, value); err == nil {
+            *bound.dst = t.UTC()
+            continue
+        }
+        for _, layout := range localLayouts {
+            if t, err := time.ParseInLocation(layout, value, f.loc); err == nil {
+                if layout == time.DateOnly && bound.end {
+                    t = t.AddDate(0, 0, 1)
+                }
+                *bound.dst = t.UTC()
+                break
+            }
+        }
+        if bound.dst.IsZero() {
+            return f, &types.ErrorResp{
+                Code:    fiber.StatusBadRequest,
+                Message: bound.name + " must be a date, a local time or an RFC 3339 time",
+            }
+        }
+    }
+
+    if !f.from.IsZero() && !f.to.IsZero() && !f.from.Before(f.to) {
+        return f, &types.ErrorResp{
+            Code:    fiber.StatusBadRequest,
+            Message: "from must be before to",
+        }
+    }
+
+    if f.pagePath = c.Query("page_path"); len(f.pagePath) != 0 && f.pagePath[0] != '/' {
+        return f, &types.ErrorResp{
+            Code:    fiber.StatusBadRequest,
+            Message: "page_path must start with /",
+        }
+    }
+
+    if f.country = strings.ToUpper(c.Query("country")); len(f.country) != 0 && f.country != "UNKNOWN" {
+        if len(f.country) != 2 || f.country[0] < 'A' || f.country[0] > 'Z' || f.country[1] < 'A' || f.country[1] > 'Z' {
+            return f, &types.ErrorResp{
+                Code:    fiber.StatusBadRequest,
+                Message: "country must be a two letter country code",
+            }
+        }
+    }
+
+    return f, nil
+}
+
+// eventsWhere is the condition on the raw events aliased as alias for the
+// filter, with its arguments.
+func (f reportFilter) eventsWhere(alias string) (string, []any) {
+    var where, args = f.dimensions(alias)
+    if !f.from.IsZero() {
+        where += " AND " + alias + ".timestamp >= ?"
+        args = append(args, sqlTime(f.from))
+    }
+    if !f.to.IsZero() {
+        where += " AND " + alias + ".timestamp < ?"
+        args = append(args, sqlTime(f.to))
+    }
+    return where, args
+}
+
+// dimensions is the condition on page_path and country_code of the rows
+// aliased as alias, which is all of the filter rollups can take as it is.
+func (f reportFilter) dimensions(alias string) (string, []any) {
+    var where, args = "1 = 1", []any(nil)
+    if len(f.pagePath) != 0 {
+        where += " AND " + alias + ".page_path = ?"
+        args = append(args, f.pagePath)
+    }
+    if len(f.country) != 0 {
+        where += " AND " + alias + ".country_code = ?"
+        args = append(args, f.country)
+    }
+    return where, args
+}
+
+// events is the filtered raw events as a subquery, the same columns as
+// eventsTable. It is for reports without rollups.
+func (f reportFilter) events(bots bool) (string, []any) {
+    var where, args = f.eventsWhere("e")
+    return `(SELECT * FROM ` + eventsTable(bots) + ` e WHERE ` + where + `)`, args
+}
+
+// sqlTime formats t the way event timestamps are stored.
+func sqlTime(t time.Time) string {
+    return t.UTC().Format(time.DateTime)
+}
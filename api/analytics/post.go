@@ -9,8 +9,8 @@ import (
     "os"
     "strings"
     "sync"
+    "time"
 
-    "git.jelius.dev/jelius-sama/Portfolio/db"
     "git.jelius.dev/jelius-sama/Portfolio/types"
     "github.com/gofiber/fiber/v3"
     "github.com/jelius-sama/logger"
@@ -90,6 +90,16 @@ func (r *TZResolver) GetCountryCode(tz string) string {
     return "UNKNOWN"
 }
 
+// canonicalPath folds `/:lang/blog/:id` into `/blog/:id`, the path the
+// server records every language variant of a post under.
+func canonicalPath(path string) string {
+    if fiber.RoutePatternMatch(path, "/:lang/blog/:id") {
+        var parts = strings.Split(strings.Trim(path, "/"), "/")
+        return "/blog/" + parts[len(parts)-1]
+    }
+    return path
+}
+
 func TrackAnalytics(c fiber.Ctx) error {
     var req types.TrackAnalyticsRequest
 
@@ -101,6 +111,25 @@ func TrackAnalytics(c fiber.Ctx) error {
         })
     }
 
+    req.PagePath = canonicalPath(req.PagePath)
+
+    var kind, ok = parseEnum(req.Type, types.TTPageview, types.TTEvent)
+    if len(req.Type) == 0 {
+        kind, ok = types.TTPageview, true
+    }
+    if !ok {
+        return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
+            Code:    fiber.StatusBadRequest,
+            Message: "type must be pageview, engagement or event",
+        })
+    }
+    switch kind {
+    case types.TTEngagement:
+        return trackEngagement(c, req)
+    case types.TTEvent:
+        return trackCustomEvent(c, req)
+    }
+
     // Validate required fields
     if len(req.UserTimeZone) == 0 || len(req.PagePath) == 0 {
         return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
@@ -109,13 +138,26 @@ func TrackAnalytics(c fiber.Ctx) error {
         })
     }
 
-    // Insert into database
-    var query = `
-        INSERT INTO analytics_events (country_code, page_path, timestamp)
-        VALUES (?, ?, datetime('now', 'utc'))
-    `
+    var pv = pageview{
+        visitor:     visitorID(time.Now(), c.IP(), c.Get(fiber.HeaderUserAgent)),
+        countryCode: resolver.GetCountryCode(req.UserTimeZone),
+        pagePath:    req.PagePath,
+        ua:          parseUserAgent(c.Get(fiber.HeaderUserAgent)),
+    }
+    if req.Referrer != nil {
+        pv.landing = true
+        pv.referrer, pv.referrerKind = normalizeReferrer(*req.Referrer)
+        pv.utmSource = utmValue(req.UTMSource)
+        pv.utmMedium = utmValue(req.UTMMedium)
+        pv.utmCampaign = utmValue(req.UTMCampaign)
+        pv.utmTerm = utmValue(req.UTMTerm)
+        pv.utmContent = utmValue(req.UTMContent)
+    }
+    // Bots are answered like anyone else, only recorded apart
+    pv.bot = detectBot(c, pv.ua, req.Webdriver, types.PSBeacon)
 
-    if _, err := db.DB.Exec(query, resolver.GetCountryCode(req.UserTimeZone), req.PagePath); err != nil {
+    var id, err = recordVisit(c.RequestCtx(), pv)
+    if err != nil {
         logger.Error(c.Path(), err.Error())
         return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
             Code:    fiber.StatusInternalServerError,
@@ -123,6 +165,6 @@ func TrackAnalytics(c fiber.Ctx) error {
         })
     }
 
-    return c.SendStatus(fiber.StatusCreated)
+    return c.Status(fiber.StatusCreated).JSON(types.TrackAnalyticsResponse{PageviewID: id})
 }
 
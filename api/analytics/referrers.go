@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package analytics
+
+import (
+    "net/url"
+    "strings"
+
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+)
+
+// maxAttributionLen caps referrers and UTM values, which come straight from
+// the visitor and can be anything.
+const maxAttributionLen = 100
+
+// referrerGroups names the search engines and social networks whose hosts
+// are grouped together. A pattern matches the host itself and its
+// subdomains, one ending in `.*` matches the name under any top-level
+// domain, so `google.*` is google.com as well as www.google.co.in.
+var referrerGroups = []struct {
+    name     string
+    kind     types.ReferrerKind
+    patterns []string
+}{
+    {"Google", types.RKSearch, []string{"google.*"}},
+    {"Bing", types.RKSearch, []string{"bing.com"}},
+    {"DuckDuckGo", types.RKSearch, []string{"duckduckgo.com"}},
+    {"Yahoo", types.RKSearch, []string{"search.yahoo.com"}},
+    {"Yandex", types.RKSearch, []string{"yandex.*", "ya.ru"}},
+    {"Baidu", types.RKSearch, []string{"baidu.com"}},
+    {"Ecosia", types.RKSearch, []string{"ecosia.org"}},
+    {"Brave Search", types.RKSearch, []string{"search.brave.com"}},
+    {"Startpage", types.RKSearch, []string{"startpage.com"}},
+    {"Kagi", types.RKSearch, []string{"kagi.com"}},
+    {"Qwant", types.RKSearch, []string{"qwant.com"}},
+
+    {"X (Twitter)", types.RKSocial, []string{"twitter.com", "x.com", "t.co"}},
+    {"Facebook", types.RKSocial, []string{"facebook.com", "fb.com", "fb.me"}},
+    {"Instagram", types.RKSocial, []string{"instagram.com"}},
+    {"LinkedIn", types.RKSocial, []string{"linkedin.com", "lnkd.in"}},
+    {"Reddit", types.RKSocial, []string{"reddit.com", "redd.it"}},
+    {"Hacker News", types.RKSocial, []string{"news.ycombinator.com"}},
+    {"Lobsters", types.RKSocial, []string{"lobste.rs"}},
+    {"Bluesky", types.RKSocial, []string{"bsky.app"}},
+    {"Threads", types.RKSocial, []string{"threads.net"}},
+    {"YouTube", types.RKSocial, []string{"youtube.com", "youtu.be"}},
+    {"Discord", types.RKSocial, []string{"discord.com", "discord.gg"}},
+    {"Telegram", types.RKSocial, []string{"t.me", "telegram.org"}},
+    {"Mastodon", types.RKSocial, []string{"mastodon.social", "hachyderm.io", "fosstodon.org", "infosec.exchange"}},
+}
+
+// normalizeReferrer reduces the referrer of a landing page to where it is
+// from: the search engine or social network it belongs to, or otherwise its
+// host. Empty referrers, ones from this site and anything that isn't a web
+// page count as direct visits.
+func normalizeReferrer(referrer string) (string, types.ReferrerKind) {
+    var u, err = url.Parse(strings.TrimSpace(referrer))
+    if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+        return "", types.RKDirect
+    }
+
+    var host = strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+    if len(host) == 0 || host == siteHost() {
+        return "", types.RKDirect
+    }
+
+    for _, group := range referrerGroups {
+        for _, pattern := range group.patterns {
+            if matchHost(host, pattern) {
+                return group.name, group.kind
+            }
+        }
+    }
+    return truncate(host), types.RKWebsite
+}
+
+func matchHost(host, pattern string) bool {
+    if name, ok := strings.CutSuffix(pattern, ".*"); ok {
+        var labels = strings.Split(host, ".")
+        // The name has to be followed by at least one label, the TLD
+        for _, label := range labels[:len(labels)-1] {
+            if label == name {
+                return true
+            }
+        }
+        return false
+    }
+    return host == pattern || strings.HasSuffix(host, "."+pattern)
+}
+
+func siteHost() string {
+    var u, err = url.Parse(types.EVHostname.Get().Value)
+    if err != nil {
+        return ""
+    }
+    return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// utmValue cleans up a UTM parameter for grouping, nil when it is missing.
+func utmValue(v string) *string {
+    v = strings.ToLower(strings.TrimSpace(v))
+    if len(v) == 0 {
+        return nil
+    }
+    return new(truncate(v))
+}
+
+func truncate(s string) string {
+    var runes = []rune(s)
+    if len(runes) > maxAttributionLen {
+        return string(runes[:maxAttributionLen])
+    }
+    return s
+}
@@ -0,0 +1,258 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package analytics
+
+import (
+    "context"
+    "fmt"
+    "sync"
+    "time"
+
+    "git.jelius.dev/jelius-sama/Portfolio/db"
+    "github.com/jelius-sama/logger"
+)
+
+// rolledUntil is the first day the rollups don't cover yet, as SQL. Reports
+// read the rollups for the days before it and the raw events from it on, so
+// nothing is counted twice or left out wherever the boundary is.
+const rolledUntil = `(SELECT rolled_until FROM analytics_rollup_state WHERE id = 1)`
+
+// RollupConfig controls the rollup job.
+type RollupConfig struct {
+    // Interval is the gap between two runs started by Start
+    Interval time.Duration
+    // Retention is how many days raw events are kept for after they are
+    // rolled up, 0 keeps them forever
+    Retention int
+}
+
+// Rollup sums the raw events of every day that is over into the hourly and
+// daily rollups, and deletes the raw events that are past the retention.
+// Reports that have no rollup to read from, such as top referrers, only
+// cover the raw events that are left.
+type Rollup struct {
+    cfg     RollupConfig
+    running sync.Mutex
+}
+
+func NewRollup(cfg RollupConfig) *Rollup {
+    return &Rollup{cfg: cfg}
+}
+
+// Start runs the job once right away and then every Interval in the
+// background, same lifecycle as the link checker.
+func (r *Rollup) Start() {
+    go func() {
+        var t = time.NewTicker(r.cfg.Interval)
+        defer t.Stop()
+
+        for {
+            if err := r.Run(context.Background()); err != nil {
+                logger.Error("Analytics rollup failed:", err.Error())
+            }
+            <-t.C
+        }
+    }()
+}
+
+// Run rolls up every day that is over and isn't yet, then applies the
+// retention.
+func (r *Rollup) Run(ctx context.Context) error {
+    if !r.running.TryLock() {
+        return nil
+    }
+    defer r.running.Unlock()
+
+    if err := rollUp(ctx); err != nil {
+        return err
+    }
+
+    if r.cfg.Retention <= 0 {
+        return nil
+    }
+
+    // Only events that are rolled up go. Custom events aren't rolled up,
+    // but they have visitor IDs like the rest and go at the same time.
+    for _, table := range []string{"analytics_events", "bot_events", "analytics_custom_events"} {
+        var res, err = db.DB.ExecContext(ctx, `
+            DELETE FROM `+table+`
+            WHERE timestamp < min(date(`+rolledUntil+`), date('now', 'utc', ?))
+        `, fmt.Sprintf("-%d days", r.cfg.Retention))
+        if err != nil {
+            return err
+        }
+        if n, err := res.RowsAffected(); err == nil && n != 0 {
+            logger.Info("Deleted", n, "raw events from", table, "past the retention")
+        }
+    }
+
+    return nil
+}
+
+// rollUp sums the days from rolledUntil up to the last one that is over into
+// the rollups and moves rolledUntil past them, all at once.
+func rollUp(ctx context.Context) error {
+    var tx, err = db.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    // A day is only over once beacons for its last pages can't be merged
+    // into them anymore, nor their engagement reported. rolled_until goes
+    // through date() as the driver would make a time.Time of the column
+    // itself.
+    var from, until string
+    if err := tx.QueryRowContext(ctx, `
+        SELECT date(rolled_until), date('now', 'utc', ?) FROM analytics_rollup_state WHERE id = 1
+    `, fmt.Sprintf("-%d seconds", int(max(beaconWindow, engagementWindow).Seconds()))).Scan(&from, &until); err != nil {
+        return err
+    }
+    if from >= until {
+        return nil
+    }
+
+    for _, bots := range []bool{false, true} {
+        var events = eventsTable(bots)
+
+        if _, err := tx.ExecContext(ctx, `
+            INSERT INTO analytics_hourly (hour, page_path, country_code, bots, visits)
+            SELECT strftime('%Y-%m-%d %H:00:00', timestamp) AS hour, page_path, country_code, ?, COUNT(*)
+            FROM `+events+`
+            WHERE timestamp >= ? AND timestamp < ?
+            GROUP BY hour, page_path, country_code
+        `, bots, from, until); err != nil {
+            return err
+        }
+
+        for _, column := range []string{"page_path", "country_code"} {
+            if _, err := tx.ExecContext(ctx, `
+                INSERT INTO `+dailyTable(column)+` (`+column+`, day, visits, visitors, sessions`+engagementColumns(column)+`, bots)
+                SELECT d.*, ? FROM (`+dayCounts(column, events, "e.timestamp >= ? AND e.timestamp < ?")+`) d
+            `, bots, from, until); err != nil {
+                return err
+            }
+        }
+    }
+
+    if _, err := tx.ExecContext(ctx, `UPDATE analytics_rollup_state SET rolled_until = ? WHERE id = 1`, until); err != nil {
+        return err
+    }
+
+    return tx.Commit()
+}
+
+func dailyTable(column string) string {
+    if column == "country_code" {
+        return "analytics_daily_countries"
+    }
+    return "analytics_daily_pages"
+}
+
+// dayCounts counts the visits, unique visitors and sessions of the events
+// matching where, per day and column. Visitor IDs change every day so adding
+// up days gives the exact count, and so do sessions: they are looked up by
+// visitor ID, a visit after midnight starts a new one. Pages have the sums of
+// engagementColumns on top.
+func dayCounts(column, events, where string) string {
+    return `
+        SELECT e.` + column + `, date(e.timestamp) AS day, COUNT(*) AS visits, COUNT(DISTINCT e.visitor_id) AS visitors,
+            COUNT(DISTINCT e.session_id) AS sessions` + engagementSums(column) + `
+        FROM ` + events + ` e
+        WHERE ` + where + `
+        GROUP BY e.` + column + `, day
+    `
+}
+
+// dailyStats is what the visit, visitor and session counts per page_path or
+// country_code are read from: the daily rollups and the raw events after
+// them, with the columns of dayCounts. Days f only covers part of, in UTC,
+// are counted from the raw events too, so they are only exact while the
+// retention keeps them. So is all of it when f filters by the dimension the
+// rollups of column don't have.
+func dailyStats(column string, bots bool, f reportFilter) (string, []any) {
+    var rawWhere, rawArgs = f.eventsWhere("e")
+    var events = eventsTable(bots)
+
+    var other = "country_code"
+    if column == "country_code" {
+        other = "page_path"
+    }
+    if (other == "page_path" && len(f.pagePath) != 0) || (other == "country_code" && len(f.country) != 0) {
+        return `(` + dayCounts(column, events, rawWhere) + `)`, rawArgs
+    }
+
+    var rollupWhere, rollupArgs = f.dimensions("r")
+    var edges = "e.timestamp >= " + rolledUntil
+    if !f.from.IsZero() {
+        // The first day wholly in the range
+        var day = f.from.Truncate(24 * time.Hour)
+        if !day.Equal(f.from) {
+            day = day.Add(24 * time.Hour)
+        }
+        rollupWhere += " AND r.day >= ?"
+        rollupArgs = append(rollupArgs, day.Format(time.DateOnly))
+        edges += " OR e.timestamp < ?"
+        rawArgs = append(rawArgs, day.Format(time.DateOnly))
+    }
+    if !f.to.IsZero() {
+        // The day after the last one wholly in the range
+        var day = f.to.Truncate(24 * time.Hour)
+        rollupWhere += " AND r.day < ?"
+        rollupArgs = append(rollupArgs, day.Format(time.DateOnly))
+        edges += " OR e.timestamp >= ?"
+        rawArgs = append(rawArgs, day.Format(time.DateOnly))
+    }
+
+    return `(
+        SELECT ` + column + `, day, visits, visitors, sessions` + engagementColumns(column) + ` FROM ` + dailyTable(column) + ` r
+        WHERE bots = ` + botsFlag(bots) + ` AND ` + rollupWhere + `
+        UNION ALL
+        ` + dayCounts(column, events, rawWhere+" AND ("+edges+")") + `
+    )`, append(rollupArgs, rawArgs...)
+}
+
+// hourlyVisits is what visit counts per hour, page_path and country_code
+// are read from: the hourly rollups and the raw events after them. The
+// range of f is widened to whole hours in UTC, which is what the rollups
+// can tell apart.
+func hourlyVisits(bots bool, f reportFilter) (string, []any) {
+    var where, args = f.dimensions("r")
+    if !f.from.IsZero() {
+        where += " AND r.hour >= ?"
+        args = append(args, sqlTime(f.from.Truncate(time.Hour)))
+    }
+    if !f.to.IsZero() {
+        where += " AND r.hour < ?"
+        args = append(args, sqlTime(f.to.Add(time.Hour-1).Truncate(time.Hour)))
+    }
+
+    return `(
+        SELECT hour, page_path, country_code, visits FROM (
+            SELECT hour, page_path, country_code, visits FROM analytics_hourly WHERE bots = ` + botsFlag(bots) + `
+            UNION ALL
+            SELECT strftime('%Y-%m-%d %H:00:00', timestamp) AS hour, page_path, country_code, COUNT(*) AS visits
+            FROM ` + eventsTable(bots) + `
+            WHERE timestamp >= ` + rolledUntil + `
+            GROUP BY hour, page_path, country_code
+        ) r
+        WHERE ` + where + `
+    )`, args
+}
+
+// HourlyVisits is an SQL subquery of the visits people made per hour, with
+// the columns hour, page_path, country_code and visits. It is for queries
+// elsewhere that need view counts, the raw events don't go back all the way
+// once the retention deletes them.
+func HourlyVisits() string {
+    var query, _ = hourlyVisits(false, reportFilter{})
+    return query
+}
+
+func botsFlag(bots bool) string {
+    if bots {
+        return "1"
+    }
+    return "0"
+}
@@ -0,0 +1,195 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package analytics
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "strings"
+    "time"
+
+    "git.jelius.dev/jelius-sama/Portfolio/db"
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+    "github.com/gofiber/fiber/v3"
+    "github.com/jelius-sama/logger"
+)
+
+// beaconWindow is how long after a page the server recorded its beacon can
+// still arrive and be merged into it. The tracking script waits 5 seconds,
+// the rest is for slow connections.
+const beaconWindow = 2 * time.Minute
+
+// ServerPageviews records a pageview for every successful full-page load of
+// the pages it is put in front of, so visitors without JavaScript are
+// counted too. HTMX requests only swap the page's content and are left to
+// the tracking script, as is everything but a 200.
+//
+// Only pages whose handler set the "pageview_path" local are counted, under
+// that path. A 200 alone doesn't tell a post from its not found page or a
+// draft preview, and the handler knows the canonical path of what it served.
+//
+// It has to come before any cache, cached pages are views all the same. The
+// cache has to keep the local for its hits, see cache.Store.KeepLocals.
+//
+// Pageviews are stored by a worker it starts once the response is on its
+// way, so page loads don't wait on each other's writes. The beacon of a page
+// comes seconds later and finds it stored by then.
+func ServerPageviews() fiber.Handler {
+    go recordServerPageviews()
+
+    return func(c fiber.Ctx) error {
+        if err := c.Next(); err != nil {
+            return err
+        }
+
+        if c.Method() != fiber.MethodGet || c.Response().StatusCode() != fiber.StatusOK || len(c.Get("HX-Request")) != 0 {
+            return nil
+        }
+        var pagePath, ok = c.Locals("pageview_path").(string)
+        if !ok {
+            return nil
+        }
+
+        // Fiber reuses the request's buffers once the handler returns, what
+        // the worker gets is copied out of them
+        var userAgent = strings.Clone(c.Get(fiber.HeaderUserAgent))
+        var country = strings.Clone(headerCountry(c))
+        if len(country) == 0 {
+            // The tracking script's beacon fills it in from the time zone
+            country = "UNKNOWN"
+        }
+
+        var pv = pageview{
+            visitor:     visitorID(time.Now(), c.IP(), userAgent),
+            countryCode: country,
+            pagePath:    pagePath,
+            ua:          parseUserAgent(userAgent),
+            source:      types.PSServer,
+            // Every full-page load is a landing as far as the tracking
+            // script is concerned, so it is here as well
+            landing:     true,
+            utmSource:   utmValue(strings.Clone(c.Query("utm_source"))),
+            utmMedium:   utmValue(strings.Clone(c.Query("utm_medium"))),
+            utmCampaign: utmValue(strings.Clone(c.Query("utm_campaign"))),
+            utmTerm:     utmValue(strings.Clone(c.Query("utm_term"))),
+            utmContent:  utmValue(strings.Clone(c.Query("utm_content"))),
+        }
+        pv.referrer, pv.referrerKind = normalizeReferrer(strings.Clone(c.Get(fiber.HeaderReferer)))
+        pv.bot = detectBot(c, pv.ua, false, types.PSServer)
+
+        // The page is already rendered, a pageview that can't be stored is
+        // not the visitor's problem
+        select {
+        case serverPageviews <- pv:
+        default:
+            logger.Error(c.Path(), "pageview queue is full, pageview dropped")
+        }
+        return nil
+    }
+}
+
+// serverPageviews holds the pageviews ServerPageviews took until
+// recordServerPageviews stores them.
+var serverPageviews = make(chan pageview, 1024)
+
+func recordServerPageviews() {
+    for pv := range serverPageviews {
+        if _, err := recordVisit(context.Background(), pv); err != nil {
+            logger.Error("Recording a server pageview of", pv.pagePath, "failed:", err.Error())
+        }
+    }
+}
+
+// headerCountry is the country COUNTRY_HEADER names, such as Cloudflare's
+// CF-IPCountry, when the request came through a trusted proxy. It is empty
+// when the header is unset, untrusted or doesn't name a country.
+func headerCountry(c fiber.Ctx) string {
+    var header = types.EVCountryHeader.Get().Value
+    if len(header) == 0 || !c.IsProxyTrusted() {
+        return ""
+    }
+
+    var code = strings.ToUpper(strings.TrimSpace(c.Get(header)))
+    if len(code) != 2 || code[0] < 'A' || code[0] > 'Z' || code[1] < 'A' || code[1] > 'Z' {
+        return ""
+    }
+    // Cloudflare's codes for unknown countries and Tor
+    if code == "XX" || code == "T1" {
+        return ""
+    }
+    return code
+}
+
+// mergeBeacon merges the beacon pv into the pageview the server recorded for
+// the same load, if there is one, and tells whether it did along with the
+// pageview's ID. The server's record stays as it is, except that the beacon
+// can tell its country and show it was made by a bot after all. Has to be
+// called with sessionMu held.
+func mergeBeacon(ctx context.Context, pv pageview) (string, bool, error) {
+    var window = fmt.Sprintf("-%d seconds", int(beaconWindow.Seconds()))
+
+    for _, table := range []string{"analytics_events", "bot_events"} {
+        var eventID int64
+        var id sql.NullString
+        if err := db.DB.QueryRowContext(ctx, `
+            SELECT event_id, pageview_id FROM `+table+`
+            WHERE visitor_id = ? AND page_path = ? AND source = ? AND timestamp > datetime('now', 'utc', ?)
+            ORDER BY event_id DESC
+            LIMIT 1
+        `, pv.visitor, pv.pagePath, types.PSServer, window).Scan(&eventID, &id); err != nil {
+            if errors.Is(err, sql.ErrNoRows) {
+                continue
+            }
+            return "", false, err
+        }
+
+        if table == "analytics_events" && pv.bot != nil {
+            return id.String, true, moveToBots(ctx, eventID, *pv.bot, pv.countryCode)
+        }
+
+        var _, err = db.DB.ExecContext(ctx, `
+            UPDATE `+table+`
+            SET source = ?, country_code = CASE WHEN country_code = 'UNKNOWN' THEN ? ELSE country_code END
+            WHERE event_id = ?
+        `, types.PSConfirmed, pv.countryCode, eventID)
+        return id.String, true, err
+    }
+
+    return "", false, nil
+}
+
+// moveToBots moves a pageview of analytics_events over to bot_events, for
+// the beacon of a page the server took for a person's.
+func moveToBots(ctx context.Context, eventID int64, bot botHit, countryCode string) error {
+    var tx, err = db.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    // Every column but event_id, which bot_events numbers on its own
+    var columns = strings.Replace(eventColumns, "event_id, ", "", 1)
+    if _, err := tx.ExecContext(ctx, `
+        INSERT INTO bot_events (reason, detail, `+columns+`)
+        SELECT ?, ?, `+columns+` FROM analytics_events WHERE event_id = ?
+    `, bot.reason, bot.detail, eventID); err != nil {
+        return err
+    }
+
+    if _, err := tx.ExecContext(ctx, `
+        UPDATE bot_events
+        SET source = ?, country_code = CASE WHEN country_code = 'UNKNOWN' THEN ? ELSE country_code END
+        WHERE event_id = last_insert_rowid()
+    `, types.PSConfirmed, countryCode); err != nil {
+        return err
+    }
+
+    if _, err := tx.ExecContext(ctx, `DELETE FROM analytics_events WHERE event_id = ?`, eventID); err != nil {
+        return err
+    }
+
+    return tx.Commit()
+}
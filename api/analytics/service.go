@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package analytics
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+
+    "git.jelius.dev/jelius-sama/Portfolio/db"
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+    "github.com/gofiber/fiber/v3"
+)
+
+var ErrInvalidPage = errors.New("invalid page path")
+
+// AnalyticsService answers analytics queries for in-process callers such as
+// the renderers and the blog service. The HTTP handlers hanging off it are
+// thin wrappers that parse the request and encode the result.
+type AnalyticsService struct{}
+
+func NewService() *AnalyticsService {
+    return &AnalyticsService{}
+}
+
+// PageVisitCount returns the total number of visits for pagePath, which has
+// to be one of `types.Pages` or match one of its patterns.
+func (s *AnalyticsService) PageVisitCount(ctx context.Context, pagePath string) (uint, error) {
+    var stats, err = s.PageStats(ctx, pagePath, false)
+    if err != nil {
+        return 0, err
+    }
+    return stats.VisitCount, nil
+}
+
+// PageStats counts the visits, unique visitors and sessions of pagePath and
+// averages their engagement. pagePath has to be one of `types.Pages` or
+// match one of its patterns. Bots are only counted when bots is set.
+func (s *AnalyticsService) PageStats(ctx context.Context, pagePath string, bots bool) (*types.PageStatsResponse, error) {
+    return pageStats(ctx, pagePath, bots, reportFilter{})
+}
+
+// pageStats is PageStats narrowed down to the events filter lets through.
+// The page_path of filter is replaced by pagePath.
+func pageStats(ctx context.Context, pagePath string, bots bool, filter reportFilter) (*types.PageStatsResponse, error) {
+    if _, exists := types.Pages[pagePath]; !exists {
+        // coule be a dynamic route if not a direct match
+        for templatePattern := range types.Pages {
+            // Evaluate the raw URL against the map key pattern
+            if fiber.RoutePatternMatch(pagePath, templatePattern) {
+                exists = true
+                break
+            }
+        }
+
+        if !exists {
+            return nil, ErrInvalidPage
+        }
+    }
+
+    filter.pagePath = pagePath
+    var stats, args = dailyStats("page_path", bots, filter)
+
+    // Query to count visits for the specific page
+    var query = `
+    SELECT COALESCE(SUM(visits), 0) as visit_count, COALESCE(SUM(visitors), 0), COALESCE(SUM(sessions), 0),
+        COALESCE(SUM(engaged_views), 0), COALESCE(SUM(engaged_seconds), 0), COALESCE(SUM(scroll_total), 0), COALESCE(SUM(read_views), 0)
+    FROM ` + stats + `
+    `
+
+    var resp = types.PageStatsResponse{PagePath: pagePath}
+    var views, seconds, scroll, reads int
+    if err := db.DB.QueryRowContext(ctx, query, args...).Scan(
+        &resp.VisitCount, &resp.UniqueVisitors, &resp.Sessions, &views, &seconds, &scroll, &reads,
+    ); err != nil && err != sql.ErrNoRows {
+        return nil, err
+    }
+    resp.PageEngagement = pageEngagement(views, seconds, scroll, reads)
+
+    return &resp, nil
+}
@@ -0,0 +1,369 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package analytics
+
+import (
+    "cmp"
+    "slices"
+    "sort"
+    "strconv"
+    "time"
+
+    "git.jelius.dev/jelius-sama/Portfolio/db"
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+    "github.com/gofiber/fiber/v3"
+    "github.com/jelius-sama/logger"
+)
+
+const (
+    // maxBuckets bounds how long a series can get, a year of hours is
+    // more than any chart can show
+    maxBuckets = 1000
+    maxTop     = 20
+)
+
+// defaultBuckets is how many buckets a series has when from isn't given.
+var defaultBuckets = map[types.TimeseriesInterval]int{
+    types.TIHour:  48,
+    types.TIDay:   30,
+    types.TIWeek:  12,
+    types.TIMonth: 12,
+}
+
+// GetTimeseries returns pageviews and unique visitors per hour, day, week or
+// month, ready to chart. Grouped by page, country or referrer, it has a
+// series for each of the `top` values with the most pageviews and one
+// adding up the rest.
+//
+// Buckets follow the time zone of tz. Unique visitors are counted per UTC
+// day, as visitor IDs change with it, and a day goes to the bucket its noon
+// is in. Per hour they are only counted from the raw events, so hours past
+// the retention show none. Pageviews are kept per UTC hour, in zones like
+// Asia/Kolkata that are off by half an hour they go to the bucket the UTC
+// hour starts in.
+func GetTimeseries(c fiber.Ctx) error {
+    var interval, ok = parseEnum(c.Query("interval", "day"), types.TIHour, types.TIMonth)
+    if !ok {
+        return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
+            Code:    fiber.StatusBadRequest,
+            Message: "interval must be one of hour, day, week or month",
+        })
+    }
+
+    var group types.TimeseriesGroup
+    if groupBy := c.Query("group_by"); len(groupBy) != 0 {
+        if group, ok = parseEnum(groupBy, types.TGPage, types.TGReferrer); !ok {
+            return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
+                Code:    fiber.StatusBadRequest,
+                Message: "group_by must be one of page, country or referrer",
+            })
+        }
+    }
+
+    var top, topErr = strconv.Atoi(c.Query("top", "5"))
+    if topErr != nil || top < 1 || top > maxTop {
+        return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
+            Code:    fiber.StatusBadRequest,
+            Message: "top must be between 1 and " + strconv.Itoa(maxTop),
+        })
+    }
+
+    var filter, filterErr = parseReportFilter(c)
+    if filterErr != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(*filterErr)
+    }
+
+    var buckets = timeBuckets(filter, interval)
+    if buckets == nil {
+        return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
+            Code:    fiber.StatusBadRequest,
+            Message: "too many buckets, narrow the range or widen the interval",
+        })
+    }
+
+    var resp, err = timeseries(filter, buckets, interval, group, top, includeBots(c))
+    if err != nil {
+        logger.Error(c.Path(), err.Error())
+        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+            Code:    fiber.StatusInternalServerError,
+            Message: "Internal Server Error",
+        })
+    }
+
+    return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// timeseries counts the series of buckets, as laid out by timeBuckets.
+func timeseries(filter reportFilter, buckets []time.Time, interval types.TimeseriesInterval, group types.TimeseriesGroup, top int, bots bool) (*types.TimeseriesResponse, error) {
+    // Whole buckets are counted, even where from and to cut into them
+    filter.from, filter.to = buckets[0].UTC(), nextBucket(buckets[len(buckets)-1], interval).UTC()
+
+    var pageviews, uniques, err = timeseriesCounts(filter, interval, group, bots)
+    if err != nil {
+        return nil, err
+    }
+
+    var resp = &types.TimeseriesResponse{
+        Interval: interval,
+        GroupBy:  group,
+        Buckets:  buckets,
+        Series:   []types.TimeseriesSeries{},
+    }
+
+    // Keys by pageviews, the ones past top go to the other series
+    var totals = make(map[string]int)
+    for _, row := range pageviews {
+        totals[row.key] += row.count
+    }
+    var keys = make([]string, 0, len(totals))
+    for key := range totals {
+        keys = append(keys, key)
+    }
+    slices.SortFunc(keys, func(a, b string) int {
+        return cmp.Or(cmp.Compare(totals[b], totals[a]), cmp.Compare(a, b))
+    })
+    if group == types.TGNone {
+        // Always a series, even without any traffic
+        keys = []string{""}
+    }
+
+    var index = make(map[string]int)
+    for i, key := range keys {
+        if i == top {
+            resp.Series = append(resp.Series, types.TimeseriesSeries{Other: true})
+            break
+        }
+        index[key] = i
+        resp.Series = append(resp.Series, types.TimeseriesSeries{Key: key})
+    }
+    for i := range resp.Series {
+        resp.Series[i].Pageviews = make([]int, len(buckets))
+        resp.Series[i].Uniques = make([]int, len(buckets))
+    }
+
+    var seriesOf = func(key string) *types.TimeseriesSeries {
+        if i, ok := index[key]; ok {
+            return &resp.Series[i]
+        }
+        return &resp.Series[len(resp.Series)-1]
+    }
+    for _, row := range pageviews {
+        seriesOf(row.key).Pageviews[bucketOf(buckets, row.at)] += row.count
+    }
+    // Unique visitors of the other series are added up across its keys, a
+    // visitor of two of them counts twice like in the top reports
+    for _, row := range uniques {
+        seriesOf(row.key).Uniques[bucketOf(buckets, row.at)] += row.count
+    }
+
+    return resp, nil
+}
+
+// parseEnum finds the value between first and last whose String is s.
+func parseEnum[T interface {
+    ~uint8
+    String() string
+}](s string, first, last T) (T, bool) {
+    for v := first; v <= last; v++ {
+        if v.String() == s {
+            return v, true
+        }
+    }
+    return 0, false
+}
+
+// timeBuckets lays out the start of every bucket from the one from is in to
+// the one before to, in the time zone of filter. Without from it goes back
+// the default number of buckets, without to it goes up to now. It returns
+// nil when that is more than maxBuckets.
+func timeBuckets(filter reportFilter, interval types.TimeseriesInterval) []time.Time {
+    var to = filter.to
+    if to.IsZero() {
+        to = time.Now()
+    }
+    to = to.In(filter.loc)
+
+    var from time.Time
+    if filter.from.IsZero() {
+        from = bucketStart(to.Add(-time.Nanosecond), interval)
+        for range defaultBuckets[interval] - 1 {
+            from = prevBucket(from, interval)
+        }
+    } else {
+        from = bucketStart(filter.from.In(filter.loc), interval)
+    }
+
+    var buckets []time.Time
+    for t := from; t.Before(to); t = nextBucket(t, interval) {
+        if len(buckets) == maxBuckets {
+            return nil
+        }
+        buckets = append(buckets, t)
+    }
+    if len(buckets) == 0 {
+        buckets = append(buckets, from)
+    }
+    return buckets
+}
+
+func bucketStart(t time.Time, interval types.TimeseriesInterval) time.Time {
+    var y, m, d = t.Date()
+    switch interval {
+    case types.TIHour:
+        return time.Date(y, m, d, t.Hour(), 0, 0, 0, t.Location())
+    case types.TIWeek:
+        // Weeks start on Monday
+        return time.Date(y, m, d-(int(t.Weekday())+6)%7, 0, 0, 0, 0, t.Location())
+    case types.TIMonth:
+        return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+    default:
+        return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+    }
+}
+
+// nextBucket and prevBucket step by calendar days and months in the zone
+// of t, so days stay days across DST changes.
+func nextBucket(t time.Time, interval types.TimeseriesInterval) time.Time {
+    switch interval {
+    case types.TIHour:
+        return t.Add(time.Hour)
+    case types.TIWeek:
+        return t.AddDate(0, 0, 7)
+    case types.TIMonth:
+        return t.AddDate(0, 1, 0)
+    default:
+        return t.AddDate(0, 0, 1)
+    }
+}
+
+func prevBucket(t time.Time, interval types.TimeseriesInterval) time.Time {
+    switch interval {
+    case types.TIHour:
+        return t.Add(-time.Hour)
+    case types.TIWeek:
+        return t.AddDate(0, 0, -7)
+    case types.TIMonth:
+        return t.AddDate(0, -1, 0)
+    default:
+        return t.AddDate(0, 0, -1)
+    }
+}
+
+// bucketOf is the index of the bucket t falls in, the first or last one for
+// times before or after them all.
+func bucketOf(buckets []time.Time, t time.Time) int {
+    var i = sort.Search(len(buckets), func(i int) bool { return buckets[i].After(t) })
+    return max(i-1, 0)
+}
+
+// timeseriesRow is a count for one key at a point in time, the start of an
+// hour or the noon of a day.
+type timeseriesRow struct {
+    at    time.Time
+    key   string
+    count int
+}
+
+// timeseriesCounts reads the pageviews per hour and the unique visitors per
+// hour or day, depending on interval, for each key of group.
+func timeseriesCounts(filter reportFilter, interval types.TimeseriesInterval, group types.TimeseriesGroup, bots bool) ([]timeseriesRow, []timeseriesRow, error) {
+    var key = "''"
+    switch group {
+    case types.TGPage:
+        key = "page_path"
+    case types.TGCountry:
+        key = "country_code"
+    case types.TGReferrer:
+        key = "COALESCE(referrer, '')"
+    }
+
+    // Referrers are only in the raw events, for landing pageviews
+    var events, eventArgs = filter.events(bots)
+    var landings = ""
+    if group == types.TGReferrer {
+        landings = " WHERE referrer_kind IS NOT NULL"
+    }
+
+    var pageviewQuery string
+    var pageviewArgs []any
+    if group == types.TGReferrer {
+        pageviewQuery = `
+            SELECT strftime('%Y-%m-%d %H:00:00', timestamp) AS hour, ` + key + `, COUNT(*)
+            FROM ` + events + landings + `
+            GROUP BY 1, 2
+        `
+        pageviewArgs = eventArgs
+    } else {
+        var visits, visitArgs = hourlyVisits(bots, filter)
+        pageviewQuery = `SELECT strftime('%Y-%m-%d %H:00:00', hour), ` + key + `, SUM(visits) FROM ` + visits + ` GROUP BY 1, 2`
+        pageviewArgs = visitArgs
+    }
+
+    var pageviews, err = timeseriesRows(pageviewQuery, pageviewArgs, time.DateTime)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    var uniqueQuery, uniqueArgs, layout = "", []any(nil), time.DateOnly
+    switch {
+    case interval == types.TIHour:
+        uniqueQuery = `
+            SELECT strftime('%Y-%m-%d %H:00:00', timestamp) AS hour, ` + key + `, COUNT(DISTINCT visitor_id)
+            FROM ` + events + landings + `
+            GROUP BY 1, 2
+        `
+        uniqueArgs, layout = eventArgs, time.DateTime
+    case group == types.TGReferrer:
+        uniqueQuery = `
+            SELECT date(timestamp) AS day, ` + key + `, COUNT(DISTINCT visitor_id)
+            FROM ` + events + landings + `
+            GROUP BY 1, 2
+        `
+        uniqueArgs = eventArgs
+    default:
+        // Without a group the countries of a day are added up, which is
+        // all of its visitors as long as each was seen from one country
+        var column = "country_code"
+        if group == types.TGPage {
+            column = "page_path"
+        }
+        var stats string
+        stats, uniqueArgs = dailyStats(column, bots, filter)
+        uniqueQuery = `SELECT date(day), ` + key + `, SUM(visitors) FROM ` + stats + ` GROUP BY 1, 2`
+    }
+
+    var uniques []timeseriesRow
+    if uniques, err = timeseriesRows(uniqueQuery, uniqueArgs, layout); err != nil {
+        return nil, nil, err
+    }
+    if layout == time.DateOnly {
+        for i := range uniques {
+            uniques[i].at = uniques[i].at.Add(12 * time.Hour)
+        }
+    }
+
+    return pageviews, uniques, nil
+}
+
+func timeseriesRows(query string, args []any, layout string) ([]timeseriesRow, error) {
+    var rows, err = db.DB.Query(query, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var data []timeseriesRow
+    for rows.Next() {
+        var at string
+        var row timeseriesRow
+        if err := rows.Scan(&at, &row.key, &row.count); err != nil {
+            return nil, err
+        }
+        if row.at, err = time.Parse(layout, at); err != nil {
+            return nil, err
+        }
+        data = append(data, row)
+    }
+    return data, rows.Err()
+}
@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package analytics
+
+import (
+    "git.jelius.dev/jelius-sama/Portfolio/db"
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+    "github.com/gofiber/fiber/v3"
+    "github.com/jelius-sama/logger"
+)
+
+// GetTopCampaigns ranks the UTM campaigns visits came from. Percentages are
+// of the visits whose source is known, campaign or not.
+func GetTopCampaigns(c fiber.Ctx) error {
+    // Query parameters
+    var pageStr = c.Query("page", "0")
+    var limitStr = c.Query("limit", "10")
+    var sortOrder = c.Query("sort", "1") // asc or desc
+
+    var page, limit, sort, parseErr = parsePaginationParam(pageStr, limitStr, sortOrder)
+    if parseErr != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(*parseErr)
+    }
+
+    var filter, filterErr = parseReportFilter(c)
+    if filterErr != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(*filterErr)
+    }
+    var events, eventArgs = filter.events(includeBots(c))
+
+    // Get total count of distinct campaigns
+    var countQuery = `
+        SELECT COUNT(*) FROM (
+            SELECT 1
+            FROM ` + events + `
+            WHERE utm_source IS NOT NULL OR utm_medium IS NOT NULL OR utm_campaign IS NOT NULL
+            GROUP BY utm_source, utm_medium, utm_campaign
+        )
+    `
+
+    var totalRows int
+    if err := db.DB.QueryRow(countQuery, eventArgs...).Scan(&totalRows); err != nil {
+        logger.Error(c.Path(), err.Error())
+        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+            Code:    fiber.StatusInternalServerError,
+            Message: "Internal Server Error",
+        })
+    }
+
+    // Calculate offset
+    var offset = page * limit
+
+    // Get the visits with a known source for percentage calculation
+    var totalVisits int
+    var totalVisitsQuery = `SELECT COUNT(*) FROM ` + events + ` WHERE referrer_kind IS NOT NULL`
+    if err := db.DB.QueryRow(totalVisitsQuery, eventArgs...).Scan(&totalVisits); err != nil {
+        logger.Error(c.Path(), err.Error())
+        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+            Code:    fiber.StatusInternalServerError,
+            Message: "Internal Server Error",
+        })
+    }
+
+    // Query with pagination
+    var sortDir = "DESC"
+    if sort == types.SOAsc {
+        sortDir = "ASC"
+    }
+
+    var query = `
+        SELECT 
+            COALESCE(utm_source, '') as utm_source,
+            COALESCE(utm_medium, '') as utm_medium,
+            COALESCE(utm_campaign, '') as utm_campaign,
+            COUNT(*) as visit_count,
+            COUNT(DISTINCT visitor_id) as unique_visitors,
+            COUNT(DISTINCT session_id) as sessions
+        FROM ` + events + `
+        WHERE utm_source IS NOT NULL OR utm_medium IS NOT NULL OR utm_campaign IS NOT NULL
+        GROUP BY utm_source, utm_medium, utm_campaign
+        ORDER BY visit_count ` + sortDir + `
+        LIMIT ? OFFSET ?
+    `
+
+    var rows, queryErr = db.DB.Query(query, append(eventArgs, limit, offset)...)
+    if queryErr != nil {
+        logger.Error(c.Path(), queryErr.Error())
+        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+            Code:    fiber.StatusInternalServerError,
+            Message: "Internal Server Error",
+        })
+    }
+    defer rows.Close()
+
+    var data []types.TopCampaignResponse
+    for rows.Next() {
+        var resp types.TopCampaignResponse
+        if err := rows.Scan(
+            &resp.Source,
+            &resp.Medium,
+            &resp.Campaign,
+            &resp.VisitCount,
+            &resp.UniqueVisitors,
+            &resp.Sessions,
+        ); err != nil {
+            logger.Error(c.Path(), err.Error())
+            return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+                Code:    fiber.StatusInternalServerError,
+                Message: "Internal Server Error",
+            })
+        }
+        if totalVisits > 0 {
+            resp.Percentage = (float64(resp.VisitCount) / float64(totalVisits)) * 100
+        }
+        data = append(data, resp)
+    }
+
+    var hasMore bool = (offset + limit) < totalRows
+
+    return c.Status(fiber.StatusOK).JSON(types.PaginatedTopCampaignsResponse{
+        Data:      data,
+        Page:      page,
+        Limit:     limit,
+        HasMore:   hasMore,
+        TotalRows: totalRows,
+    })
+}
+
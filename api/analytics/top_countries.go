@@ -21,11 +21,12 @@ func GetTopCountries(c fiber.Ctx) error {
         return c.Status(fiber.StatusBadRequest).JSON(*parseErr)
     }
 
-    // Get total count of distinct countries
-    var countQuery = `SELECT COUNT(DISTINCT country_code) FROM analytics_events`
-
-    var totalRows int
-    if err := db.DB.QueryRow(countQuery).Scan(&totalRows); err != nil {
+    var filter, filterErr = parseReportFilter(c)
+    if filterErr != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(*filterErr)
+    }
+    var resp, err = topCountries(filter, includeBots(c), page, limit, sort)
+    if err != nil {
         logger.Error(c.Path(), err.Error())
         return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
             Code:    fiber.StatusInternalServerError,
@@ -33,18 +34,28 @@ func GetTopCountries(c fiber.Ctx) error {
         })
     }
 
+    return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func topCountries(filter reportFilter, bots bool, page, limit int, sort types.SortOrder) (*types.PaginatedTopCountriesResponse, error) {
+    var stats, statsArgs = dailyStats("country_code", bots, filter)
+
+    // Get total count of distinct countries
+    var countQuery = `SELECT COUNT(DISTINCT country_code) FROM ` + stats
+
+    var totalRows int
+    if err := db.DB.QueryRow(countQuery, statsArgs...).Scan(&totalRows); err != nil {
+        return nil, err
+    }
+
     // Calculate offset
     var offset = page * limit
 
     // Get total visits for percentage calculation
     var totalVisits int
-    var totalVisitsQuery = `SELECT COUNT(*) FROM analytics_events`
-    if err := db.DB.QueryRow(totalVisitsQuery).Scan(&totalVisits); err != nil {
-        logger.Error(c.Path(), err.Error())
-        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
-            Code:    fiber.StatusInternalServerError,
-            Message: "Internal Server Error",
-        })
+    var totalVisitsQuery = `SELECT COALESCE(SUM(visits), 0) FROM ` + stats
+    if err := db.DB.QueryRow(totalVisitsQuery, statsArgs...).Scan(&totalVisits); err != nil {
+        return nil, err
     }
 
     // Query with pagination
@@ -56,20 +67,18 @@ func GetTopCountries(c fiber.Ctx) error {
     query := `
         SELECT 
             country_code,
-            COUNT(*) as visit_count
-        FROM analytics_events
+            SUM(visits) as visit_count,
+            SUM(visitors) as unique_visitors,
+            SUM(sessions) as sessions
+        FROM ` + stats + `
         GROUP BY country_code
         ORDER BY visit_count ` + sortDir + `
         LIMIT ? OFFSET ?
     `
 
-    var rows, queryErr = db.DB.Query(query, limit, offset)
+    var rows, queryErr = db.DB.Query(query, append(statsArgs, limit, offset)...)
     if queryErr != nil {
-        logger.Error(c.Path(), queryErr.Error())
-        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
-            Code:    fiber.StatusInternalServerError,
-            Message: "Internal Server Error",
-        })
+        return nil, queryErr
     }
     defer rows.Close()
 
@@ -79,27 +88,27 @@ func GetTopCountries(c fiber.Ctx) error {
         if err := rows.Scan(
             &resp.CountryCode,
             &resp.VisitCount,
+            &resp.UniqueVisitors,
+            &resp.Sessions,
         ); err != nil {
-            logger.Error(c.Path(), err.Error())
-            return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
-                Code:    fiber.StatusInternalServerError,
-                Message: "Internal Server Error",
-            })
+            return nil, err
         }
         if totalVisits > 0 {
             resp.Percentage = (float64(resp.VisitCount) / float64(totalVisits)) * 100
         }
         data = append(data, resp)
     }
+    if err := rows.Err(); err != nil {
+        return nil, err
+    }
 
     var hasMore bool = (offset + limit) < totalRows
 
-    return c.Status(fiber.StatusOK).JSON(types.PaginatedTopCountriesResponse{
+    return &types.PaginatedTopCountriesResponse{
         Data:      data,
         Page:      page,
         Limit:     limit,
         HasMore:   hasMore,
         TotalRows: totalRows,
-    })
+    }, nil
 }
-
@@ -21,11 +21,12 @@ func GetTopPages(c fiber.Ctx) error {
         return c.Status(fiber.StatusBadRequest).JSON(*parseErr)
     }
 
-    // Get total count of distinct pages
-    var countQuery = `SELECT COUNT(DISTINCT page_path) FROM analytics_events`
-
-    var totalRows int
-    if err := db.DB.QueryRow(countQuery).Scan(&totalRows); err != nil {
+    var filter, filterErr = parseReportFilter(c)
+    if filterErr != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(*filterErr)
+    }
+    var resp, err = topPages(filter, includeBots(c), page, limit, sort)
+    if err != nil {
         logger.Error(c.Path(), err.Error())
         return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
             Code:    fiber.StatusInternalServerError,
@@ -33,18 +34,28 @@ func GetTopPages(c fiber.Ctx) error {
         })
     }
 
+    return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func topPages(filter reportFilter, bots bool, page, limit int, sort types.SortOrder) (*types.PaginatedTopPagesResponse, error) {
+    var stats, statsArgs = dailyStats("page_path", bots, filter)
+
+    // Get total count of distinct pages
+    var countQuery = `SELECT COUNT(DISTINCT page_path) FROM ` + stats
+
+    var totalRows int
+    if err := db.DB.QueryRow(countQuery, statsArgs...).Scan(&totalRows); err != nil {
+        return nil, err
+    }
+
     // Calculate offset
     var offset = page * limit
 
     // Get total visits for percentage calculation
     var totalVisits int
-    var totalVisitsQuery = `SELECT COUNT(*) FROM analytics_events`
-    if err := db.DB.QueryRow(totalVisitsQuery).Scan(&totalVisits); err != nil {
-        logger.Error(c.Path(), err.Error())
-        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
-            Code:    fiber.StatusInternalServerError,
-            Message: "Internal Server Error",
-        })
+    var totalVisitsQuery = `SELECT COALESCE(SUM(visits), 0) FROM ` + stats
+    if err := db.DB.QueryRow(totalVisitsQuery, statsArgs...).Scan(&totalVisits); err != nil {
+        return nil, err
     }
 
     // Query with pagination
@@ -56,50 +67,52 @@ func GetTopPages(c fiber.Ctx) error {
     var query = `
         SELECT 
             page_path,
-            COUNT(*) as visit_count
-        FROM analytics_events
+            SUM(visits) as visit_count,
+            SUM(visitors) as unique_visitors,
+            SUM(sessions) as sessions,
+            SUM(engaged_views), SUM(engaged_seconds), SUM(scroll_total), SUM(read_views)
+        FROM ` + stats + `
         GROUP BY page_path
         ORDER BY visit_count ` + sortDir + `
         LIMIT ? OFFSET ?
     `
 
-    var rows, queryErr = db.DB.Query(query, limit, offset)
+    var rows, queryErr = db.DB.Query(query, append(statsArgs, limit, offset)...)
     if queryErr != nil {
-        logger.Error(c.Path(), queryErr.Error())
-        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
-            Code:    fiber.StatusInternalServerError,
-            Message: "Internal Server Error",
-        })
+        return nil, queryErr
     }
     defer rows.Close()
 
     var data []types.TopPageResponse
     for rows.Next() {
         var resp types.TopPageResponse
+        var views, seconds, scroll, reads int
         if err := rows.Scan(
             &resp.PagePath,
             &resp.VisitCount,
+            &resp.UniqueVisitors,
+            &resp.Sessions,
+            &views, &seconds, &scroll, &reads,
         ); err != nil {
-            logger.Error(c.Path(), err.Error())
-            return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
-                Code:    fiber.StatusInternalServerError,
-                Message: "Internal Server Error",
-            })
+            return nil, err
         }
+        resp.PageEngagement = pageEngagement(views, seconds, scroll, reads)
         if totalVisits > 0 {
             resp.Percentage = (float64(resp.VisitCount) / float64(totalVisits)) * 100
         }
         data = append(data, resp)
     }
+    if err := rows.Err(); err != nil {
+        return nil, err
+    }
 
     var hasMore bool = (offset + limit) < totalRows
 
-    return c.Status(fiber.StatusOK).JSON(types.PaginatedTopPagesResponse{
+    return &types.PaginatedTopPagesResponse{
         Data:      data,
         Page:      page,
         Limit:     limit,
         HasMore:   hasMore,
         TotalRows: totalRows,
-    })
+    }, nil
 }
-
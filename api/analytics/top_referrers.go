@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package analytics
+
+import (
+    "git.jelius.dev/jelius-sama/Portfolio/db"
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+    "github.com/gofiber/fiber/v3"
+    "github.com/jelius-sama/logger"
+)
+
+// GetTopReferrers ranks where visits came from, direct visits included.
+// Percentages are of the visits whose source is known.
+func GetTopReferrers(c fiber.Ctx) error {
+    // Query parameters
+    var pageStr = c.Query("page", "0")
+    var limitStr = c.Query("limit", "10")
+    var sortOrder = c.Query("sort", "1") // asc or desc
+
+    var page, limit, sort, parseErr = parsePaginationParam(pageStr, limitStr, sortOrder)
+    if parseErr != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(*parseErr)
+    }
+
+    var filter, filterErr = parseReportFilter(c)
+    if filterErr != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(*filterErr)
+    }
+    var resp, err = topReferrers(filter, includeBots(c), page, limit, sort)
+    if err != nil {
+        logger.Error(c.Path(), err.Error())
+        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+            Code:    fiber.StatusInternalServerError,
+            Message: "Internal Server Error",
+        })
+    }
+
+    return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func topReferrers(filter reportFilter, bots bool, page, limit int, sort types.SortOrder) (*types.PaginatedTopReferrersResponse, error) {
+    var events, eventArgs = filter.events(bots)
+
+    // Get total count of distinct referrers
+    var countQuery = `
+        SELECT COUNT(*) FROM (
+            SELECT 1
+            FROM ` + events + `
+            WHERE referrer_kind IS NOT NULL
+            GROUP BY referrer_kind, referrer
+        )
+    `
+
+    var totalRows int
+    if err := db.DB.QueryRow(countQuery, eventArgs...).Scan(&totalRows); err != nil {
+        return nil, err
+    }
+
+    // Calculate offset
+    var offset = page * limit
+
+    // Get the visits with a known source for percentage calculation
+    var totalVisits int
+    var totalVisitsQuery = `SELECT COUNT(*) FROM ` + events + ` WHERE referrer_kind IS NOT NULL`
+    if err := db.DB.QueryRow(totalVisitsQuery, eventArgs...).Scan(&totalVisits); err != nil {
+        return nil, err
+    }
+
+    // Query with pagination
+    var sortDir = "DESC"
+    if sort == types.SOAsc {
+        sortDir = "ASC"
+    }
+
+    var query = `
+        SELECT 
+            COALESCE(referrer, '') as referrer,
+            referrer_kind,
+            COUNT(*) as visit_count,
+            COUNT(DISTINCT visitor_id) as unique_visitors,
+            COUNT(DISTINCT session_id) as sessions
+        FROM ` + events + `
+        WHERE referrer_kind IS NOT NULL
+        GROUP BY referrer_kind, referrer
+        ORDER BY visit_count ` + sortDir + `
+        LIMIT ? OFFSET ?
+    `
+
+    var rows, queryErr = db.DB.Query(query, append(eventArgs, limit, offset)...)
+    if queryErr != nil {
+        return nil, queryErr
+    }
+    defer rows.Close()
+
+    var data []types.TopReferrerResponse
+    for rows.Next() {
+        var resp types.TopReferrerResponse
+        if err := rows.Scan(
+            &resp.Referrer,
+            &resp.Kind,
+            &resp.VisitCount,
+            &resp.UniqueVisitors,
+            &resp.Sessions,
+        ); err != nil {
+            return nil, err
+        }
+        if totalVisits > 0 {
+            resp.Percentage = (float64(resp.VisitCount) / float64(totalVisits)) * 100
+        }
+        data = append(data, resp)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, err
+    }
+
+    var hasMore bool = (offset + limit) < totalRows
+
+    return &types.PaginatedTopReferrersResponse{
+        Data:      data,
+        Page:      page,
+        Limit:     limit,
+        HasMore:   hasMore,
+        TotalRows: totalRows,
+    }, nil
+}
@@ -0,0 +1,154 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package analytics
+
+import (
+    "regexp"
+    "strconv"
+
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+)
+
+// userAgent is what a User-Agent header tells about a visit.
+type userAgent struct {
+    browser string
+    // version is the major version of the browser, nil when unknown
+    version *int
+    os      string
+    device  types.DeviceClass
+}
+
+type uaRule struct {
+    name string
+    // re captures the major version, if at all, in a group
+    re *regexp.Regexp
+}
+
+// The rule tables below are checked top to bottom and the first match wins.
+// Most browsers claim to be several others for compatibility, Edge says it is
+// Chrome and Chrome says it is Safari, so more specific rules have to come
+// before the ones they would also match. When adding a rule, put it above
+// every rule its User-Agent also matches.
+
+// botRules recognize crawlers, link previewers, monitors and HTTP clients.
+var botRules = []uaRule{
+    {"Googlebot", regexp.MustCompile(`Googlebot(?:-\w+)?/(\d+)`)},
+    {"Google", regexp.MustCompile(`Google(?:-InspectionTool|-Read-Aloud|Other|-Extended| Favicon)`)},
+    {"Bingbot", regexp.MustCompile(`(?i)bingbot/(\d+)`)},
+    {"DuckDuckBot", regexp.MustCompile(`DuckDuck(?:Go-Favicons-)?Bot`)},
+    {"YandexBot", regexp.MustCompile(`Yandex\w*Bot/(\d+)`)},
+    {"Baiduspider", regexp.MustCompile(`Baiduspider`)},
+    {"Applebot", regexp.MustCompile(`Applebot/(\d+)`)},
+    {"GPTBot", regexp.MustCompile(`GPTBot/(\d+)`)},
+    {"ClaudeBot", regexp.MustCompile(`ClaudeBot/(\d+)`)},
+    {"Facebook", regexp.MustCompile(`facebookexternalhit/(\d+)|meta-externalagent`)},
+    {"Twitterbot", regexp.MustCompile(`Twitterbot/(\d+)`)},
+    {"LinkedInBot", regexp.MustCompile(`LinkedInBot/(\d+)`)},
+    {"Slackbot", regexp.MustCompile(`Slackbot`)},
+    {"Discordbot", regexp.MustCompile(`Discordbot/(\d+)`)},
+    {"TelegramBot", regexp.MustCompile(`TelegramBot`)},
+    {"UptimeRobot", regexp.MustCompile(`UptimeRobot/(\d+)`)},
+    {"Pingdom", regexp.MustCompile(`Pingdom`)},
+    {"Lighthouse", regexp.MustCompile(`Chrome-Lighthouse`)},
+    {"Headless Chrome", regexp.MustCompile(`HeadlessChrome/(\d+)`)},
+    {"Python", regexp.MustCompile(`^python-(?:requests|httpx|urllib\d?)/(\d+)|^Python-urllib/(\d+)`)},
+    {"Go", regexp.MustCompile(`^Go-http-client/(\d+)`)},
+    {"Node.js", regexp.MustCompile(`^(?:node-fetch|axios|undici)/(\d+)`)},
+    {"Other bot", regexp.MustCompile(`(?i)bot\b|crawl|spider|slurp|scrape|fetcher|monitor|checker|preview`)},
+}
+
+// terminalRules recognize text browsers and command line clients people read
+// pages with. They come after botRules, scripts use some of them too.
+var terminalRules = []uaRule{
+    {"curl", regexp.MustCompile(`^curl/(\d+)`)},
+    {"Wget", regexp.MustCompile(`^Wget/(\d+)`)},
+    {"HTTPie", regexp.MustCompile(`^HTTPie/(\d+)`)},
+    {"Lynx", regexp.MustCompile(`^Lynx/(\d+)`)},
+    {"w3m", regexp.MustCompile(`^w3m/(\d+)`)},
+    {"Links", regexp.MustCompile(`^E?Links \((\d+)`)},
+}
+
+var browserRules = []uaRule{
+    {"Edge", regexp.MustCompile(`Edg(?:e|A|iOS)?/(\d+)`)},
+    {"Opera", regexp.MustCompile(`(?:OPR|OPT|Opera)/(\d+)`)},
+    {"Samsung Internet", regexp.MustCompile(`SamsungBrowser/(\d+)`)},
+    {"Vivaldi", regexp.MustCompile(`Vivaldi/(\d+)`)},
+    {"Yandex Browser", regexp.MustCompile(`YaBrowser/(\d+)`)},
+    {"UC Browser", regexp.MustCompile(`UCBrowser/(\d+)`)},
+    {"Firefox", regexp.MustCompile(`(?:Firefox|FxiOS)/(\d+)`)},
+    {"Chrome", regexp.MustCompile(`(?:Chrome|CriOS)/(\d+)`)},
+    {"Safari", regexp.MustCompile(`Version/(\d+)[\d.]* (?:Mobile/\w+ )?Safari/`)},
+    {"Internet Explorer", regexp.MustCompile(`MSIE (\d+)|Trident/.*rv:(\d+)`)},
+}
+
+var osRules = []uaRule{
+    {"iOS", regexp.MustCompile(`iPhone|iPod`)},
+    {"iPadOS", regexp.MustCompile(`iPad`)},
+    {"Android", regexp.MustCompile(`Android`)},
+    {"ChromeOS", regexp.MustCompile(`CrOS`)},
+    {"Windows", regexp.MustCompile(`Windows`)},
+    {"macOS", regexp.MustCompile(`Mac OS X|Macintosh`)},
+    {"FreeBSD", regexp.MustCompile(`FreeBSD`)},
+    {"OpenBSD", regexp.MustCompile(`OpenBSD`)},
+    {"Linux", regexp.MustCompile(`Linux|X11`)},
+}
+
+var (
+    tabletRe = regexp.MustCompile(`iPad|Tablet|Kindle|Silk/|PlayBook`)
+    mobileRe = regexp.MustCompile(`Mobi|iPhone|iPod|Windows Phone|Android.*Mobile|Opera Mini`)
+)
+
+// parseUserAgent works out the browser, OS and device class of a User-Agent
+// header. Anything it can't tell is left empty, an empty header is a bot.
+func parseUserAgent(header string) userAgent {
+    if len(header) == 0 {
+        return userAgent{device: types.DCBot}
+    }
+
+    var ua userAgent
+    ua.os, _, _ = matchRules(osRules, header)
+
+    if name, version, ok := matchRules(botRules, header); ok {
+        ua.browser, ua.version, ua.device = name, version, types.DCBot
+        return ua
+    }
+    if name, version, ok := matchRules(terminalRules, header); ok {
+        ua.browser, ua.version, ua.device = name, version, types.DCTerminal
+        return ua
+    }
+
+    ua.browser, ua.version, _ = matchRules(browserRules, header)
+
+    switch {
+    // iPads say Mobile as well, so tablets go first
+    case tabletRe.MatchString(header):
+        ua.device = types.DCTablet
+    case mobileRe.MatchString(header):
+        ua.device = types.DCMobile
+    case ua.os == "Android":
+        // Android phones say Mobile, which the case above already took
+        ua.device = types.DCTablet
+    default:
+        ua.device = types.DCDesktop
+    }
+    return ua
+}
+
+func matchRules(rules []uaRule, header string) (string, *int, bool) {
+    for _, rule := range rules {
+        var m = rule.re.FindStringSubmatch(header)
+        if m == nil {
+            continue
+        }
+        // Alternatives of a rule each have their own group, at most one of
+        // them took part in the match
+        for _, group := range m[1:] {
+            if v, err := strconv.Atoi(group); err == nil {
+                return rule.name, &v, true
+            }
+        }
+        return rule.name, nil, true
+    }
+    return "", nil, false
+}
@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package analytics
+
+import (
+    "context"
+    "crypto/hmac"
+    "crypto/rand"
+    "crypto/sha256"
+    "database/sql"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "strings"
+    "sync"
+    "time"
+
+    "git.jelius.dev/jelius-sama/Portfolio/db"
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+)
+
+// sessionTimeout is how long a visitor can stay idle before their next
+// pageview starts a new session.
+const sessionTimeout = 30 * time.Minute
+
+// dailySalt is mixed into visitor IDs. It only ever lives in memory and is
+// replaced as soon as the UTC day changes, so the ID of a visitor can't be
+// linked to the one they get the next day, nor be recomputed from an IP
+// address once the day is over. A restart starts a new salt as well, which
+// at worst counts some visitors twice that day.
+var dailySalt struct {
+    mu   sync.Mutex
+    day  string
+    salt [32]byte
+}
+
+func saltFor(day string) [32]byte {
+    dailySalt.mu.Lock()
+    defer dailySalt.mu.Unlock()
+
+    if dailySalt.day != day {
+        rand.Read(dailySalt.salt[:])
+        dailySalt.day = day
+    }
+    return dailySalt.salt
+}
+
+// visitorID derives an anonymous ID for whoever is behind ip and userAgent,
+// the same for every request they make on the UTC day of now.
+func visitorID(now time.Time, ip, userAgent string) string {
+    var salt = saltFor(now.UTC().Format(time.DateOnly))
+
+    var mac = hmac.New(sha256.New, salt[:])
+    mac.Write([]byte(ip))
+    mac.Write([]byte{0})
+    mac.Write([]byte(userAgent))
+    return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// sessionMu keeps two pageviews of a visitor that arrive together from both
+// starting a session.
+var sessionMu sync.Mutex
+
+// pageview is one view of a page about to be recorded.
+type pageview struct {
+    visitor     string
+    countryCode string
+    pagePath    string
+    ua          userAgent
+    source      types.PageviewSource
+    // landing is set for the first pageview of a visit, the only one
+    // attributed to a referrer and campaign
+    landing      bool
+    referrer     string
+    referrerKind types.ReferrerKind
+    utmSource    *string
+    utmMedium    *string
+    utmCampaign  *string
+    utmTerm      *string
+    utmContent   *string
+    // bot is set for hits that go to bot_events instead
+    bot *botHit
+}
+
+// recordVisit stores a pageview, continuing the session of the visitor's
+// last pageview unless that is over sessionTimeout ago. Bot hits are kept
+// apart in bot_events, with sessions of their own. A beacon for a page the
+// server already recorded is merged into it instead. Pageviews of people
+// go out to the live streams too.
+//
+// It returns the ID of the pageview, which the tracking script reports its
+// engagement and custom events with.
+func recordVisit(ctx context.Context, pv pageview) (string, error) {
+    sessionMu.Lock()
+    defer sessionMu.Unlock()
+
+    if pv.source == types.PSBeacon {
+        if id, merged, err := mergeBeacon(ctx, pv); err != nil || merged {
+            return id, err
+        }
+    }
+
+    var table, botColumns, botArgs = "analytics_events", "", []any(nil)
+    if pv.bot != nil {
+        table, botColumns, botArgs = "bot_events", "reason, detail, ", []any{pv.bot.reason, pv.bot.detail}
+    }
+
+    var session string
+    if err := db.DB.QueryRowContext(ctx, `
+        SELECT session_id FROM `+table+`
+        WHERE visitor_id = ? AND timestamp > datetime('now', 'utc', ?)
+        ORDER BY event_id DESC
+        LIMIT 1
+    `, pv.visitor, fmt.Sprintf("-%d seconds", int(sessionTimeout.Seconds()))).Scan(&session); err != nil {
+        if !errors.Is(err, sql.ErrNoRows) {
+            return "", err
+        }
+        session = randomID(8)
+    }
+    var id = randomID(16)
+
+    var referrer, referrerKind any
+    if pv.landing {
+        referrer, referrerKind = sql.NullString{String: pv.referrer, Valid: len(pv.referrer) != 0}, pv.referrerKind
+    }
+
+    var args = append(botArgs,
+        pv.countryCode, pv.pagePath, pv.visitor, session, referrer, referrerKind,
+        pv.utmSource, pv.utmMedium, pv.utmCampaign, pv.utmTerm, pv.utmContent,
+        pv.ua.browser, pv.ua.version, pv.ua.os, pv.ua.device, pv.source, id,
+    )
+    var placeholders = strings.Repeat("?, ", len(botArgs))
+
+    var _, err = db.DB.ExecContext(ctx, `
+        INSERT INTO `+table+` (
+            `+botColumns+`country_code, page_path, visitor_id, session_id, referrer, referrer_kind,
+            utm_source, utm_medium, utm_campaign, utm_term, utm_content,
+            browser, browser_version, os, device, source, pageview_id, timestamp
+        )
+        VALUES (`+placeholders+`?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''), ?, NULLIF(?, ''), ?, ?, ?, datetime('now', 'utc'))
+    `, args...)
+    if err != nil {
+        return "", err
+    }
+
+    if pv.bot == nil {
+        live.publish(pv.visitor, types.LivePageview{
+            PagePath:    pv.pagePath,
+            CountryCode: pv.countryCode,
+            Timestamp:   time.Now().UTC(),
+        })
+    }
+    return id, nil
+}
+
+// randomID is n random bytes, hex encoded.
+func randomID(n int) string {
+    var b = make([]byte, n)
+    rand.Read(b)
+    return hex.EncodeToString(b)
+}
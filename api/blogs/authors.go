@@ -0,0 +1,234 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package blogs
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "regexp"
+    "strings"
+
+    "git.jelius.dev/jelius-sama/Portfolio/db"
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+    "github.com/gofiber/fiber/v3"
+    "github.com/jelius-sama/logger"
+)
+
+var authorHandleRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+const authorColumns = `a.handle, a.name, COALESCE(a.bio, ''), COALESCE(a.avatar_path, ''),
+    COALESCE(a.twitter, ''), COALESCE(a.github, ''), COALESCE(a.mastodon, ''), COALESCE(a.website, '')`
+
+func scanAuthor(row interface{ Scan(...any) error }, a *types.Author, extra ...any) error {
+    return row.Scan(append([]any{
+        &a.Handle, &a.Name, &a.Bio, &a.Avatar, &a.Twitter, &a.GitHub, &a.Mastodon, &a.Website,
+    }, extra...)...)
+}
+
+// Author returns the author with handle, or `sql.ErrNoRows`.
+func (s *BlogService) Author(ctx context.Context, handle string) (*types.Author, error) {
+    var a types.Author
+    if err := scanAuthor(db.DB.QueryRowContext(ctx,
+        `SELECT `+authorColumns+` FROM authors a WHERE a.handle = ?`, handle,
+    ), &a); err != nil {
+        return nil, err
+    }
+    return &a, nil
+}
+
+// AuthorPosts lists the published posts an author wrote or co-wrote,
+// newest first.
+func (s *BlogService) AuthorPosts(ctx context.Context, handle string) ([]types.BlogPost, error) {
+    var rows, err = db.DB.QueryContext(ctx, `
+        SELECT b.id, b.title, b.excerpt, b.published_at, b.updated_at, b.prequel_id, b.sequel_id
+        FROM blogs b
+        JOIN blog_authors ba ON ba.blog_id = b.id
+        JOIN authors a ON a.id = ba.author_id
+        WHERE a.handle = ? AND b.deleted_at IS NULL AND b.draft = 0
+        ORDER BY b.published_at DESC
+    `, handle)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var posts []types.BlogPost
+    for rows.Next() {
+        var post types.BlogPost
+        var excerpt sql.NullString
+        if err := rows.Scan(
+            &post.ID, &post.Title, &excerpt, &post.PublishedAt, &post.UpdatedAt, &post.PrequelID, &post.SequelID,
+        ); err != nil {
+            return nil, err
+        }
+        post.Excerpt = excerpt.String
+        posts = append(posts, post)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, err
+    }
+    rows.Close()
+
+    for i := range posts {
+        if posts[i].Views, err = s.analytics.PageVisitCount(ctx, "/blog/"+posts[i].ID); err != nil {
+            return nil, err
+        }
+    }
+    return posts, nil
+}
+
+// loadAuthors fills in the authors of every post in posts, keyed by ID.
+func loadAuthors(ctx context.Context, posts map[string]*types.BlogResponse) error {
+    if len(posts) == 0 {
+        return nil
+    }
+
+    var args = make([]any, 0, len(posts))
+    for id := range posts {
+        args = append(args, id)
+    }
+
+    var rows, err = db.DB.QueryContext(ctx, `
+        SELECT `+authorColumns+`, ba.blog_id
+        FROM blog_authors ba
+        JOIN authors a ON a.id = ba.author_id
+        WHERE ba.blog_id IN (?`+strings.Repeat(", ?", len(args)-1)+`)
+        ORDER BY ba.blog_id, ba.position
+    `, args...)
+    if err != nil {
+        return err
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var a types.Author
+        var blogID string
+        if err := scanAuthor(rows, &a, &blogID); err != nil {
+            return err
+        }
+        posts[blogID].Authors = append(posts[blogID].Authors, a)
+    }
+    return rows.Err()
+}
+
+// AuthorNames joins the names of everyone who wrote posts for a byline, in
+// order of appearance, falling back to the site owner when none of the posts
+// has authors.
+func AuthorNames(ctx context.Context, posts ...*types.BlogResponse) string {
+    var names []string
+    var seen = make(map[string]bool)
+    for _, post := range posts {
+        for _, a := range post.Authors {
+            if !seen[a.Handle] {
+                seen[a.Handle] = true
+                names = append(names, a.Name)
+            }
+        }
+    }
+
+    if len(names) == 0 {
+        return siteAuthor(ctx)
+    }
+    return strings.Join(names, ", ")
+}
+
+func siteAuthor(ctx context.Context) string {
+    var author string
+    if err := db.DB.QueryRowContext(ctx, `
+        SELECT first_name || ' ' || last_name FROM home_page ORDER BY updated_at DESC LIMIT 1
+    `).Scan(&author); err != nil {
+        return ""
+    }
+    return author
+}
+
+// lintAuthors checks that every handle a post is credited to exists.
+func (u *upload) lintAuthors(ctx context.Context, handles []string) error {
+    var seen = make(map[string]bool, len(handles))
+    for _, handle := range handles {
+        handle = strings.ToLower(strings.TrimSpace(handle))
+        if seen[handle] {
+            u.report(types.DSError, ruleAuthor, 0, "author %s is listed twice", handle)
+            continue
+        }
+        seen[handle] = true
+
+        var exists int
+        if err := db.DB.QueryRowContext(ctx, `SELECT 1 FROM authors WHERE handle = ?`, handle).Scan(&exists); err != nil {
+            if errors.Is(err, sql.ErrNoRows) {
+                u.report(types.DSError, ruleAuthor, 0, "author %s doesn't exist", handle)
+                continue
+            }
+            return err
+        }
+    }
+    return nil
+}
+
+// setAuthors credits a post to handles, in order. lintAuthors has to have
+// passed for them.
+func setAuthors(ctx context.Context, blogID string, handles []string) error {
+    for i, handle := range handles {
+        if _, err := db.DB.ExecContext(ctx, `
+            INSERT INTO blog_authors (blog_id, author_id, position)
+            SELECT ?, id, ? FROM authors WHERE handle = ?
+        `, blogID, i, strings.TrimSpace(handle)); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// CreateAuthor adds an author that posts can then be credited to.
+func CreateAuthor(c fiber.Ctx) error {
+    var req types.CreateAuthor
+    if err := c.Bind().Body(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
+            Code:    fiber.StatusBadRequest,
+            Message: "Invalid request body",
+        })
+    }
+
+    req.Handle = strings.ToLower(strings.TrimSpace(req.Handle))
+    if !authorHandleRe.MatchString(req.Handle) {
+        return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
+            Code:    fiber.StatusBadRequest,
+            Message: "handle must be 1 to 32 lowercase letters, digits, '-' or '_'",
+        })
+    }
+    if len(strings.TrimSpace(req.Name)) == 0 {
+        return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
+            Code:    fiber.StatusBadRequest,
+            Message: "Name is required",
+        })
+    }
+
+    var res, err = db.DB.ExecContext(c.RequestCtx(), `
+        INSERT INTO authors (handle, name, bio, avatar_path, twitter, github, mastodon, website)
+        VALUES (?, ?, NULLIF(?, ''), NULLIF(?, ''), NULLIF(?, ''), NULLIF(?, ''), NULLIF(?, ''), NULLIF(?, ''))
+        ON CONFLICT (handle) DO NOTHING
+    `,
+        req.Handle, strings.TrimSpace(req.Name), req.Bio, req.Avatar,
+        strings.TrimPrefix(req.Twitter, "@"), req.GitHub, strings.TrimPrefix(req.Mastodon, "@"), req.Website,
+    )
+    if err == nil {
+        var n int64
+        if n, err = res.RowsAffected(); err == nil && n == 0 {
+            return c.Status(fiber.StatusConflict).JSON(types.ErrorResp{
+                Code:    fiber.StatusConflict,
+                Message: "An author with this handle already exists",
+            })
+        }
+    }
+    if err != nil {
+        logger.Error(c.Path(), err.Error())
+        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+            Code:    fiber.StatusInternalServerError,
+            Message: "Failed to create author",
+        })
+    }
+
+    return c.SendStatus(fiber.StatusCreated)
+}
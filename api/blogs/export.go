@@ -0,0 +1,287 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package blogs
+
+import (
+    "crypto/sha256"
+    "database/sql"
+    "errors"
+    "fmt"
+    "io"
+    "io/fs"
+    "net/http"
+    "net/url"
+    "os"
+    "path"
+    "path/filepath"
+    "strings"
+    "sync"
+    "time"
+
+    "git.jelius.dev/jelius-sama/Portfolio/epub"
+    "git.jelius.dev/jelius-sama/Portfolio/pdf"
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+    "github.com/gofiber/fiber/v3"
+    "github.com/jelius-sama/logger"
+)
+
+// Images referenced by a post are fetched while its export is built, these
+// keep a slow or huge one from holding it up.
+const (
+    exportImageTimeout = 10 * time.Second
+    exportImageMaxSize = 10 << 20
+)
+
+var exportClient = &http.Client{Timeout: exportImageTimeout}
+
+// exportLocks serializes builds of the same file, keyed by its path.
+var exportLocks sync.Map
+
+// GetBlogPDF serves a post as a PDF, `?lang=` picks a translation. The PDF
+// fonts only cover Western European text, a translation in another script is
+// answered with 501 rather than a page of question marks.
+func (s *BlogService) GetBlogPDF(c fiber.Ctx) error {
+    var post, err = s.exportPost(c)
+    if err != nil {
+        return err
+    }
+
+    if lang := c.Query("lang"); len(lang) != 0 {
+        if lang, ok := NormalizeLang(lang); !ok || !ApplyTranslation(post, lang) {
+            return fiber.ErrNotFound
+        }
+    }
+
+    book, err := s.exportBook(c, []*types.BlogResponse{post})
+    if err != nil {
+        return err
+    }
+    return sendExport(c, "blog", book, ".pdf", "application/pdf", renderPDF)
+}
+
+// GetSeriesPDF serves the whole series a post belongs to as one PDF, any of
+// its parts can be used as the ID.
+func (s *BlogService) GetSeriesPDF(c fiber.Ctx) error {
+    var post, err = s.exportPost(c)
+    if err != nil {
+        return err
+    }
+
+    book, err := s.exportBook(c, post.Series())
+    if err != nil {
+        return err
+    }
+    return sendExport(c, "series", book, ".pdf", "application/pdf", renderPDF)
+}
+
+// GetSeriesEPUB serves the series a post belongs to as an EPUB, one chapter
+// per part.
+func (s *BlogService) GetSeriesEPUB(c fiber.Ctx) error {
+    var post, err = s.exportPost(c)
+    if err != nil {
+        return err
+    }
+
+    book, err := s.exportBook(c, post.Series())
+    if err != nil {
+        return err
+    }
+    return sendExport(c, "series", book, ".epub", epub.MediaType, renderEPUB)
+}
+
+func renderPDF(w io.Writer, book *types.ExportBook) error {
+    return pdf.Render(w, book, pdf.Options{LoadImage: loadExportImage})
+}
+
+func renderEPUB(w io.Writer, book *types.ExportBook) error {
+    return epub.Render(w, book, epub.Options{LoadImage: loadExportImage})
+}
+
+func (s *BlogService) exportPost(c fiber.Ctx) (*types.BlogResponse, error) {
+    var post, err = s.Get(c.RequestCtx(), c.Params("id"))
+    if err != nil {
+        if err == ErrMissingID || err == sql.ErrNoRows {
+            return nil, fiber.ErrNotFound
+        }
+        logger.Error(c.Path(), err.Error())
+        return nil, fiber.ErrInternalServerError
+    }
+    return post, nil
+}
+
+// exportBook puts parts together into a book, the first part lends it its
+// title and description.
+func (s *BlogService) exportBook(c fiber.Ctx, parts []*types.BlogResponse) (*types.ExportBook, error) {
+    var host = types.EVHostname.Get().Value
+    var first = parts[0]
+
+    var book = &types.ExportBook{
+        ID:          first.ID,
+        Title:       first.Title,
+        Description: first.Excerpt,
+        Author:      AuthorNames(c.RequestCtx(), parts...),
+        Lang:        first.ContentLang,
+        URL:         host + first.LocalizedPath(first.ContentLang),
+        Published:   first.PublishedAt,
+    }
+    if u, err := url.Parse(host); err == nil {
+        book.Site = u.Hostname()
+    }
+
+    for _, part := range parts {
+        var file, err = s.OpenMarkdown(MarkdownName(part, part.ContentLang))
+        if err != nil {
+            if errors.Is(err, fs.ErrNotExist) {
+                return nil, fiber.ErrNotFound
+            }
+            logger.Error(c.Path(), err.Error())
+            return nil, fiber.ErrInternalServerError
+        }
+        content, err := io.ReadAll(file)
+        file.Close()
+        if err != nil {
+            logger.Error(c.Path(), err.Error())
+            return nil, fiber.ErrInternalServerError
+        }
+
+        book.Chapters = append(book.Chapters, types.ExportChapter{
+            ID:        part.ID,
+            Title:     part.Title,
+            Excerpt:   part.Excerpt,
+            Lang:      part.ContentLang,
+            URL:       host + part.LocalizedPath(part.ContentLang),
+            Published: part.PublishedAt,
+            Updated:   part.UpdatedAt,
+            Markdown:  string(content),
+        })
+        if part.UpdatedAt.After(book.Updated) {
+            book.Updated = part.UpdatedAt
+        }
+    }
+
+    return book, nil
+}
+
+// sendExport serves book rendered by render, building it only when there is
+// no cached copy yet. Cached copies are named after the `updated_at` of every
+// chapter, so editing any of them (or deploying a new version) makes the next
+// request build a fresh one.
+func sendExport(c fiber.Ctx, kind string, book *types.ExportBook, ext, contentType string, render func(io.Writer, *types.ExportBook) error) error {
+    var h = sha256.New()
+    io.WriteString(h, types.EVVersion.Get().Value)
+    for _, chapter := range book.Chapters {
+        fmt.Fprintf(h, "\x00%s\x00%s\x00%d", chapter.ID, chapter.Lang, chapter.Updated.UnixNano())
+    }
+
+    var dir = filepath.Join(types.EVDataDir.Get().Value, "cache", "exports", kind)
+    var prefix = book.ID + "." + book.Lang + "."
+    var file = filepath.Join(dir, fmt.Sprintf("%s%x%s", prefix, h.Sum(nil)[:8], ext))
+
+    if err := buildExport(dir, prefix, ext, file, func(w io.Writer) error { return render(w, book) }); err != nil {
+        if errors.Is(err, pdf.ErrUnsupportedText) {
+            return fiber.NewError(fiber.StatusNotImplemented, "PDF export isn't available in this language")
+        }
+        logger.Error(c.Path(), err.Error())
+        return fiber.ErrInternalServerError
+    }
+
+    var name = book.ID
+    if kind != "blog" {
+        name += "-" + kind
+    }
+    c.Set(fiber.HeaderContentType, contentType)
+    c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`inline; filename="%s%s"`, name, ext))
+    return c.SendFile(file)
+}
+
+func buildExport(dir, prefix, ext, file string, render func(w io.Writer) error) error {
+    var lock, _ = exportLocks.LoadOrStore(file, &sync.Mutex{})
+    lock.(*sync.Mutex).Lock()
+    defer lock.(*sync.Mutex).Unlock()
+
+    if _, err := os.Stat(file); err == nil {
+        return nil
+    }
+
+    if err := os.MkdirAll(dir, 0o755); err != nil {
+        return err
+    }
+
+    // Render next to the final path and move it in place, so a half written
+    // file is never served
+    var tmp, err = os.CreateTemp(dir, ".build-*")
+    if err != nil {
+        return err
+    }
+    defer os.Remove(tmp.Name())
+
+    if err := render(tmp); err != nil {
+        tmp.Close()
+        return err
+    }
+    if err := tmp.Close(); err != nil {
+        return err
+    }
+    if err := os.Rename(tmp.Name(), file); err != nil {
+        return err
+    }
+
+    // Drop the versions this one replaces
+    if stale, err := filepath.Glob(filepath.Join(dir, prefix+"*"+ext)); err == nil {
+        for _, old := range stale {
+            if old != file {
+                os.Remove(old)
+            }
+        }
+    }
+    return nil
+}
+
+// loadExportImage returns the image behind an image source in a post. Files
+// under /assets/ are read from the data directory, anything else is fetched,
+// relative sources from the site itself.
+func loadExportImage(src string) ([]byte, error) {
+    var u, err = url.Parse(src)
+    if err != nil {
+        return nil, err
+    }
+
+    if !u.IsAbs() {
+        if rest, ok := strings.CutPrefix(u.Path, "/assets/"); ok {
+            var file = filepath.Join(types.EVDataDir.Get().Value, "assets", filepath.FromSlash(path.Clean("/"+rest)))
+            if data, err := os.ReadFile(file); err == nil {
+                return data, nil
+            }
+        }
+
+        base, err := url.Parse(types.EVHostname.Get().Value)
+        if err != nil {
+            return nil, err
+        }
+        u = base.ResolveReference(u)
+    }
+
+    if u.Scheme != "http" && u.Scheme != "https" {
+        return nil, fmt.Errorf("unsupported image source: %s", src)
+    }
+
+    resp, err := exportClient.Get(u.String())
+    if err != nil {
+        return nil, err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK {
+        return nil, fmt.Errorf("fetching %s: %s", u, resp.Status)
+    }
+
+    data, err := io.ReadAll(io.LimitReader(resp.Body, exportImageMaxSize+1))
+    if err != nil {
+        return nil, err
+    }
+    if len(data) > exportImageMaxSize {
+        return nil, fmt.Errorf("image too large: %s", u)
+    }
+    return data, nil
+}
@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package blogs
+
+import (
+    "context"
+    "database/sql"
+
+    "git.jelius.dev/jelius-sama/Portfolio/db"
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+    "github.com/gofiber/fiber/v3"
+    "github.com/jelius-sama/logger"
+)
+
+// PinBlog keeps a post at the top of the blog list, UnpinBlog lets it back
+// into its place.
+var (
+    PinBlog   = setFlag("pinned", true)
+    UnpinBlog = setFlag("pinned", false)
+)
+
+// FeatureBlog shows a post on the home page, UnfeatureBlog takes it off.
+var (
+    FeatureBlog   = setFlag("featured", true)
+    UnfeatureBlog = setFlag("featured", false)
+)
+
+// setFlag returns a handler setting the boolean column of the post with the
+// `:id` param. Setting a flag that is already set is not an error, so the
+// handlers can be retried.
+func setFlag(column string, value bool) fiber.Handler {
+    return func(c fiber.Ctx) error {
+        var res, err = db.DB.ExecContext(c.RequestCtx(),
+            `UPDATE blogs SET `+column+` = ? WHERE id = ? AND deleted_at IS NULL`, value, c.Params("id"),
+        )
+        if err == nil {
+            var n int64
+            if n, err = res.RowsAffected(); err == nil && n == 0 {
+                return c.Status(fiber.StatusNotFound).JSON(types.ErrorResp{
+                    Code:    fiber.StatusNotFound,
+                    Message: "Blog not found",
+                })
+            }
+        }
+        if err != nil {
+            logger.Error(c.Path(), err.Error())
+            return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+                Code:    fiber.StatusInternalServerError,
+                Message: "Failed to update blog",
+            })
+        }
+
+        return c.SendStatus(fiber.StatusOK)
+    }
+}
+
+// Featured returns up to limit published featured posts, newest first.
+func (s *BlogService) Featured(ctx context.Context, limit int) ([]types.BlogPost, error) {
+    var rows, err = db.DB.QueryContext(ctx, `
+        SELECT id, title, excerpt, published_at, updated_at, prequel_id, sequel_id, pinned
+        FROM blogs
+        WHERE featured = 1 AND deleted_at IS NULL AND draft = 0
+        ORDER BY published_at DESC
+        LIMIT ?
+    `, limit)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var posts []types.BlogPost
+    for rows.Next() {
+        var post = types.BlogPost{Featured: true}
+        var excerpt sql.NullString
+        if err := rows.Scan(
+            &post.ID, &post.Title, &excerpt, &post.PublishedAt, &post.UpdatedAt, &post.PrequelID, &post.SequelID, &post.Pinned,
+        ); err != nil {
+            return nil, err
+        }
+        post.Excerpt = excerpt.String
+        posts = append(posts, post)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, err
+    }
+    rows.Close()
+
+    for i := range posts {
+        if posts[i].Views, err = s.analytics.PageVisitCount(ctx, "/blog/"+posts[i].ID); err != nil {
+            return nil, err
+        }
+    }
+    return posts, nil
+}
@@ -4,67 +4,27 @@
 package blogs
 
 import (
-    "bytes"
+    "context"
     "database/sql"
-    "encoding/gob"
-    "errors"
-    "strconv"
-    "strings"
 
-    "git.jelius.dev/jelius-sama/Portfolio/api/analytics"
     "git.jelius.dev/jelius-sama/Portfolio/db"
     "git.jelius.dev/jelius-sama/Portfolio/types"
     "github.com/gofiber/fiber/v3"
     "github.com/jelius-sama/logger"
 )
 
-// NOTE:
-// var tempTestDirectCall = func() {
-//     buf := bytes.NewBufferString("abc1230") // Blog ID
-//     err := blogs.GetAllBlogs(nil, buf)
-//     if err == nil {
-//         var decodedResponse types.BlogResponse
-//         decErr := gob.NewDecoder(buf).Decode(&decodedResponse)
-//         if decErr != nil {
-//             logger.Error("Failed to decode Gob data:", decErr.Error())
-//             return
-//         }
-//         decodedResponse.Title // access fields
-//         // convert into human-readible
-//         humanReadable, _ := json.MarshalIndent(decodedResponse, "", "  ")
-//         logger.Okay("Buffer output:\n", string(humanReadable))
-//     } else {
-//         logger.Error("Error output:", err.Error())
-//     }
-// }
-
-// GetBlog retrieves a blog post by ID with its prequel and sequel chain
-func GetBlog(c fiber.Ctx, buf ...*bytes.Buffer) error {
-    var id string
-    if len(buf) != 0 {
-        id = buf[0].String()
-    } else {
-        id = c.Params("id")
-    }
-
-    if len(id) == 0 && len(buf) == 0 {
-        return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
-            Code:    fiber.StatusBadRequest,
-            Message: "Blog ID is required",
-        })
-    }
-
-    if len(id) == 0 && len(buf) != 0 {
-        return errors.New("Blog ID is not provided")
-    }
-
-    var blog, err = getBlogResponse(id)
+// GetBlog retrieves a blog post by ID with its prequel and sequel chain.
+// `?lang=` returns the title and excerpt of a translation instead.
+func (s *BlogService) GetBlog(c fiber.Ctx) error {
+    var blog, err = s.Get(c.RequestCtx(), c.Params("id"))
     if err != nil {
-        if len(buf) != 0 {
-            return err
-        }
-
-        if err == sql.ErrNoRows {
+        switch err {
+        case ErrMissingID:
+            return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
+                Code:    fiber.StatusBadRequest,
+                Message: "Blog ID is required",
+            })
+        case sql.ErrNoRows:
             return c.Status(fiber.StatusNotFound).JSON(types.ErrorResp{
                 Code:    fiber.StatusNotFound,
                 Message: "Blog not found",
@@ -77,9 +37,13 @@ func GetBlog(c fiber.Ctx, buf ...*bytes.Buffer) error {
         })
     }
 
-    if len(buf) != 0 {
-        buf[0].Reset()
-        return gob.NewEncoder(buf[0]).Encode(blog)
+    if lang := c.Query("lang"); len(lang) != 0 {
+        if lang, ok := NormalizeLang(lang); !ok || !ApplyTranslation(blog, lang) {
+            return c.Status(fiber.StatusNotFound).JSON(types.ErrorResp{
+                Code:    fiber.StatusNotFound,
+                Message: "Translation not found",
+            })
+        }
     }
 
     return c.Status(fiber.StatusOK).JSON(blog)
@@ -93,32 +57,50 @@ type scannedBlog struct {
     Depth     int
 }
 
-func getBlogResponse(targetID string) (*types.BlogResponse, error) {
+// Get retrieves a blog post by ID with its prequel and sequel chain, in the
+// post's own language. Returns `sql.ErrNoRows` when there is no such post or
+// it is a draft.
+func (s *BlogService) Get(ctx context.Context, targetID string) (*types.BlogResponse, error) {
+    return s.get(ctx, targetID, false)
+}
+
+// GetDraft is Get for previews, drafts are included both as the post itself
+// and as parts of its series.
+func (s *BlogService) GetDraft(ctx context.Context, targetID string) (*types.BlogResponse, error) {
+    return s.get(ctx, targetID, true)
+}
+
+func (s *BlogService) get(ctx context.Context, targetID string, drafts bool) (*types.BlogResponse, error) {
+    if len(targetID) == 0 {
+        return nil, ErrMissingID
+    }
+
     var query = `
         WITH RECURSIVE 
         prequels AS (
-            SELECT id, title, excerpt, published_at, updated_at, deleted_at, prequel_id, sequel_id, 0 AS depth
-            FROM blogs WHERE id = ? AND deleted_at IS NULL
+            SELECT id, title, excerpt, lang, published_at, updated_at, deleted_at, prequel_id, sequel_id, draft, 0 AS depth
+            FROM blogs WHERE id = ? AND deleted_at IS NULL AND (draft = 0 OR ?)
             UNION ALL
-            SELECT b.id, b.title, b.excerpt, b.published_at, b.updated_at, b.deleted_at, b.prequel_id, b.sequel_id, p.depth - 1
-            FROM blogs b JOIN prequels p ON b.id = p.prequel_id WHERE b.deleted_at IS NULL
+            SELECT b.id, b.title, b.excerpt, b.lang, b.published_at, b.updated_at, b.deleted_at, b.prequel_id, b.sequel_id, b.draft, p.depth - 1
+            FROM blogs b JOIN prequels p ON b.id = p.prequel_id WHERE b.deleted_at IS NULL AND (b.draft = 0 OR ?)
         ),
         sequels AS (
-            SELECT id, title, excerpt, published_at, updated_at, deleted_at, prequel_id, sequel_id, 0 AS depth
-            FROM blogs WHERE id = ? AND deleted_at IS NULL
+            SELECT id, title, excerpt, lang, published_at, updated_at, deleted_at, prequel_id, sequel_id, draft, 0 AS depth
+            FROM blogs WHERE id = ? AND deleted_at IS NULL AND (draft = 0 OR ?)
             UNION ALL
-            SELECT b.id, b.title, b.excerpt, b.published_at, b.updated_at, b.deleted_at, b.prequel_id, b.sequel_id, s.depth + 1
-            FROM blogs b JOIN sequels s ON b.id = s.sequel_id WHERE b.deleted_at IS NULL
+            SELECT b.id, b.title, b.excerpt, b.lang, b.published_at, b.updated_at, b.deleted_at, b.prequel_id, b.sequel_id, b.draft, s.depth + 1
+            FROM blogs b JOIN sequels s ON b.id = s.sequel_id WHERE b.deleted_at IS NULL AND (b.draft = 0 OR ?)
         ),
         combined_chain AS (
             SELECT * FROM prequels UNION SELECT * FROM sequels
         )
-        SELECT id, title, excerpt, published_at, updated_at, deleted_at, prequel_id, sequel_id, depth 
+        SELECT id, title, excerpt, lang, published_at, updated_at, deleted_at, prequel_id, sequel_id, draft, depth 
         FROM combined_chain ORDER BY depth ASC;
     `
 
-    // Pass targetID twice: once for the prequels CTE, once for the sequels CTE
-    var rows, err = db.DB.Query(query, targetID, targetID)
+    // Pass targetID and the draft switches twice: once for the prequels CTE,
+    // once for the sequels CTE
+    var rows, err = db.DB.QueryContext(ctx, query, targetID, drafts, drafts, targetID, drafts, drafts)
     if err != nil {
         return nil, err
     }
@@ -132,9 +114,9 @@ func getBlogResponse(targetID string) (*types.BlogResponse, error) {
         var deletedAt sql.NullTime
 
         err := rows.Scan(
-            &sb.Response.ID, &sb.Response.Title, &sb.Response.Excerpt,
+            &sb.Response.ID, &sb.Response.Title, &sb.Response.Excerpt, &sb.Response.Lang,
             &sb.Response.PublishedAt, &sb.Response.UpdatedAt, &deletedAt,
-            &sb.PrequelID, &sb.SequelID, &sb.Depth,
+            &sb.PrequelID, &sb.SequelID, &sb.Response.Draft, &sb.Depth,
         )
         if err != nil {
             return nil, err
@@ -142,6 +124,7 @@ func getBlogResponse(targetID string) (*types.BlogResponse, error) {
         if deletedAt.Valid {
             sb.Response.DeletedAt = &deletedAt.Time
         }
+        sb.Response.ContentLang = sb.Response.Lang
 
         blogMap[sb.Response.ID] = &sb
         rowCount++
@@ -154,16 +137,25 @@ func getBlogResponse(targetID string) (*types.BlogResponse, error) {
 
     // First, populate view counts sequentially
     for _, item := range blogMap {
-        var viewCountBuf strings.Builder
-        viewCountBuf.WriteString("/blog/")
-        viewCountBuf.WriteString(item.Response.ID)
-        if err := analytics.GetPageVisitCount(nil, &viewCountBuf); err == nil {
-            if views, err := strconv.Atoi(viewCountBuf.String()); err == nil {
-                item.Response.Views = uint(views)
-            }
+        if views, err := s.analytics.PageVisitCount(ctx, "/blog/"+item.Response.ID); err == nil {
+            item.Response.Views = views
+        }
+    }
+
+    if targetBlog, exists := blogMap[targetID]; exists {
+        if targetBlog.Response.Translations, err = getTranslations(ctx, targetID); err != nil {
+            return nil, err
         }
     }
 
+    var posts = make(map[string]*types.BlogResponse, len(blogMap))
+    for id, item := range blogMap {
+        posts[id] = &item.Response
+    }
+    if err := loadAuthors(ctx, posts); err != nil {
+        return nil, err
+    }
+
     // Stitch pointers based on depth to prevent JSON marshal cycles
     for _, item := range blogMap {
         // Root item (Depth == 0) gets BOTH prequel and sequel tracks populated
@@ -4,11 +4,8 @@
 package blogs
 
 import (
-    "bytes"
-    "encoding/gob"
-    "fmt"
+    "context"
     "strconv"
-    "strings"
 
     "git.jelius.dev/jelius-sama/Portfolio/api/analytics"
     "git.jelius.dev/jelius-sama/Portfolio/db"
@@ -17,106 +14,38 @@ import (
     "github.com/jelius-sama/logger"
 )
 
-// NOTE:
-//	var tempTestDirectCall = func() {
-//	    buf := bytes.NewBufferString("page=1sort=0")
-//	    err := blogs.GetAllBlogs(nil, buf)
-//	    if err == nil {
-//	        var decodedResponse types.PaginatedBlogsResponse
-//	        decErr := gob.NewDecoder(buf).Decode(&decodedResponse)
-//	        if decErr != nil {
-//	            logger.Error("Failed to decode Gob data:", decErr.Error())
-//	            return
-//	        }
-//          decodedResponse.Title // access fields
-//          // convert into human-readible
-//	        humanReadable, _ := json.MarshalIndent(decodedResponse, "", "  ")
-//	        logger.Okay("Buffer output:\n", string(humanReadable))
-//	    } else {
-//	        logger.Error("Error output:", err.Error())
-//	    }
-//	}
-
 // GetAllBlogs retrieves all non-deleted blog posts with pagination and sorting
-func GetAllBlogs(c fiber.Ctx, buf ...*bytes.Buffer) error {
-    var page int = 1
-    var sort types.BlogsSortOrder = types.BSONew
-
-    var validateSort = func(t any, s *types.BlogsSortOrder) error {
-        switch t := t.(type) {
-        case string:
-            if order, err := strconv.Atoi(t); err != nil {
-                return fmt.Errorf("invalid sorting parameter")
-            } else if bso := types.BlogsSortOrder(order); bso != types.BSONew && bso != types.BSOOld && bso != types.BSOPopular {
-                return fmt.Errorf("invalid sorting parameter")
-            } else {
-                *s = bso
-            }
-            return nil
-        case types.BlogsSortOrder:
-            if t != types.BSONew && t != types.BSOOld && t != types.BSOPopular {
-                return fmt.Errorf("invalid sorting parameter")
-            } else {
-                *s = t
-            }
-            return nil
-        default:
-            return fmt.Errorf("unsupported type in validator function")
-        }
+func (s *BlogService) GetAllBlogs(c fiber.Ctx) error {
+    var page, pageErr = strconv.Atoi(c.Query("page", "1"))
+    if pageErr != nil || page < 0 {
+        return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
+            Code:    fiber.StatusBadRequest,
+            Message: "page must be a non-negative and non-zero integer",
+        })
     }
 
-    var readDigits = func(s string) string {
-        var end int
-        for end < len(s) && s[end] >= '0' && s[end] <= '9' {
-            end++
-        }
-        return s[:end]
+    var sort, sortErr = strconv.Atoi(c.Query("sort", "0"))
+    if sortErr != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
+            Code:    fiber.StatusBadRequest,
+            Message: "sort must be a valid sorting parameter",
+        })
     }
 
-    if has := len(buf) > 0; has && buf[0] == nil {
-        return fmt.Errorf("length of variadic greater than 0 but nil buffer\n")
-    } else if has {
-        var str = buf[0].String()
-        if _, after, found := strings.Cut(str, "page="); found {
-            if val, err := strconv.Atoi(readDigits(after)); err == nil {
-                page = val
-            }
-        }
-
-        if _, after, found := strings.Cut(str, "sort="); found {
-            if val, err := strconv.Atoi(readDigits(after)); err == nil {
-                sort = types.BlogsSortOrder(val)
-            }
-        }
-
-        if err := validateSort(sort, &sort); err != nil {
-            return fmt.Errorf("page must be a non-negative integer\n")
-        }
-    } else {
-        if p, pageErr := strconv.Atoi(c.Query("page", "1")); pageErr != nil || p < 0 {
+    var resp, err = s.List(c.RequestCtx(), page, types.BlogsSortOrder(sort))
+    if err != nil {
+        switch err {
+        case ErrInvalidPage:
             return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
                 Code:    fiber.StatusBadRequest,
                 Message: "page must be a non-negative and non-zero integer",
             })
-        } else {
-            page = p
-        }
-
-        if err := validateSort(c.Query("sort", "0"), &sort); err != nil {
+        case ErrInvalidSort:
             return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
                 Code:    fiber.StatusBadRequest,
                 Message: "sort must be a valid sorting parameter",
             })
         }
-    }
-
-    // Get total count of non-deleted blogs
-    var countQuery = `SELECT COUNT(*) FROM blogs WHERE deleted_at IS NULL`
-    var totalRows int
-    if err := db.DB.QueryRow(countQuery).Scan(&totalRows); err != nil {
-        if len(buf) != 0 {
-            return err
-        }
 
         logger.Error(c.Path(), err.Error())
         return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
@@ -125,11 +54,31 @@ func GetAllBlogs(c fiber.Ctx, buf ...*bytes.Buffer) error {
         })
     }
 
+    return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// List returns one page of published blog posts in the given sort order.
+func (s *BlogService) List(ctx context.Context, page int, sort types.BlogsSortOrder) (*types.PaginatedBlogsResponse, error) {
+    if page < 0 {
+        return nil, ErrInvalidPage
+    }
+    if !sort.IsValid() {
+        return nil, ErrInvalidSort
+    }
+
+    // Get total count of published blogs
+    var countQuery = `SELECT COUNT(*) FROM blogs WHERE deleted_at IS NULL AND draft = 0`
+    var totalRows int
+    if err := db.DB.QueryRowContext(ctx, countQuery).Scan(&totalRows); err != nil {
+        return nil, err
+    }
+
     // Calculate offset
     var offset = (page - 1) * types.PostPerPage
 
     // Build query with sorting
     var orderBy string
+    var args []any
     switch sort {
     case types.BSONew:
         orderBy = "published_at DESC"
@@ -137,31 +86,34 @@ func GetAllBlogs(c fiber.Ctx, buf ...*bytes.Buffer) error {
         orderBy = "published_at ASC"
     case types.BSOPopular:
         orderBy = "visit_count DESC"
+    case types.BSOTrending:
+        var window, gravity = trendingParams()
+        orderBy = trendingScoreExpr + " DESC, visit_count DESC, published_at DESC"
+        args = append(args, window, gravity)
     }
+    args = append(args, types.PostPerPage, offset)
+
+    // Pinned posts are ordered ahead of the rest in the same query rather
+    // than prepended to the first page, so LIMIT/OFFSET pages through one
+    // sequence and a pinned post never shows up again further down. The id
+    // breaks ties, ties being free to come out in a different order each
+    // time otherwise.
 
     var query = `
         SELECT 
             b.id, b.title, b.excerpt, b.published_at, b.updated_at, b.deleted_at, b.prequel_id, b.sequel_id,
-            COALESCE(COUNT(ae.event_id), 0) as visit_count
+            b.pinned, b.featured, COALESCE(SUM(ae.visits), 0) as visit_count
         FROM blogs b
-        LEFT JOIN analytics_events ae ON ae.page_path = '/blog/' || b.id
-        WHERE b.deleted_at IS NULL
+        LEFT JOIN ` + analytics.HourlyVisits() + ` ae ON ae.page_path = '/blog/' || b.id
+        WHERE b.deleted_at IS NULL AND b.draft = 0
         GROUP BY b.id
-        ORDER BY ` + orderBy + `
+        ORDER BY b.pinned DESC, ` + orderBy + `, b.id
         LIMIT ? OFFSET ?
     `
 
-    var rows, queryErr = db.DB.Query(query, types.PostPerPage, offset)
-    if queryErr != nil {
-        if len(buf) != 0 {
-            return queryErr
-        }
-
-        logger.Error(c.Path(), queryErr.Error())
-        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
-            Code:    fiber.StatusInternalServerError,
-            Message: "Internal Server Error",
-        })
+    var rows, err = db.DB.QueryContext(ctx, query, args...)
+    if err != nil {
+        return nil, err
     }
     defer rows.Close()
 
@@ -177,38 +129,18 @@ func GetAllBlogs(c fiber.Ctx, buf ...*bytes.Buffer) error {
             &post.DeletedAt,
             &post.PrequelID,
             &post.SequelID,
+            &post.Pinned,
+            &post.Featured,
             &post.Views,
         ); err != nil {
-            if len(buf) != 0 {
-                return err
-            }
-
-            logger.Error(c.Path(), err.Error())
-            return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
-                Code:    fiber.StatusInternalServerError,
-                Message: "Internal Server Error",
-            })
+            return nil, err
         }
 
         // Get view count for this blog post
-        var viewCountBuf strings.Builder
-        viewCountBuf.WriteString("/blog/")
-        viewCountBuf.WriteString(post.ID)
-        if err := analytics.GetPageVisitCount(nil, &viewCountBuf); err != nil {
-            if len(buf) != 0 {
-                return err
-            }
-
-            logger.Error(c.Path(), err.Error())
-            return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
-                Code:    fiber.StatusInternalServerError,
-                Message: "Internal Server Error",
-            })
+        if post.Views, err = s.analytics.PageVisitCount(ctx, "/blog/"+post.ID); err != nil {
+            return nil, err
         }
 
-        views, _ := strconv.Atoi(viewCountBuf.String())
-        post.Views = uint(views)
-
         data = append(data, post)
     }
 
@@ -222,11 +154,6 @@ func GetAllBlogs(c fiber.Ctx, buf ...*bytes.Buffer) error {
         Sort:      sort,
     }
 
-    if len(buf) != 0 {
-        buf[0].Reset()
-        return gob.NewEncoder(buf[0]).Encode(resp)
-    }
-
-    return c.Status(fiber.StatusOK).JSON(resp)
+    return &resp, nil
 }
 
@@ -4,71 +4,53 @@
 package blogs
 
 import (
-    "errors"
     "io"
     "os"
     "path/filepath"
 
+    "git.jelius.dev/jelius-sama/Portfolio/db"
     "git.jelius.dev/jelius-sama/Portfolio/types"
     "github.com/gofiber/fiber/v3"
 )
 
-//	func exampleCaller() {
-//	    var stream io.ReadCloser = io.NopCloser(strings.NewReader("/blog/abc1230"))
-//
-//	    if err := GetBlogMarkdown(nil, &stream); err != nil {
-//	        return
-//	    }
-//	    // 'stream' has now been mutated! It is now an *os.File descriptor.
-//	    // We MUST defer closing it to avoid resource leaks.
-//	    defer stream.Close()
-//
-//	    // Manually step through the file chunk-by-chunk
-//	    var chunkBuffer = make([]byte, 4096)
-//	    for {
-//	        n, err := stream.Read(chunkBuffer)
-//	        if n > 0 {
-//	            // Process chunkBuffer[:n] manually
-//	        }
-//	        if err == io.EOF {
-//	            break // Finished chunk-by-chunk manual reading
-//	        }
-//	    }
-//	}
-func GetBlogMarkdown(c fiber.Ctx, buf ...*io.ReadCloser) error {
-    var id string
-
-    if has := len(buf) > 0; has && buf[0] == nil {
-        return errors.New("length of variadic greater than 0 but nil buffer")
-    } else if has {
-        if idBytes, err := io.ReadAll(*buf[0]); err == nil {
-            id = string(idBytes)
-            (*buf[0]).Close()
-        }
-    } else {
-        id = c.Params("id")
+// GetBlogMarkdown serves the raw markdown of a post, `?lang=` picks a translation.
+func (s *BlogService) GetBlogMarkdown(c fiber.Ctx) error {
+    var id = c.Params("id")
+    if len(id) == 0 {
+        return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
+            Code:    fiber.StatusBadRequest,
+            Message: ErrMissingID.Error(),
+        })
     }
 
-    // Validate ID
-    if len(id) == 0 {
-        var err = errors.New("blog id is required")
-        if len(buf) == 0 {
-            return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
-                Code:    fiber.StatusBadRequest,
-                Message: err.Error(),
-            })
-        }
-        return err
+    // Drafts are only readable through a preview
+    var draft bool
+    if err := db.DB.QueryRowContext(c.RequestCtx(), `SELECT draft FROM blogs WHERE id = ?`, id).Scan(&draft); err == nil && draft {
+        return c.Status(fiber.StatusNotFound).JSON(types.ErrorResp{
+            Code:    fiber.StatusNotFound,
+            Message: "Blog not found",
+        })
     }
 
-    var filePath = filepath.Join(types.EVDataDir.Get().Value, "blogs", id+".md")
-    if len(buf) == 0 {
-        return c.SendFile(filePath)
+    if lang := c.Query("lang"); len(lang) != 0 {
+        if lang, ok := NormalizeLang(lang); ok {
+            id += "." + lang
+        }
     }
 
-    var file, err = os.Open(filePath)
+    return c.SendFile(markdownPath(id))
+}
 
-    *buf[0] = file
-    return err
+// OpenMarkdown opens the markdown file called name in the blogs directory, a
+// post ID or `<id>.<lang>` for a translation (see `MarkdownName`). The caller
+// has to close it.
+func (s *BlogService) OpenMarkdown(name string) (io.ReadCloser, error) {
+    if len(name) == 0 {
+        return nil, ErrMissingID
+    }
+    return os.Open(markdownPath(name))
 }
 
+func markdownPath(name string) string {
+    return filepath.Join(types.EVDataDir.Get().Value, "blogs", name+".md")
+}
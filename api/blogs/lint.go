@@ -0,0 +1,329 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package blogs
+
+import (
+    "bytes"
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "io"
+    "mime/multipart"
+    "net/url"
+    "path"
+    "slices"
+    "strings"
+    "unicode/utf8"
+
+    "git.jelius.dev/jelius-sama/Portfolio/db"
+    "git.jelius.dev/jelius-sama/Portfolio/markdown"
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+    "github.com/gofiber/fiber/v3"
+)
+
+// maxMarkdownSize caps uploaded markdown, generous for prose but small
+// enough to turn away binaries and generated dumps.
+const maxMarkdownSize = 1 << 20
+
+// Rules a diagnostic can be about
+const (
+    ruleSize     = "size"
+    ruleEncoding = "encoding"
+    ruleTitle    = "title"
+    ruleImageAlt = "image-alt"
+    ruleLink     = "link"
+    ruleCodeLang = "code-lang"
+    ruleMath     = "math"
+    ruleSeries   = "series"
+    ruleAuthor   = "author"
+)
+
+// knownCodeLangs are the code fence languages we expect to see. Anything
+// else is most likely a typo, which only costs the label on the code block.
+var knownCodeLangs = map[string]bool{
+    "asm": true, "awk": true, "bash": true, "c": true, "clojure": true, "cmake": true,
+    "console": true, "cpp": true, "cs": true, "csharp": true, "css": true, "csv": true,
+    "dart": true, "diff": true, "dockerfile": true, "elixir": true, "erlang": true,
+    "fish": true, "go": true, "gomod": true, "graphql": true, "haskell": true, "hcl": true,
+    "html": true, "http": true, "ini": true, "java": true, "javascript": true, "js": true,
+    "json": true, "jsonc": true, "jsx": true, "kotlin": true, "latex": true, "lua": true,
+    "makefile": true, "markdown": true, "math": true, "md": true, "nginx": true, "nix": true,
+    "ocaml": true, "perl": true, "php": true, "plaintext": true, "powershell": true,
+    "proto": true, "python": true, "py": true, "r": true, "regex": true, "ruby": true,
+    "rust": true, "scala": true, "scss": true, "sh": true, "shell": true, "sql": true,
+    "svelte": true, "swift": true, "templ": true, "tex": true, "text": true, "toml": true,
+    "ts": true, "tsx": true, "txt": true, "typescript": true, "vim": true, "vue": true,
+    "wasm": true, "xml": true, "yaml": true, "yml": true, "zig": true, "zsh": true,
+}
+
+// upload is a markdown file that is checked before it gets stored.
+type upload struct {
+    src []byte
+    // title is the one given with the upload, or else the heading the
+    // markdown opens with
+    title string
+    // routes are the paths of the pages the app serves, same-site links
+    // have to lead to one of them
+    routes []string
+    diags  []types.Diagnostic
+}
+
+func (u *upload) report(severity types.DiagnosticSeverity, rule string, line int, format string, args ...any) {
+    u.diags = append(u.diags, types.Diagnostic{
+        Severity: severity,
+        Rule:     rule,
+        Line:     line,
+        Message:  fmt.Sprintf(format, args...),
+    })
+}
+
+func (u *upload) failed() bool {
+    return slices.ContainsFunc(u.diags, func(d types.Diagnostic) bool {
+        return d.Severity == types.DSError
+    })
+}
+
+func (u *upload) warnings() []types.Diagnostic {
+    var warnings = make([]types.Diagnostic, 0, len(u.diags))
+    for _, d := range u.diags {
+        if d.Severity == types.DSWarning {
+            warnings = append(warnings, d)
+        }
+    }
+    slices.SortStableFunc(warnings, byLine)
+    return warnings
+}
+
+// lintUpload reads the markdown file of an upload and checks it. Problems
+// with the content end up as diagnostics, the error is for everything else.
+func lintUpload(c fiber.Ctx, fh *multipart.FileHeader, title string) (*upload, error) {
+    var ctx = c.RequestCtx()
+    var u = &upload{title: strings.TrimSpace(title), routes: pageRoutes(c.App())}
+
+    var f, err = fh.Open()
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+
+    // Read one byte past the limit to tell a file at the limit from one over it
+    if u.src, err = io.ReadAll(io.LimitReader(f, maxMarkdownSize+1)); err != nil {
+        return nil, err
+    }
+
+    switch {
+    case len(u.src) > maxMarkdownSize:
+        u.report(types.DSError, ruleSize, 0, "file is larger than %d KiB", maxMarkdownSize>>10)
+    case len(bytes.TrimSpace(u.src)) == 0:
+        u.report(types.DSError, ruleSize, 0, "file is empty")
+    case bytes.IndexByte(u.src, 0) != -1:
+        u.report(types.DSError, ruleEncoding, lineAt(u.src, bytes.IndexByte(u.src, 0)), "file contains NUL bytes, it is not markdown")
+    case !utf8.Valid(u.src):
+        u.report(types.DSError, ruleEncoding, lineAt(u.src, invalidUTF8(u.src)), "file is not valid UTF-8")
+    }
+    if u.failed() {
+        // Nothing past here makes sense for a file we can't read as text
+        return u, nil
+    }
+
+    var src = string(u.src)
+    if strings.HasPrefix(src, "\uFEFF") {
+        u.report(types.DSWarning, ruleEncoding, 1, "file starts with a byte order mark, it will be removed")
+        src = strings.TrimPrefix(src, "\uFEFF")
+        u.src = []byte(src)
+    }
+
+    var doc = markdown.Parse(src)
+    u.lintTitle(doc)
+
+    type link struct {
+        url  string
+        line int
+    }
+    var links []link
+    doc.WalkInlines(func(_ *markdown.Block, in *markdown.Inline) {
+        switch in.Kind {
+        case markdown.IKImage:
+            if len(strings.TrimSpace(markdown.PlainText(in.Children))) == 0 {
+                u.report(types.DSWarning, ruleImageAlt, in.Line, "image %q has no alt text", in.URL)
+            }
+            links = append(links, link{in.URL, in.Line})
+        case markdown.IKLink:
+            links = append(links, link{in.URL, in.Line})
+        }
+    })
+    for _, l := range links {
+        if err := u.lintLink(ctx, l.url, l.line); err != nil {
+            return nil, err
+        }
+    }
+
+    doc.Walk(func(b *markdown.Block) bool {
+        if b.Kind == markdown.BKCodeBlock {
+            if lang := strings.ToLower(b.Lang()); len(lang) != 0 && !knownCodeLangs[lang] {
+                u.report(types.DSWarning, ruleCodeLang, b.Line, "unknown code block language %q", b.Lang())
+            }
+        }
+        return true
+    })
+
+    var _, _, mathWarnings = markdown.RenderMath(src)
+    for _, w := range mathWarnings {
+        u.report(types.DSWarning, ruleMath, w.Line, "%s will be shown as source: %s", w.Source, w.Err)
+    }
+
+    return u, nil
+}
+
+// lintTitle makes sure the post has a title, taking it from the heading the
+// markdown opens with when the upload didn't come with one.
+func (u *upload) lintTitle(doc *markdown.Document) {
+    if len(u.title) != 0 {
+        return
+    }
+
+    if len(doc.Blocks) != 0 && doc.Blocks[0].Kind == markdown.BKHeading && doc.Blocks[0].Level == 1 {
+        u.title = strings.TrimSpace(markdown.PlainText(doc.Blocks[0].Inlines))
+    }
+    if len(u.title) == 0 {
+        u.report(types.DSError, ruleTitle, 1, "a title is required, either with the upload or as a `# Heading` on the first line")
+    }
+}
+
+// pageRoutes lists the paths of every GET route of app. The catch-all that
+// serves assets in development would match any link, so it is left out.
+func pageRoutes(app *fiber.App) []string {
+    var routes []string
+    for _, r := range app.GetRoutes(true) {
+        if r.Method == fiber.MethodGet && r.Path != "/*" {
+            routes = append(routes, r.Path)
+        }
+    }
+    return routes
+}
+
+// blogBase is where posts live, relative links in them resolve against it.
+var blogBase = &url.URL{Path: "/blog/"}
+
+// lintLink checks that a link to somewhere on this site leads to a page.
+// External links are left to the link checker, and so are links to files
+// since exports and assets aren't known here.
+func (u *upload) lintLink(ctx context.Context, href string, line int) error {
+    var target, err = url.Parse(strings.TrimSpace(href))
+    if err != nil {
+        u.report(types.DSError, ruleLink, line, "link %q can't be parsed", href)
+        return nil
+    }
+    if target.IsAbs() || len(target.Host) != 0 || len(target.Path) == 0 {
+        return nil
+    }
+    target = blogBase.ResolveReference(target)
+    if len(path.Ext(target.Path)) != 0 {
+        return nil
+    }
+
+    if fiber.RoutePatternMatch(target.Path, "/blog/:id") || fiber.RoutePatternMatch(target.Path, "/:lang/blog/:id") {
+        var id = path.Base(target.Path)
+        var draft bool
+        if err := db.DB.QueryRowContext(ctx,
+            `SELECT draft FROM blogs WHERE id = ? AND deleted_at IS NULL`, id,
+        ).Scan(&draft); err != nil {
+            if errors.Is(err, sql.ErrNoRows) {
+                u.report(types.DSError, ruleLink, line, "link %q points to post %s, which doesn't exist", href, id)
+                return nil
+            }
+            return err
+        }
+        if draft {
+            u.report(types.DSWarning, ruleLink, line, "link %q points to post %s, which is still a draft", href, id)
+        }
+        return nil
+    }
+
+    for _, pattern := range u.routes {
+        if fiber.RoutePatternMatch(target.Path, pattern) {
+            return nil
+        }
+    }
+    u.report(types.DSError, ruleLink, line, "link %q points to %s, which isn't a page", href, target.Path)
+    return nil
+}
+
+// lintSeries checks the posts a new post is said to follow and precede.
+func (u *upload) lintSeries(ctx context.Context, prequelID, sequelID *string) error {
+    if prequelID != nil && sequelID != nil && len(*prequelID) != 0 && *prequelID == *sequelID {
+        u.report(types.DSError, ruleSeries, 0, "post %s can't be both the prequel and the sequel", *prequelID)
+        return nil
+    }
+
+    for _, ref := range []struct {
+        field string
+        id    *string
+        // back is the column of the referenced post that should lead
+        // to the new one
+        back string
+    }{
+        {"prequel_id", prequelID, "sequel_id"},
+        {"sequel_id", sequelID, "prequel_id"},
+    } {
+        if ref.id == nil || len(*ref.id) == 0 {
+            continue
+        }
+
+        var other sql.NullString
+        if err := db.DB.QueryRowContext(ctx,
+            `SELECT `+ref.back+` FROM blogs WHERE id = ? AND deleted_at IS NULL`, *ref.id,
+        ).Scan(&other); err != nil {
+            if errors.Is(err, sql.ErrNoRows) {
+                u.report(types.DSError, ruleSeries, 0, "%s %s doesn't exist", ref.field, *ref.id)
+                continue
+            }
+            return err
+        }
+        if len(other.String) != 0 {
+            u.report(types.DSWarning, ruleSeries, 0, "%s %s already has %s %s, the series won't lead to this post from there", ref.field, *ref.id, ref.back, other.String)
+        }
+    }
+    return nil
+}
+
+// rejectUpload answers with everything lintUpload found, in file order.
+func rejectUpload(c fiber.Ctx, u *upload) error {
+    slices.SortStableFunc(u.diags, byLine)
+
+    var errs int
+    for _, d := range u.diags {
+        if d.Severity == types.DSError {
+            errs++
+        }
+    }
+
+    return c.Status(fiber.StatusUnprocessableEntity).JSON(types.LintErrorResp{
+        Code:        fiber.StatusUnprocessableEntity,
+        Message:     fmt.Sprintf("Markdown has %d error(s)", errs),
+        Diagnostics: u.diags,
+    })
+}
+
+func byLine(a, b types.Diagnostic) int {
+    return a.Line - b.Line
+}
+
+// lineAt returns the 1-based line the byte at offset i is on.
+func lineAt(src []byte, i int) int {
+    return bytes.Count(src[:i], []byte("\n")) + 1
+}
+
+// invalidUTF8 returns the offset of the first byte that isn't valid UTF-8.
+func invalidUTF8(src []byte) int {
+    for i := 0; i < len(src); {
+        var r, size = utf8.DecodeRune(src[i:])
+        if r == utf8.RuneError && size == 1 {
+            return i
+        }
+        i += size
+    }
+    return len(src)
+}
@@ -4,9 +4,6 @@
 package blogs
 
 import (
-    "bytes"
-    "encoding/gob"
-    "fmt"
     "strconv"
     "strings"
 
@@ -16,7 +13,7 @@ import (
     "github.com/jelius-sama/logger"
 )
 
-func GetBlogsPage(c fiber.Ctx) error {
+func (s *BlogService) GetBlogsPage(c fiber.Ctx) error {
     var pageStr = c.Query("page", "1")
     var sort types.BlogsSortOrder = types.BSONew
 
@@ -28,6 +25,8 @@ func GetBlogsPage(c fiber.Ctx) error {
             sort = types.BSOPopular
         case types.BSOOld.String():
             sort = types.BSOOld
+        case types.BSOTrending.String():
+            sort = types.BSOTrending
         default:
             return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
                 Code:    fiber.StatusBadRequest,
@@ -46,19 +45,8 @@ func GetBlogsPage(c fiber.Ctx) error {
         page = p
     }
 
-    var dataBuf bytes.Buffer
-    if _, err := fmt.Fprintf(&dataBuf, "page=%dsort=%d", page, sort); err != nil {
-        logger.Error(c.Path(), err.Error())
-        return fiber.NewError(fiber.StatusInternalServerError, "Internal Server Error")
-    }
-
-    if err := GetAllBlogs(nil, &dataBuf); err != nil {
-        logger.Error(c.Path(), err.Error())
-        return fiber.NewError(fiber.StatusInternalServerError, "Internal Server Error")
-    }
-
-    var decodedResponse types.PaginatedBlogsResponse
-    if err := gob.NewDecoder(&dataBuf).Decode(&decodedResponse); err != nil {
+    var resp, err = s.List(c.RequestCtx(), page, sort)
+    if err != nil {
         logger.Error(c.Path(), err.Error())
         return fiber.NewError(fiber.StatusInternalServerError, "Internal Server Error")
     }
@@ -67,12 +55,12 @@ func GetBlogsPage(c fiber.Ctx) error {
 
     if page == 1 {
         if err := pages.BlogsSection(pages.BlogsSectionArgs{
-            Post:        decodedResponse.Data,
-            HasMore:     decodedResponse.HasMore,
-            TotalPosts:  decodedResponse.TotalRows,
-            LoadedPages: decodedResponse.Page,
-            TotalPages:  (decodedResponse.TotalRows + decodedResponse.Limit - 1) / decodedResponse.Limit,
-            Sort:        decodedResponse.Sort,
+            Post:        resp.Data,
+            HasMore:     resp.HasMore,
+            TotalPosts:  resp.TotalRows,
+            LoadedPages: resp.Page,
+            TotalPages:  (resp.TotalRows + resp.Limit - 1) / resp.Limit,
+            Sort:        resp.Sort,
         }).Render(c.RequestCtx(), &buf); err != nil {
             return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
                 Code:    fiber.StatusInternalServerError,
@@ -84,7 +72,7 @@ func GetBlogsPage(c fiber.Ctx) error {
         return c.SendString(buf.String())
     }
 
-    if err := pages.BlogPostsOOB(decodedResponse.Data).Render(c.RequestCtx(), &buf); err != nil {
+    if err := pages.BlogPostsOOB(resp.Data).Render(c.RequestCtx(), &buf); err != nil {
         return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
             Code:    fiber.StatusInternalServerError,
             Message: err.Error(),
@@ -93,12 +81,12 @@ func GetBlogsPage(c fiber.Ctx) error {
 
     if err := pages.BlogInfoOOBUpdate(pages.BlogInfoArgs{
         Sort:        sort,
-        LoadedPosts: ((page - 1) * decodedResponse.Limit) + len(decodedResponse.Data),
+        LoadedPosts: ((page - 1) * resp.Limit) + len(resp.Data),
         // NOTE: For mathematicians out there, golang stores the length of an array internally, it is more efficient to use that length than to do fancy math which only waste more CPU cycles
-        // LoadedPosts: ((page - 1) * decodedResponse.Limit) + min(decodedResponse.Limit, decodedResponse.TotalRows - ((page - 1) * decodedResponse.Limit)),
-        TotalPosts:  decodedResponse.TotalRows,
-        LoadedPages: decodedResponse.Page,
-        TotalPages:  (decodedResponse.TotalRows + decodedResponse.Limit - 1) / decodedResponse.Limit,
+        // LoadedPosts: ((page - 1) * resp.Limit) + min(resp.Limit, resp.TotalRows - ((page - 1) * resp.Limit)),
+        TotalPosts:  resp.TotalRows,
+        LoadedPages: resp.Page,
+        TotalPages:  (resp.TotalRows + resp.Limit - 1) / resp.Limit,
     }).Render(c.RequestCtx(), &buf); err != nil {
         return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
             Code:    fiber.StatusInternalServerError,
@@ -106,7 +94,7 @@ func GetBlogsPage(c fiber.Ctx) error {
         })
     }
 
-    if page >= (decodedResponse.TotalRows+decodedResponse.Limit-1)/decodedResponse.Limit {
+    if page >= (resp.TotalRows+resp.Limit-1)/resp.Limit {
         if err := pages.BlogEndOfPosts().Render(c.RequestCtx(), &buf); err != nil {
             return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
                 Code:    fiber.StatusInternalServerError,
@@ -114,7 +102,7 @@ func GetBlogsPage(c fiber.Ctx) error {
             })
         }
     } else {
-        if err := pages.BlogLoadMoreTrigger(decodedResponse.Page, sort).Render(c.RequestCtx(), &buf); err != nil {
+        if err := pages.BlogLoadMoreTrigger(resp.Page, sort).Render(c.RequestCtx(), &buf); err != nil {
             return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
                 Code:    fiber.StatusInternalServerError,
                 Message: err.Error(),
@@ -11,6 +11,7 @@ import (
     "path/filepath"
 
     "git.jelius.dev/jelius-sama/Portfolio/db"
+    "git.jelius.dev/jelius-sama/Portfolio/newsletter"
     "git.jelius.dev/jelius-sama/Portfolio/types"
     "github.com/gofiber/fiber/v3"
     "github.com/jelius-sama/logger"
@@ -26,12 +27,15 @@ func CreateBlog(c fiber.Ctx) error {
         })
     }
 
-    // Validate required fields
-    if len(req.Title) == 0 {
-        return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
-            Code:    fiber.StatusBadRequest,
-            Message: "Title is required",
-        })
+    var lang = types.DefaultBlogLang
+    if len(req.Lang) != 0 {
+        var ok bool
+        if lang, ok = NormalizeLang(req.Lang); !ok {
+            return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
+                Code:    fiber.StatusBadRequest,
+                Message: "Invalid language",
+            })
+        }
     }
 
     // Get markdown file from form
@@ -43,6 +47,26 @@ func CreateBlog(c fiber.Ctx) error {
         })
     }
 
+    // The title can also come from the markdown, so it is checked as a part
+    // of it
+    var upload, lintErr = lintUpload(c, file, req.Title)
+    if lintErr == nil {
+        lintErr = upload.lintSeries(c.RequestCtx(), req.PrequelID, req.SequelID)
+    }
+    if lintErr == nil {
+        lintErr = upload.lintAuthors(c.RequestCtx(), req.Authors)
+    }
+    if lintErr != nil {
+        logger.Error(c.Path(), lintErr.Error())
+        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+            Code:    fiber.StatusInternalServerError,
+            Message: "Internal Server Error",
+        })
+    }
+    if upload.failed() {
+        return rejectUpload(c, upload)
+    }
+
     var randomBytes = make([]byte, 16)
     if _, err := rand.Read(randomBytes); err != nil {
         logger.Error(err)
@@ -56,11 +80,19 @@ func CreateBlog(c fiber.Ctx) error {
     var id = hex.EncodeToString(hash[:])[:7]
 
     var query = `
-        INSERT INTO blogs (id, title, excerpt, prequel_id, sequel_id, published_at, updated_at)
-        VALUES (?, ?, ?, ?, ?, datetime('now'), datetime('now'))
+        INSERT INTO blogs (id, title, excerpt, lang, prequel_id, sequel_id, draft, pinned, featured, published_at, updated_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'), datetime('now'))
     `
 
-    if _, err := db.DB.Exec(query, id, req.Title, req.Excerpt, req.PrequelID, req.SequelID); err != nil {
+    if _, err := db.DB.Exec(query, id, upload.title, req.Excerpt, lang, req.PrequelID, req.SequelID, req.Draft, req.Pinned, req.Featured); err != nil {
+        logger.Error(c.Path(), err.Error())
+        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+            Code:    fiber.StatusInternalServerError,
+            Message: "Failed to create blog",
+        })
+    }
+
+    if err := setAuthors(c.RequestCtx(), id, req.Authors); err != nil {
         logger.Error(c.Path(), err.Error())
         return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
             Code:    fiber.StatusInternalServerError,
@@ -80,7 +112,7 @@ func CreateBlog(c fiber.Ctx) error {
 
     // Save markdown file
     var filePath = filepath.Join(blogsDir, id+".md")
-    if err := c.SaveFile(file, filePath); err != nil {
+    if err := os.WriteFile(filePath, upload.src, 0o644); err != nil {
         logger.Error(c.Path(), err.Error())
         return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
             Code:    fiber.StatusInternalServerError,
@@ -88,6 +120,17 @@ func CreateBlog(c fiber.Ctx) error {
         })
     }
 
-    return c.SendStatus(fiber.StatusCreated)
+    // The post is live at this point, a failure here only costs the mail.
+    // Drafts are mailed out once they get published instead.
+    if !req.Draft {
+        if err := newsletter.QueuePost(c.RequestCtx(), id); err != nil {
+            logger.Error(c.Path(), err.Error())
+        }
+    }
+
+    return c.Status(fiber.StatusCreated).JSON(types.UploadResp{
+        ID:       id,
+        Warnings: upload.warnings(),
+    })
 }
 
@@ -0,0 +1,193 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package blogs
+
+import (
+    "context"
+    "crypto/hmac"
+    "crypto/rand"
+    "crypto/sha256"
+    "database/sql"
+    "encoding/base64"
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+
+    "git.jelius.dev/jelius-sama/Portfolio/db"
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+    "github.com/gofiber/fiber/v3"
+    "github.com/jelius-sama/logger"
+)
+
+const (
+    defaultPreviewTTL = 72 * time.Hour
+    maxPreviewTTL     = 30 * 24 * time.Hour
+)
+
+var ErrInvalidPreview = errors.New("invalid or expired preview token")
+
+var previewKey struct {
+    once sync.Once
+    key  []byte
+    err  error
+}
+
+// previewSecret is the key preview tokens are signed with: `PREVIEW_SECRET`
+// when set, otherwise one generated on first use and kept in the data
+// directory so links survive restarts.
+func previewSecret() ([]byte, error) {
+    previewKey.once.Do(func() {
+        if secret := types.EVPreviewSecret.Get().Value; len(secret) != 0 {
+            previewKey.key = []byte(secret)
+            return
+        }
+
+        var file = filepath.Join(types.EVDataDir.Get().Value, "preview.key")
+        if key, err := os.ReadFile(file); err == nil && len(key) >= 32 {
+            previewKey.key = key
+            return
+        }
+
+        var key = make([]byte, 32)
+        if _, err := rand.Read(key); err != nil {
+            previewKey.err = err
+            return
+        }
+        if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+            previewKey.err = err
+            return
+        }
+        if err := os.WriteFile(file, key, 0o600); err != nil {
+            previewKey.err = err
+            return
+        }
+        previewKey.key = key
+    })
+    return previewKey.key, previewKey.err
+}
+
+func signPreview(key []byte, id string, generation int64, expires int64) string {
+    var mac = hmac.New(sha256.New, key)
+    fmt.Fprintf(mac, "%s\x00%d\x00%d", id, generation, expires)
+    return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyPreview checks a `?preview=` token for the post with id. Tokens look
+// like `<expiry>.<generation>.<signature>`, bumping the post's generation
+// revokes every token minted before.
+func VerifyPreview(ctx context.Context, id, token string) error {
+    var parts = strings.Split(token, ".")
+    if len(parts) != 3 {
+        return ErrInvalidPreview
+    }
+    var expires, expErr = strconv.ParseInt(parts[0], 10, 64)
+    var generation, genErr = strconv.ParseInt(parts[1], 10, 64)
+    if expErr != nil || genErr != nil || time.Now().Unix() > expires {
+        return ErrInvalidPreview
+    }
+
+    var key, err = previewSecret()
+    if err != nil {
+        return err
+    }
+    if !hmac.Equal([]byte(parts[2]), []byte(signPreview(key, id, generation, expires))) {
+        return ErrInvalidPreview
+    }
+
+    var current int64
+    if err := db.DB.QueryRowContext(ctx,
+        `SELECT preview_generation FROM blogs WHERE id = ? AND deleted_at IS NULL`, id,
+    ).Scan(&current); err != nil {
+        if errors.Is(err, sql.ErrNoRows) {
+            return ErrInvalidPreview
+        }
+        return err
+    }
+    if current != generation {
+        return ErrInvalidPreview
+    }
+    return nil
+}
+
+// CreatePreview mints a preview link for a post, valid for `?ttl=` (a Go
+// duration, 72h by default and 30 days at most).
+func CreatePreview(c fiber.Ctx) error {
+    var ttl = defaultPreviewTTL
+    if s := c.Query("ttl"); len(s) != 0 {
+        var d, err = time.ParseDuration(s)
+        if err != nil || d <= 0 || d > maxPreviewTTL {
+            return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
+                Code:    fiber.StatusBadRequest,
+                Message: "ttl must be a positive duration of at most 720h",
+            })
+        }
+        ttl = d
+    }
+
+    var id = c.Params("id")
+    var generation int64
+    if err := db.DB.QueryRowContext(c.RequestCtx(),
+        `SELECT preview_generation FROM blogs WHERE id = ? AND deleted_at IS NULL`, id,
+    ).Scan(&generation); err != nil {
+        if errors.Is(err, sql.ErrNoRows) {
+            return c.Status(fiber.StatusNotFound).JSON(types.ErrorResp{
+                Code:    fiber.StatusNotFound,
+                Message: "Blog not found",
+            })
+        }
+        logger.Error(c.Path(), err.Error())
+        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+            Code:    fiber.StatusInternalServerError,
+            Message: "Internal Server Error",
+        })
+    }
+
+    var key, err = previewSecret()
+    if err != nil {
+        logger.Error(c.Path(), err.Error())
+        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+            Code:    fiber.StatusInternalServerError,
+            Message: "Internal Server Error",
+        })
+    }
+
+    var expires = time.Now().Add(ttl).Truncate(time.Second)
+    var token = fmt.Sprintf("%d.%d.%s", expires.Unix(), generation, signPreview(key, id, generation, expires.Unix()))
+
+    return c.Status(fiber.StatusCreated).JSON(types.PreviewLink{
+        URL:       fmt.Sprintf("%s/blog/%s?preview=%s", types.EVHostname.Get().Value, id, token),
+        ExpiresAt: expires.UTC(),
+    })
+}
+
+// RevokePreviews invalidates every preview link of a post minted so far.
+func RevokePreviews(c fiber.Ctx) error {
+    var res, err = db.DB.ExecContext(c.RequestCtx(),
+        `UPDATE blogs SET preview_generation = preview_generation + 1 WHERE id = ? AND deleted_at IS NULL`,
+        c.Params("id"),
+    )
+    if err == nil {
+        var n int64
+        if n, err = res.RowsAffected(); err == nil && n == 0 {
+            return c.Status(fiber.StatusNotFound).JSON(types.ErrorResp{
+                Code:    fiber.StatusNotFound,
+                Message: "Blog not found",
+            })
+        }
+    }
+    if err != nil {
+        logger.Error(c.Path(), err.Error())
+        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+            Code:    fiber.StatusInternalServerError,
+            Message: "Internal Server Error",
+        })
+    }
+
+    return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package blogs
+
+import (
+    "database/sql"
+    "errors"
+
+    "git.jelius.dev/jelius-sama/Portfolio/db"
+    "git.jelius.dev/jelius-sama/Portfolio/newsletter"
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+    "github.com/gofiber/fiber/v3"
+    "github.com/jelius-sama/logger"
+)
+
+// PublishBlog makes a draft public, dated to now, and mails it to the
+// newsletter subscribers.
+func PublishBlog(c fiber.Ctx) error {
+    var id = c.Params("id")
+
+    var draft bool
+    if err := db.DB.QueryRowContext(c.RequestCtx(),
+        `SELECT draft FROM blogs WHERE id = ? AND deleted_at IS NULL`, id,
+    ).Scan(&draft); err != nil {
+        if errors.Is(err, sql.ErrNoRows) {
+            return c.Status(fiber.StatusNotFound).JSON(types.ErrorResp{
+                Code:    fiber.StatusNotFound,
+                Message: "Blog not found",
+            })
+        }
+        logger.Error(c.Path(), err.Error())
+        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+            Code:    fiber.StatusInternalServerError,
+            Message: "Internal Server Error",
+        })
+    }
+
+    if !draft {
+        return c.Status(fiber.StatusConflict).JSON(types.ErrorResp{
+            Code:    fiber.StatusConflict,
+            Message: "Blog is already published",
+        })
+    }
+
+    if _, err := db.DB.ExecContext(c.RequestCtx(), `
+        UPDATE blogs SET draft = 0, published_at = datetime('now'), updated_at = datetime('now')
+        WHERE id = ?
+    `, id); err != nil {
+        logger.Error(c.Path(), err.Error())
+        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+            Code:    fiber.StatusInternalServerError,
+            Message: "Failed to publish blog",
+        })
+    }
+
+    if err := newsletter.QueuePost(c.RequestCtx(), id); err != nil {
+        logger.Error(c.Path(), err.Error())
+    }
+
+    return c.SendStatus(fiber.StatusOK)
+}
@@ -0,0 +1,27 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package blogs
+
+import (
+    "errors"
+
+    "git.jelius.dev/jelius-sama/Portfolio/api/analytics"
+)
+
+var (
+    ErrMissingID   = errors.New("blog id is required")
+    ErrInvalidSort = errors.New("invalid sorting parameter")
+    ErrInvalidPage = errors.New("page must be a non-negative integer")
+)
+
+// BlogService reads posts for in-process callers such as the renderers. The
+// HTTP handlers hanging off it are thin wrappers that parse the request and
+// encode the result.
+type BlogService struct {
+    analytics *analytics.AnalyticsService
+}
+
+func NewService(analyticsService *analytics.AnalyticsService) *BlogService {
+    return &BlogService{analytics: analyticsService}
+}
@@ -0,0 +1,250 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package blogs
+
+import (
+    "cmp"
+    "context"
+    "database/sql"
+    "os"
+    "path/filepath"
+    "regexp"
+    "slices"
+    "strconv"
+    "strings"
+
+    "git.jelius.dev/jelius-sama/Portfolio/db"
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+    "github.com/gofiber/fiber/v3"
+    "github.com/jelius-sama/logger"
+)
+
+// langPattern accepts the BCP 47 tags we actually use ("en", "ja", "pt-br"),
+// which is also what keeps a language safe to put in a file name.
+var langPattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+// NormalizeLang lowercases a language tag and reports whether it is valid.
+func NormalizeLang(lang string) (string, bool) {
+    lang = strings.ToLower(strings.TrimSpace(lang))
+    return lang, langPattern.MatchString(lang)
+}
+
+// MarkdownName is what `OpenMarkdown` expects to read the post in lang.
+func MarkdownName(post *types.BlogResponse, lang string) string {
+    if len(lang) == 0 || lang == post.Lang {
+        return post.ID
+    }
+    return post.ID + "." + lang
+}
+
+// NegotiateLanguage picks the language to serve a post in from the
+// request's Accept-Language header, falling back to the post's own language.
+// A range that only differs in region from one we have still counts, so a
+// reader asking for "fr-CH" gets the "fr" variant.
+func NegotiateLanguage(c fiber.Ctx, post *types.BlogResponse) string {
+    var ranges = acceptedLanguages(c.Get(fiber.HeaderAcceptLanguage))
+    var langs = post.Languages()
+
+    for _, r := range ranges {
+        if r == "*" {
+            return post.Lang
+        }
+        if slices.Contains(langs, r) {
+            return r
+        }
+
+        var primary, _, _ = strings.Cut(r, "-")
+        for _, lang := range langs {
+            if p, _, _ := strings.Cut(lang, "-"); p == primary {
+                return lang
+            }
+        }
+    }
+    return post.Lang
+}
+
+// acceptedLanguages returns the language ranges of an Accept-Language header,
+// most preferred first, leaving out the ones with a quality of zero.
+func acceptedLanguages(header string) []string {
+    type weighted struct {
+        lang string
+        q    float64
+    }
+
+    var ranges []weighted
+    for part := range strings.SplitSeq(header, ",") {
+        var lang, params, _ = strings.Cut(part, ";")
+        lang = strings.ToLower(strings.TrimSpace(lang))
+        if len(lang) == 0 {
+            continue
+        }
+
+        var q = 1.0
+        if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+            if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+                q = parsed
+            }
+        }
+        if q > 0 {
+            ranges = append(ranges, weighted{lang, q})
+        }
+    }
+
+    slices.SortStableFunc(ranges, func(a, b weighted) int {
+        return cmp.Compare(b.q, a.q)
+    })
+
+    var langs = make([]string, len(ranges))
+    for i := range ranges {
+        langs[i] = ranges[i].lang
+    }
+    return langs
+}
+
+// ApplyTranslation swaps the title, excerpt and update time of post for
+// the ones of its lang variant. Returns false when there is no such variant.
+func ApplyTranslation(post *types.BlogResponse, lang string) bool {
+    if lang == post.Lang {
+        post.ContentLang = lang
+        return true
+    }
+
+    for _, t := range post.Translations {
+        if t.Lang == lang {
+            post.Title = t.Title
+            post.Excerpt = t.Excerpt
+            post.UpdatedAt = t.UpdatedAt
+            post.ContentLang = lang
+            return true
+        }
+    }
+    return false
+}
+
+func getTranslations(ctx context.Context, blogID string) ([]types.BlogTranslation, error) {
+    var rows, err = db.DB.QueryContext(ctx, `
+        SELECT lang, title, COALESCE(excerpt, ''), updated_at
+        FROM blog_translations
+        WHERE blog_id = ?
+        ORDER BY lang ASC
+    `, blogID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var translations []types.BlogTranslation
+    for rows.Next() {
+        var t types.BlogTranslation
+        if err := rows.Scan(&t.Lang, &t.Title, &t.Excerpt, &t.UpdatedAt); err != nil {
+            return nil, err
+        }
+        translations = append(translations, t)
+    }
+
+    return translations, rows.Err()
+}
+
+// CreateTranslation adds a language variant to a post, or replaces it when
+// the post already has one in that language.
+func CreateTranslation(c fiber.Ctx) error {
+    var id = c.Params("id")
+
+    var req types.CreateBlogTranslation
+    if err := c.Bind().Body(&req); err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
+            Code:    fiber.StatusBadRequest,
+            Message: "Invalid request body",
+        })
+    }
+
+    var lang, ok = NormalizeLang(req.Lang)
+    if !ok {
+        return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
+            Code:    fiber.StatusBadRequest,
+            Message: "A valid language is required",
+        })
+    }
+
+    var file, err = c.FormFile("markdown")
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(types.ErrorResp{
+            Code:    fiber.StatusBadRequest,
+            Message: "Markdown file is required",
+        })
+    }
+
+    var postLang string
+    if err := db.DB.QueryRow(`SELECT lang FROM blogs WHERE id = ? AND deleted_at IS NULL`, id).Scan(&postLang); err != nil {
+        if err == sql.ErrNoRows {
+            return c.Status(fiber.StatusNotFound).JSON(types.ErrorResp{
+                Code:    fiber.StatusNotFound,
+                Message: "Blog not found",
+            })
+        }
+        logger.Error(c.Path(), err.Error())
+        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+            Code:    fiber.StatusInternalServerError,
+            Message: "Internal Server Error",
+        })
+    }
+
+    if lang == postLang {
+        return c.Status(fiber.StatusConflict).JSON(types.ErrorResp{
+            Code:    fiber.StatusConflict,
+            Message: "The post is already written in this language",
+        })
+    }
+
+    var upload, lintErr = lintUpload(c, file, req.Title)
+    if lintErr != nil {
+        logger.Error(c.Path(), lintErr.Error())
+        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+            Code:    fiber.StatusInternalServerError,
+            Message: "Internal Server Error",
+        })
+    }
+    if upload.failed() {
+        return rejectUpload(c, upload)
+    }
+
+    var blogsDir = filepath.Join(types.EVDataDir.Get().Value, "blogs")
+    if err := os.MkdirAll(blogsDir, 0o755); err != nil {
+        logger.Error(c.Path(), err.Error())
+        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+            Code:    fiber.StatusInternalServerError,
+            Message: "Failed to create blog directory",
+        })
+    }
+
+    if err := os.WriteFile(filepath.Join(blogsDir, id+"."+lang+".md"), upload.src, 0o644); err != nil {
+        logger.Error(c.Path(), err.Error())
+        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+            Code:    fiber.StatusInternalServerError,
+            Message: "Failed to save markdown file",
+        })
+    }
+
+    var query = `
+        INSERT INTO blog_translations (blog_id, lang, title, excerpt, published_at, updated_at)
+        VALUES (?, ?, ?, ?, datetime('now'), datetime('now'))
+        ON CONFLICT (blog_id, lang) DO UPDATE SET
+            title = excluded.title,
+            excerpt = excluded.excerpt,
+            updated_at = excluded.updated_at
+    `
+
+    if _, err := db.DB.Exec(query, id, lang, upload.title, req.Excerpt); err != nil {
+        logger.Error(c.Path(), err.Error())
+        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+            Code:    fiber.StatusInternalServerError,
+            Message: "Failed to create translation",
+        })
+    }
+
+    return c.Status(fiber.StatusCreated).JSON(types.UploadResp{
+        ID:       id,
+        Warnings: upload.warnings(),
+    })
+}
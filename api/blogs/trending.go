@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package blogs
+
+import (
+    "strconv"
+    "time"
+
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+    "github.com/jelius-sama/logger"
+)
+
+const (
+    defaultTrendingWindow  = 7 * 24 * time.Hour
+    defaultTrendingGravity = 1.8
+)
+
+// trendingParams reads the sliding window and decay gravity used by the
+// `BSOTrending` sort order. Bad values fall back to the defaults instead of
+// failing the request, a typo in the environment shouldn't take /blogs down.
+func trendingParams() (float64, float64) {
+    var window = defaultTrendingWindow
+    if w, err := time.ParseDuration(types.EVTrendingWindow.Get().Value); err == nil && w > 0 {
+        window = w
+    } else if len(types.EVTrendingWindow.Get().Value) != 0 {
+        logger.Error("Invalid trending window, falling back to default:", types.EVTrendingWindow.Get().Value)
+    }
+
+    var gravity float64 = defaultTrendingGravity
+    if g, err := strconv.ParseFloat(types.EVTrendingGravity.Get().Value, 64); err == nil && g >= 0 {
+        gravity = g
+    } else if len(types.EVTrendingGravity.Get().Value) != 0 {
+        logger.Error("Invalid trending gravity, falling back to default:", types.EVTrendingGravity.Get().Value)
+    }
+
+    return window.Hours(), gravity
+}
+
+// trendingScoreExpr is a Hacker News style gravity score computed per view:
+// every view inside the window contributes 1 / (age_in_hours + 2)^gravity,
+// so a burst of recent views outranks a large pile of old ones and a post
+// falls off the list once its views age out of the window entirely. Views
+// are counted per hour, their age is that of the start of their hour.
+//
+// Expects two bound parameters, the window in hours followed by the gravity.
+const trendingScoreExpr = `
+    COALESCE(SUM(
+        CASE WHEN ae.hour >= datetime('now', '-' || ? || ' hours')
+        THEN ae.visits * 1.0 / pow(((julianday('now') - julianday(ae.hour)) * 24.0) + 2.0, ?)
+        ELSE 0 END
+    ), 0)
+`
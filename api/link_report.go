@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package api
+
+import (
+    "database/sql"
+
+    "git.jelius.dev/jelius-sama/Portfolio/db"
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+    "github.com/gofiber/fiber/v3"
+    "github.com/jelius-sama/logger"
+)
+
+// GetLinkReport lists every currently published link whose latest check came
+// back broken or redirected, along with every place it appears.
+func GetLinkReport(c fiber.Ctx) error {
+    var ctx = c.RequestCtx()
+
+    var query = `
+        SELECT r.url, r.state, r.status_code, r.final_url, r.error,
+            strftime('%Y-%m-%d %H:%M:%S', r.checked_at)
+        FROM link_check_results r
+        WHERE r.id = (SELECT MAX(id) FROM link_check_results WHERE url = r.url)
+            AND r.state != ?
+            AND r.url IN (SELECT url FROM link_occurrences)
+        ORDER BY r.url ASC
+    `
+
+    var rows, err = db.DB.QueryContext(ctx, query, types.LSOk)
+    if err != nil {
+        logger.Error(c.Path(), err.Error())
+        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+            Code:    fiber.StatusInternalServerError,
+            Message: "Internal Server Error",
+        })
+    }
+
+    var entries []types.LinkReportEntry
+    for rows.Next() {
+        var entry types.LinkReportEntry
+        var statusCode sql.NullInt64
+        var finalURL, errMsg sql.NullString
+
+        if err := rows.Scan(&entry.URL, &entry.State, &statusCode, &finalURL, &errMsg, &entry.CheckedAt); err != nil {
+            rows.Close()
+            logger.Error(c.Path(), err.Error())
+            return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+                Code:    fiber.StatusInternalServerError,
+                Message: "Internal Server Error",
+            })
+        }
+
+        entry.StatusCode = int(statusCode.Int64)
+        entry.FinalURL = finalURL.String
+        entry.Error = errMsg.String
+        entries = append(entries, entry)
+    }
+    rows.Close() // Close early, the occurrence lookups below need a connection of their own
+
+    var resp = types.LinkReportResponse{
+        Broken:     []types.LinkReportEntry{},
+        Redirected: []types.LinkReportEntry{},
+    }
+
+    for i := range entries {
+        var occRows, err = db.DB.QueryContext(ctx, `
+            SELECT source_kind, source_id, line
+            FROM link_occurrences
+            WHERE url = ?
+            ORDER BY source_kind, source_id, line
+        `, entries[i].URL)
+        if err != nil {
+            logger.Error(c.Path(), err.Error())
+            return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+                Code:    fiber.StatusInternalServerError,
+                Message: "Internal Server Error",
+            })
+        }
+
+        for occRows.Next() {
+            var occ types.LinkOccurrence
+            if err := occRows.Scan(&occ.SourceKind, &occ.SourceID, &occ.Line); err != nil {
+                occRows.Close()
+                logger.Error(c.Path(), err.Error())
+                return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+                    Code:    fiber.StatusInternalServerError,
+                    Message: "Internal Server Error",
+                })
+            }
+            entries[i].Occurrences = append(entries[i].Occurrences, occ)
+        }
+        occRows.Close()
+
+        switch entries[i].State {
+        case types.LSBroken:
+            resp.Broken = append(resp.Broken, entries[i])
+        case types.LSRedirected:
+            resp.Redirected = append(resp.Redirected, entries[i])
+        }
+    }
+
+    return c.Status(fiber.StatusOK).JSON(resp)
+}
@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package api
+
+import (
+    "errors"
+    "strings"
+
+    "git.jelius.dev/jelius-sama/Portfolio/newsletter"
+    "git.jelius.dev/jelius-sama/Portfolio/template/pages"
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+    "github.com/a-h/templ"
+    "github.com/gofiber/fiber/v3"
+    "github.com/jelius-sama/logger"
+)
+
+// SubscribeNewsletter handles the signup form on /blogs, answering with the
+// HTML to swap in for it.
+func SubscribeNewsletter(c fiber.Ctx) error {
+    var req types.NewsletterSignup
+    if err := c.Bind().Body(&req); err != nil {
+        return sendFragment(c, pages.NewsletterForm(pages.NewsletterFormArgs{Error: "Invalid request"}))
+    }
+
+    // Bots get the same answer as everyone else, just without the mail
+    if len(req.Website) != 0 {
+        return sendFragment(c, pages.NewsletterSubscribed())
+    }
+
+    if err := newsletter.Subscribe(c.RequestCtx(), req.Email); err != nil {
+        if errors.Is(err, newsletter.ErrInvalidEmail) {
+            return sendFragment(c, pages.NewsletterForm(pages.NewsletterFormArgs{
+                Email: req.Email,
+                Error: "That doesn't look like an email address.",
+            }))
+        }
+        logger.Error(c.Path(), err.Error())
+        return sendFragment(c, pages.NewsletterForm(pages.NewsletterFormArgs{
+            Email: req.Email,
+            Error: "Something went wrong, please try again later.",
+        }))
+    }
+
+    return sendFragment(c, pages.NewsletterSubscribed())
+}
+
+// UnsubscribeNewsletter is the one-click unsubscribe mail clients POST to
+// (RFC 8058), people following the link get a page instead.
+func UnsubscribeNewsletter(c fiber.Ctx) error {
+    if err := newsletter.Unsubscribe(c.RequestCtx(), c.Params("token")); err != nil {
+        if errors.Is(err, newsletter.ErrInvalidToken) {
+            return fiber.ErrNotFound
+        }
+        logger.Error(c.Path(), err.Error())
+        return fiber.ErrInternalServerError
+    }
+    return c.SendString("Unsubscribed")
+}
+
+// sendFragment answers an HTMX request with component. Errors still come
+// back as 200, HTMX doesn't swap anything else by default.
+func sendFragment(c fiber.Ctx, component templ.Component) error {
+    var buf strings.Builder
+    if err := component.Render(c.RequestCtx(), &buf); err != nil {
+        logger.Error(c.Path(), err.Error())
+        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+            Code:    fiber.StatusInternalServerError,
+            Message: "Internal Server Error",
+        })
+    }
+
+    c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+    return c.SendString(buf.String())
+}
@@ -26,7 +26,7 @@ func GenerateRobots(c fiber.Ctx) error {
             {
                 UserAgent: "*",
                 Allow:     []string{"/"},
-                Disallow:  []string{"/analytics", "/music"},
+                Disallow:  []string{"/analytics", "/music", "/newsletter/"},
             },
         },
         Host: types.EVHostname.Get().Value,
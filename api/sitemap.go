@@ -31,10 +31,67 @@ func GenerateSitemap(c fiber.Ctx) error {
         {Loc: host.JoinPath("/links").String(), LastMod: now, ChangeFreq: "monthly", Priority: "0.6"},
     }
 
+    // Author pages, as long as there is something on them
+    if rows, err := db.DB.Query(`
+        SELECT a.handle, date(MAX(b.updated_at))
+        FROM authors a
+        JOIN blog_authors ba ON ba.author_id = a.id
+        JOIN blogs b ON b.id = ba.blog_id AND b.deleted_at IS NULL AND b.draft = 0
+        GROUP BY a.id
+        ORDER BY a.handle
+    `); err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+            Code:    fiber.StatusInternalServerError,
+            Message: "Internal Server Error",
+        })
+    } else {
+        defer rows.Close()
+
+        for rows.Next() {
+            var author types.Author
+            var updatedAt string
+            if err := rows.Scan(&author.Handle, &updatedAt); err != nil {
+                continue // skip malformed rows
+            }
+
+            urls = append(urls, types.SiteMapURLEntry{
+                Loc:        host.JoinPath(author.Path()).String(),
+                LastMod:    updatedAt,
+                ChangeFreq: "weekly",
+                Priority:   "0.5",
+            })
+        }
+    }
+
+    // Language variants, keyed by blog ID
+    var translations = make(map[string][]types.BlogTranslation)
+    if rows, err := db.DB.Query(`
+        SELECT blog_id, lang, updated_at
+        FROM blog_translations
+        ORDER BY blog_id, lang
+    `); err != nil {
+        return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
+            Code:    fiber.StatusInternalServerError,
+            Message: "Internal Server Error",
+        })
+    } else {
+        defer rows.Close()
+
+        for rows.Next() {
+            var id string
+            var t types.BlogTranslation
+            if err := rows.Scan(&id, &t.Lang, &t.UpdatedAt); err != nil {
+                continue // skip malformed rows
+            }
+            translations[id] = append(translations[id], t)
+        }
+    }
+
     // Fetch all blogs directly
     if rows, err := db.DB.Query(`
-        SELECT id, updated_at
+        SELECT id, lang, updated_at
         FROM blogs
+        WHERE draft = 0
         ORDER BY updated_at DESC
     `); err != nil {
         return c.Status(fiber.StatusInternalServerError).JSON(types.ErrorResp{
@@ -45,8 +102,9 @@ func GenerateSitemap(c fiber.Ctx) error {
         defer rows.Close()
 
         for rows.Next() {
-            var id, updatedAt string
-            if err := rows.Scan(&id, &updatedAt); err != nil {
+            var post types.BlogResponse
+            var updatedAt string
+            if err := rows.Scan(&post.ID, &post.Lang, &updatedAt); err != nil {
                 continue // skip malformed rows
             }
 
@@ -56,18 +114,49 @@ func GenerateSitemap(c fiber.Ctx) error {
                 updatedAt = t.Format("2006-01-02")
             }
 
+            post.Translations = translations[post.ID]
+
+            // Every variant lists all of them, itself included, as Google asks
+            var alternates []types.SiteMapAlternate
+            if len(post.Translations) != 0 {
+                for _, lang := range post.Languages() {
+                    alternates = append(alternates, types.SiteMapAlternate{
+                        Rel:      "alternate",
+                        Hreflang: lang,
+                        Href:     host.JoinPath(post.LocalizedPath(lang)).String(),
+                    })
+                }
+                alternates = append(alternates, types.SiteMapAlternate{
+                    Rel:      "alternate",
+                    Hreflang: "x-default",
+                    Href:     host.JoinPath(post.LocalizedPath(post.Lang)).String(),
+                })
+            }
+
             urls = append(urls, types.SiteMapURLEntry{
-                Loc:        host.JoinPath("blog", id).String(),
+                Loc:        host.JoinPath(post.LocalizedPath(post.Lang)).String(),
                 LastMod:    updatedAt,
                 ChangeFreq: "monthly",
                 Priority:   "0.7",
+                Alternates: alternates,
             })
+
+            for _, t := range post.Translations {
+                urls = append(urls, types.SiteMapURLEntry{
+                    Loc:        host.JoinPath(post.LocalizedPath(t.Lang)).String(),
+                    LastMod:    t.UpdatedAt.Format("2006-01-02"),
+                    ChangeFreq: "monthly",
+                    Priority:   "0.7",
+                    Alternates: alternates,
+                })
+            }
         }
     }
 
     return c.XML(types.SiteMapURLSet{
-        Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9",
-        URLs:  urls,
+        Xmlns:      "http://www.sitemaps.org/schemas/sitemap/0.9",
+        XmlnsXhtml: "http://www.w3.org/1999/xhtml",
+        URLs:       urls,
     })
 }
 
@@ -42,7 +42,9 @@ type entry struct {
     body        []byte
     contentType string
     status      int
-    expiresAt   time.Time
+    // locals are the kept locals the handler set, see KeepLocals
+    locals    map[string]any
+    expiresAt time.Time
 }
 
 // call tracks a single in-flight render for a given cache key, so that
@@ -63,6 +65,8 @@ type Store struct {
     inflight   sync.Map // key(string) -> *call
     ttl        time.Duration
     headerKeys []string // request headers that must be part of the cache key
+    bypass     []string // query parameters that skip the cache entirely
+    keep       []string // locals stored with a response and restored on hits
 }
 
 // New creates a Store with the given TTL. headerKeys should list every
@@ -81,6 +85,23 @@ func New(ttl time.Duration, headerKeys ...string) *Store {
     return s
 }
 
+// BypassQuery makes requests carrying any of the given query parameters skip
+// the cache in both directions: they are never answered from it and their
+// responses are never stored. Meant for parameters like credentials, which
+// must not end up in a cache key, let alone be served to someone else.
+func (s *Store) BypassQuery(params ...string) *Store {
+    s.bypass = append(s.bypass, params...)
+    return s
+}
+
+// KeepLocals stores the given locals along with every response and sets
+// them again when the response is served from the cache, so middleware in
+// front of the cache sees what the handler left for it on hits too.
+func (s *Store) KeepLocals(keys ...string) *Store {
+    s.keep = append(s.keep, keys...)
+    return s
+}
+
 // janitor periodically evicts expired entries so the map doesn't grow
 // forever.
 func (s *Store) janitor() {
@@ -138,6 +159,9 @@ func (s *Store) set(key string, e *entry) {
 // Cloudflare or the browser and reintroduce the original bug at a
 // different layer.
 func writeEntry(c fiber.Ctx, e *entry, cacheStatus string) error {
+    for k, v := range e.locals {
+        c.Locals(k, v)
+    }
     c.Set(fiber.HeaderContentType, e.contentType)
     c.Set(fiber.HeaderCacheControl, "no-store")
     c.Set("X-Cache", cacheStatus)
@@ -152,6 +176,11 @@ func (s *Store) Middleware() fiber.Handler {
         if c.Method() != fiber.MethodGet {
             return c.Next()
         }
+        for _, param := range s.bypass {
+            if len(c.Query(param)) != 0 {
+                return c.Next()
+            }
+        }
 
         key := s.key(c)
 
@@ -195,6 +224,14 @@ func (s *Store) Middleware() fiber.Handler {
             status:      resp.StatusCode(),
             expiresAt:   time.Now().Add(s.ttl),
         }
+        for _, k := range s.keep {
+            if v := c.Locals(k); v != nil {
+                if e.locals == nil {
+                    e.locals = make(map[string]any)
+                }
+                e.locals[k] = v
+            }
+        }
         cl.val = e
 
         // Don't cache error responses — a transient 500 shouldn't get
@@ -4,13 +4,21 @@
 package main
 
 import (
+    "context"
     "net/url"
     "os"
+    "os/signal"
     "path/filepath"
+    "strconv"
     "strings"
+    "syscall"
+    "time"
 
+    "git.jelius.dev/jelius-sama/Portfolio/api/analytics"
     "git.jelius.dev/jelius-sama/Portfolio/db"
+    "git.jelius.dev/jelius-sama/Portfolio/linkcheck"
     "git.jelius.dev/jelius-sama/Portfolio/middleware"
+    "git.jelius.dev/jelius-sama/Portfolio/newsletter"
     "git.jelius.dev/jelius-sama/Portfolio/types"
     "github.com/gofiber/fiber/v3"
     "github.com/jelius-sama/logger"
@@ -65,6 +73,10 @@ func init() {
         types.Env{Key: types.EVVersion.Get().Key, Value: Version},
         types.Env{Key: types.EVPort.Get().Key, Value: Port},
         types.Env{Key: types.EVDataDir.Get().Key, Value: dataDir},
+        types.Env{Key: types.EVTrendingWindow.Get().Key, Value: "168h"},
+        types.Env{Key: types.EVTrendingGravity.Get().Key, Value: "1.8"},
+        types.Env{Key: types.EVLinkCheckInterval.Get().Key, Value: "24h"},
+        types.Env{Key: types.EVSMTPPort.Get().Key, Value: "587"},
     )
 
     types.InitEnv(env)
@@ -103,9 +115,77 @@ func main() {
         ErrorHandler: middleware.ErrHandler,
     }
 
+    // Behind a reverse proxy the client address is only known from the
+    // header it sets, which is trusted from local and private addresses.
+    if header := types.EVProxyHeader.Get().Value; len(header) != 0 {
+        cnf.ProxyHeader = header
+        cnf.TrustProxy = true
+        cnf.TrustProxyConfig = fiber.TrustProxyConfig{Loopback: true, Private: true}
+    }
+
     var app *fiber.App = fiber.New(cnf)
     Router(app)
 
-    logger.Fatal(app.Listen(types.EVPort.Get().Value))
+    if interval, err := time.ParseDuration(types.EVLinkCheckInterval.Get().Value); err != nil || interval <= 0 {
+        logger.Error("Invalid link check interval, link checker disabled:", types.EVLinkCheckInterval.Get().Value)
+    } else {
+        linkcheck.New(linkcheck.Config{
+            Concurrency: 4,
+            HostDelay:   2 * time.Second,
+            Retries:     2,
+            Timeout:     15 * time.Second,
+            Interval:    interval,
+        }).Start()
+    }
+
+    // Raw analytics events are kept forever unless a retention is set
+    var retention int
+    if days := types.EVAnalyticsRetention.Get().Value; len(days) != 0 {
+        if n, err := strconv.Atoi(days); err != nil || n < 1 {
+            logger.Error("Invalid analytics retention, keeping raw events forever:", days)
+        } else {
+            retention = n
+        }
+    }
+    analytics.NewRollup(analytics.RollupConfig{
+        Interval:  time.Hour,
+        Retention: retention,
+    }).Start()
+
+    if len(types.EVSMTPHost.Get().Value) == 0 {
+        logger.Info("No SMTP relay configured, newsletter mails stay queued")
+    } else if mailer, err := newsletter.New(newsletter.Config{
+        Host:         types.EVSMTPHost.Get().Value,
+        Port:         types.EVSMTPPort.Get().Value,
+        Username:     types.EVSMTPUsername.Get().Value,
+        Password:     types.EVSMTPPassword.Get().Value,
+        From:         types.EVSMTPFrom.Get().Value,
+        Retries:      5,
+        RetryDelay:   time.Minute,
+        Timeout:      30 * time.Second,
+        PollInterval: time.Minute,
+    }); err != nil {
+        logger.Error("Newsletter delivery disabled:", err.Error())
+    } else {
+        mailer.Start()
+    }
+
+    // On SIGINT or SIGTERM the server stops taking connections and waits for
+    // the open requests. Live streams never finish, they are ended first.
+    var ctx, stop = signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stop()
+
+    app.Hooks().OnPreShutdown(func() error {
+        logger.Info("Shutting down")
+        analytics.CloseLiveStreams()
+        return nil
+    })
+
+    if err := app.Listen(types.EVPort.Get().Value, fiber.ListenConfig{
+        GracefulContext: ctx,
+        ShutdownTimeout: 10 * time.Second,
+    }); err != nil {
+        logger.Fatal(err)
+    }
 }
 
@@ -22,13 +22,17 @@ import (
 
 type RouterCtx struct {
     UI                 *renderer.ViewManager
+    Blogs              *blogs.BlogService
+    Analytics          *analytics.AnalyticsService
     MiddlewareHandlers types.MiddlewareHandlerMap
 }
 
 var routerCtx RouterCtx = RouterCtx{}
 
 func init() {
-    routerCtx.UI = renderer.New()
+    routerCtx.Analytics = analytics.NewService()
+    routerCtx.Blogs = blogs.NewService(routerCtx.Analytics)
+    routerCtx.UI = renderer.New(routerCtx.Blogs, routerCtx.Analytics)
     routerCtx.MiddlewareHandlers = make(types.MiddlewareHandlerMap)
     routerCtx.MiddlewareHandlers[types.MHNoCache] = middleware.NewCacheControl(middleware.CacheConfig{
         CustomHeader: "no-store, no-cache, max-age=0, must-revalidate, proxy-revalidate",
@@ -44,19 +48,30 @@ func init() {
         MaxAge:         31536000 * time.Second, // 1 year
         MustRevalidate: true,
     })
+    // Exports are rebuilt when a post changes, so caches have to check back
+    routerCtx.MiddlewareHandlers[types.MHDownloads] = middleware.NewCacheControl(middleware.CacheConfig{
+        Public:         true,
+        MaxAge:         0,
+        MustRevalidate: true,
+    })
+    // Draft previews must never end up in the shared cache
     routerCtx.MiddlewareHandlers[types.MHHTMXCache] = cache.New(
         5*time.Minute,
-        "HX-Request", "HX-Target", "HX-Current-URL", "HX-Boosted",
-    ).Middleware()
+        "HX-Request", "HX-Target", "HX-Current-URL", "HX-Boosted", "Accept-Language",
+    ).BypassQuery("preview").KeepLocals("pageview_path").Middleware()
+    routerCtx.MiddlewareHandlers[types.MHAdmin] = middleware.NewAdminAuth()
+    routerCtx.MiddlewareHandlers[types.MHPageviews] = analytics.ServerPageviews()
 
     types.Pages = map[string]types.Page{
-        "/":             types.Page{Handler: routerCtx.MiddlewareHandlers[types.MHHTMXCache], Handlers: []any{routerCtx.UI.RenderHome}},
-        "/links":        types.Page{Handler: routerCtx.MiddlewareHandlers[types.MHHTMXCache], Handlers: []any{routerCtx.UI.RenderLinks}},
-        "/blogs":        types.Page{Handler: routerCtx.MiddlewareHandlers[types.MHHTMXCache], Handlers: []any{routerCtx.UI.RenderBlogs}},
-        "/robots.txt":   types.Page{Handler: routerCtx.MiddlewareHandlers[types.MHStaticPages], Handlers: []any{api.GenerateRobots}},
-        "/sitemap.xml":  types.Page{Handler: routerCtx.MiddlewareHandlers[types.MHStaticPages], Handlers: []any{api.GenerateSitemap}},
-        "/blog/:id":     types.Page{Handler: routerCtx.MiddlewareHandlers[types.MHHTMXCache], Handlers: []any{routerCtx.UI.RenderBlog}},
-        "/achievements": types.Page{Handler: routerCtx.MiddlewareHandlers[types.MHHTMXCache], Handlers: []any{routerCtx.UI.RenderAchievements}},
+        "/":                types.Page{Handler: routerCtx.MiddlewareHandlers[types.MHHTMXCache], Handlers: []any{routerCtx.UI.RenderHome}, Tracking: types.TMServer},
+        "/links":           types.Page{Handler: routerCtx.MiddlewareHandlers[types.MHHTMXCache], Handlers: []any{routerCtx.UI.RenderLinks}, Tracking: types.TMServer},
+        "/blogs":           types.Page{Handler: routerCtx.MiddlewareHandlers[types.MHHTMXCache], Handlers: []any{routerCtx.UI.RenderBlogs}, Tracking: types.TMServer},
+        "/robots.txt":      types.Page{Handler: routerCtx.MiddlewareHandlers[types.MHStaticPages], Handlers: []any{api.GenerateRobots}},
+        "/sitemap.xml":     types.Page{Handler: routerCtx.MiddlewareHandlers[types.MHStaticPages], Handlers: []any{api.GenerateSitemap}},
+        "/blog/:id":        types.Page{Handler: routerCtx.MiddlewareHandlers[types.MHHTMXCache], Handlers: []any{routerCtx.UI.RenderBlog}, Tracking: types.TMServer},
+        "/:lang/blog/:id":  types.Page{Handler: routerCtx.MiddlewareHandlers[types.MHHTMXCache], Handlers: []any{routerCtx.UI.RenderBlog}, Tracking: types.TMServer},
+        "/authors/:handle": types.Page{Handler: routerCtx.MiddlewareHandlers[types.MHHTMXCache], Handlers: []any{routerCtx.UI.RenderAuthor}, Tracking: types.TMServer},
+        "/achievements":    types.Page{Handler: routerCtx.MiddlewareHandlers[types.MHHTMXCache], Handlers: []any{routerCtx.UI.RenderAchievements}, Tracking: types.TMServer},
     }
 }
 
@@ -68,21 +83,43 @@ func Router(app *fiber.App) {
 
     apiHandle.Get("/healthz", api.Healthz)
     apiHandle.Get("/version", api.Version)
+    apiHandle.Get("/links/report", api.GetLinkReport)
 
     // Analytics endpoints
     apiHandle.Get("/analytics/get/all", analytics.GetAllAnalyticsEvents)
-    apiHandle.Get("/analytics/get/visit-count", func(c fiber.Ctx) error { return analytics.GetPageVisitCount(c) })
+    apiHandle.Get("/analytics/get/visit-count", routerCtx.Analytics.GetPageVisitCount)
     apiHandle.Get("/analytics/get/avg-visits", analytics.GetAvgVisitsPerHour)
     apiHandle.Get("/analytics/get/top-countries", analytics.GetTopCountries)
     apiHandle.Get("/analytics/get/top-pages", analytics.GetTopPages)
+    apiHandle.Get("/analytics/get/top-referrers", analytics.GetTopReferrers)
+    apiHandle.Get("/analytics/get/top-campaigns", analytics.GetTopCampaigns)
+    apiHandle.Get("/analytics/get/top-browsers", analytics.GetTopBrowsers)
+    apiHandle.Get("/analytics/get/top-os", analytics.GetTopOS)
+    apiHandle.Get("/analytics/get/devices", analytics.GetDevices)
+    apiHandle.Get("/analytics/get/timeseries", analytics.GetTimeseries)
+    apiHandle.Get("/analytics/get/events", analytics.GetCustomEvents)
+    apiHandle.Get("/analytics/live", routerCtx.MiddlewareHandlers[types.MHAdmin], analytics.StreamLiveVisitors)
+    apiHandle.Get("/analytics/export", routerCtx.MiddlewareHandlers[types.MHAdmin], analytics.ExportAnalyticsEvents)
     apiHandle.Post("/analytics/track", analytics.TrackAnalytics)
 
-    apiHandle.Get("/blogs", blogs.GetBlogsPage)
+    apiHandle.Get("/blogs", routerCtx.Blogs.GetBlogsPage)
+
+    apiHandle.Get("/blog/all", routerCtx.Blogs.GetAllBlogs)
+    apiHandle.Get("/blog/md/:id", routerCtx.Blogs.GetBlogMarkdown)
+    apiHandle.Get("/blog/:id", routerCtx.Blogs.GetBlog)
+    apiHandle.Post("/blog", routerCtx.MiddlewareHandlers[types.MHAdmin], blogs.CreateBlog)
+    apiHandle.Post("/blog/:id/translations", routerCtx.MiddlewareHandlers[types.MHAdmin], blogs.CreateTranslation)
+    apiHandle.Post("/blog/:id/publish", routerCtx.MiddlewareHandlers[types.MHAdmin], blogs.PublishBlog)
+    apiHandle.Post("/blog/:id/preview", routerCtx.MiddlewareHandlers[types.MHAdmin], blogs.CreatePreview)
+    apiHandle.Delete("/blog/:id/preview", routerCtx.MiddlewareHandlers[types.MHAdmin], blogs.RevokePreviews)
+    apiHandle.Post("/blog/:id/pin", routerCtx.MiddlewareHandlers[types.MHAdmin], blogs.PinBlog)
+    apiHandle.Delete("/blog/:id/pin", routerCtx.MiddlewareHandlers[types.MHAdmin], blogs.UnpinBlog)
+    apiHandle.Post("/blog/:id/feature", routerCtx.MiddlewareHandlers[types.MHAdmin], blogs.FeatureBlog)
+    apiHandle.Delete("/blog/:id/feature", routerCtx.MiddlewareHandlers[types.MHAdmin], blogs.UnfeatureBlog)
+
+    apiHandle.Post("/authors", routerCtx.MiddlewareHandlers[types.MHAdmin], blogs.CreateAuthor)
 
-    apiHandle.Get("/blog/all", func(c fiber.Ctx) error { return blogs.GetAllBlogs(c) })
-    apiHandle.Get("/blog/md/:id", func(c fiber.Ctx) error { return blogs.GetBlogMarkdown(c) })
-    apiHandle.Get("/blog/:id", func(c fiber.Ctx) error { return blogs.GetBlog(c) })
-    apiHandle.Post("/blog", blogs.CreateBlog)
+    apiHandle.Post("/newsletter/subscribe", api.SubscribeNewsletter)
 
     if types.EVEnv.Get().Value == types.EMProd.String() {
         var assetDir = filepath.Join(types.EVDataDir.Get().Value, "assets")
@@ -101,7 +138,28 @@ func Router(app *fiber.App) {
         }
     }
 
+    // Registered ahead of the pages, `/blog/:id` would match these too
+    app.Get("/blog/:id.pdf", routerCtx.MiddlewareHandlers[types.MHDownloads], routerCtx.Blogs.GetBlogPDF)
+    app.Get("/series/:id.pdf", routerCtx.MiddlewareHandlers[types.MHDownloads], routerCtx.Blogs.GetSeriesPDF)
+    app.Get("/series/:id.epub", routerCtx.MiddlewareHandlers[types.MHDownloads], routerCtx.Blogs.GetSeriesEPUB)
+
+    // Links from newsletter mails, they change state so they are kept out
+    // of the pages and their caches
+    app.Get("/newsletter/confirm/:token", routerCtx.MiddlewareHandlers[types.MHNoCache], routerCtx.UI.RenderNewsletterConfirm)
+    app.Get("/newsletter/unsubscribe/:token", routerCtx.MiddlewareHandlers[types.MHNoCache], routerCtx.UI.RenderNewsletterUnsubscribe)
+    app.Post("/newsletter/unsubscribe/:token", routerCtx.MiddlewareHandlers[types.MHNoCache], api.UnsubscribeNewsletter)
+
+    // The analytics dashboard, signed in to with ADMIN_TOKEN
+    app.Get("/analytics", routerCtx.MiddlewareHandlers[types.MHNoCache], routerCtx.UI.RenderAnalytics)
+    app.Post("/analytics/login", routerCtx.MiddlewareHandlers[types.MHNoCache], routerCtx.UI.AnalyticsLogin)
+    app.Post("/analytics/logout", routerCtx.MiddlewareHandlers[types.MHNoCache], routerCtx.UI.AnalyticsLogout)
+
     for k, v := range types.Pages {
+        if v.Tracking == types.TMServer {
+            // Ahead of the page's cache, which would skip it on hits
+            app.Get(k, routerCtx.MiddlewareHandlers[types.MHPageviews], append([]any{v.Handler}, v.Handlers...)...)
+            continue
+        }
         app.Get(k, v.Handler, v.Handlers...)
     }
 
@@ -21,6 +21,229 @@ func createAnalyticsTables() error {
         return err
     }
 
+    // Anonymous visitor and session IDs, NULL for events recorded before them
+    if err := addColumn("analytics_events", "visitor_id", "TEXT"); err != nil {
+        return err
+    }
+    if err := addColumn("analytics_events", "session_id", "TEXT"); err != nil {
+        return err
+    }
+    // Where the visit came from, recorded for the page it landed on only
+    for _, column := range []string{"referrer", "utm_source", "utm_medium", "utm_campaign", "utm_term", "utm_content"} {
+        if err := addColumn("analytics_events", column, "TEXT"); err != nil {
+            return err
+        }
+    }
+    if err := addColumn("analytics_events", "referrer_kind", "INTEGER"); err != nil {
+        return err
+    }
+
+    // What the visit was made with, parsed from the User-Agent which itself
+    // isn't kept
+    for _, column := range [][2]string{
+        {"browser", "TEXT"}, {"browser_version", "INTEGER"}, {"os", "TEXT"}, {"device", "INTEGER"},
+    } {
+        if err := addColumn("analytics_events", column[0], column[1]); err != nil {
+            return err
+        }
+    }
+
+    // Whether the tracking script or the server recorded the pageview
+    if err := addColumn("analytics_events", "source", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+        return err
+    }
+
+    if err := addEngagementColumns("analytics_events"); err != nil {
+        return err
+    }
+    if err := addPageviewID("analytics_events", "idx_pageview_id"); err != nil {
+        return err
+    }
+
+    if _, err := DB.Exec(`CREATE INDEX IF NOT EXISTS idx_visitor_id ON analytics_events(visitor_id)`); err != nil {
+        return err
+    }
+
+    if _, err := DB.Exec(`CREATE INDEX IF NOT EXISTS idx_session_id ON analytics_events(session_id)`); err != nil {
+        return err
+    }
+
+    if err := createBotEventsTable(); err != nil {
+        return err
+    }
+
+    if err := createCustomEventsTable(); err != nil {
+        return err
+    }
+
+    return createRollupTables()
+}
+
+// createBotEventsTable creates the table bot hits are kept in for auditing,
+// out of the way of the reports. It has every column of analytics_events so
+// reports can include the two together, plus why the hit was taken for a bot.
+func createBotEventsTable() error {
+    var schema = `
+    CREATE TABLE IF NOT EXISTS bot_events (
+        event_id INTEGER PRIMARY KEY AUTOINCREMENT,
+        reason INTEGER NOT NULL,
+        detail TEXT,
+        country_code TEXT NOT NULL,
+        page_path TEXT NOT NULL,
+        timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
+        visitor_id TEXT,
+        session_id TEXT,
+        referrer TEXT,
+        referrer_kind INTEGER,
+        utm_source TEXT,
+        utm_medium TEXT,
+        utm_campaign TEXT,
+        utm_term TEXT,
+        utm_content TEXT,
+        browser TEXT,
+        browser_version INTEGER,
+        os TEXT,
+        device INTEGER,
+        source INTEGER NOT NULL DEFAULT 0
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_bot_events_timestamp ON bot_events(timestamp);
+    CREATE INDEX IF NOT EXISTS idx_bot_events_visitor_id ON bot_events(visitor_id);
+    `
+
+    if _, err := DB.Exec(schema); err != nil {
+        return err
+    }
+
+    if err := addColumn("bot_events", "source", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+        return err
+    }
+    if err := addEngagementColumns("bot_events"); err != nil {
+        return err
+    }
+    if err := addPageviewID("bot_events", "idx_bot_events_pageview_id"); err != nil {
+        return err
+    }
+
+    if _, err := DB.Exec(`CREATE INDEX IF NOT EXISTS idx_bot_events_session_id ON bot_events(session_id)`); err != nil {
+        return err
+    }
+
+    return nil
+}
+
+// createCustomEventsTable creates the table of the custom events the
+// tracking script sends, such as clicks on outbound links. properties is a
+// JSON object of the event's properties, checked against its schema before
+// it is stored.
+func createCustomEventsTable() error {
+    var schema = `
+    CREATE TABLE IF NOT EXISTS analytics_custom_events (
+        event_id INTEGER PRIMARY KEY AUTOINCREMENT,
+        name TEXT NOT NULL,
+        properties TEXT NOT NULL DEFAULT '{}',
+        country_code TEXT NOT NULL,
+        page_path TEXT NOT NULL,
+        visitor_id TEXT NOT NULL,
+        session_id TEXT,
+        timestamp DATETIME DEFAULT CURRENT_TIMESTAMP
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_custom_events_name ON analytics_custom_events(name, timestamp);
+    CREATE INDEX IF NOT EXISTS idx_custom_events_timestamp ON analytics_custom_events(timestamp);
+    `
+
+    if _, err := DB.Exec(schema); err != nil {
+        return err
+    }
+
+    return nil
+}
+
+// addEngagementColumns adds how long a pageview was looked at, in seconds,
+// and how far down it was scrolled, in percent of the page. They stay NULL
+// until the tracking script reports them, without JavaScript they never do.
+func addEngagementColumns(table string) error {
+    if err := addColumn(table, "engaged_seconds", "INTEGER"); err != nil {
+        return err
+    }
+    return addColumn(table, "max_scroll", "INTEGER")
+}
+
+// addPageviewID adds the ID the tracking script is handed for a pageview to
+// report on it with, NULL for pageviews recorded before it.
+func addPageviewID(table, index string) error {
+    if err := addColumn(table, "pageview_id", "TEXT"); err != nil {
+        return err
+    }
+    if _, err := DB.Exec(`CREATE INDEX IF NOT EXISTS ` + index + ` ON ` + table + `(pageview_id)`); err != nil {
+        return err
+    }
+    return nil
+}
+
+// createRollupTables creates the tables the raw events are summed up into
+// once their day is over. Every row is there twice, bots set to 0 for people
+// alone and to 1 for people and bots together, as unique visitors and
+// sessions can't be added up from the two.
+//
+// analytics_rollup_state holds how far the rollups reach: days before
+// rolled_until are in them, later ones are only in the raw events.
+func createRollupTables() error {
+    var schema = `
+    CREATE TABLE IF NOT EXISTS analytics_hourly (
+        hour DATETIME NOT NULL,
+        page_path TEXT NOT NULL,
+        country_code TEXT NOT NULL,
+        bots INTEGER NOT NULL,
+        visits INTEGER NOT NULL,
+        PRIMARY KEY (hour, page_path, country_code, bots)
+    );
+
+    CREATE TABLE IF NOT EXISTS analytics_daily_pages (
+        day DATE NOT NULL,
+        page_path TEXT NOT NULL,
+        bots INTEGER NOT NULL,
+        visits INTEGER NOT NULL,
+        visitors INTEGER NOT NULL,
+        sessions INTEGER NOT NULL,
+        PRIMARY KEY (day, page_path, bots)
+    );
+
+    CREATE TABLE IF NOT EXISTS analytics_daily_countries (
+        day DATE NOT NULL,
+        country_code TEXT NOT NULL,
+        bots INTEGER NOT NULL,
+        visits INTEGER NOT NULL,
+        visitors INTEGER NOT NULL,
+        sessions INTEGER NOT NULL,
+        PRIMARY KEY (day, country_code, bots)
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_analytics_hourly_page_path ON analytics_hourly(page_path, bots);
+    CREATE INDEX IF NOT EXISTS idx_analytics_daily_pages_page_path ON analytics_daily_pages(page_path, bots);
+
+    CREATE TABLE IF NOT EXISTS analytics_rollup_state (
+        id INTEGER PRIMARY KEY CHECK (id = 1),
+        rolled_until DATE NOT NULL
+    );
+
+    INSERT OR IGNORE INTO analytics_rollup_state (id, rolled_until) VALUES (1, '1970-01-01');
+    `
+
+    if _, err := DB.Exec(schema); err != nil {
+        return err
+    }
+
+    // The engagement of the day's pageviews: how many reported any, and the
+    // sums of their engaged seconds and scroll depths and how many of them
+    // were read to the end. Days rolled up before these were added have none.
+    for _, column := range []string{"engaged_views", "engaged_seconds", "scroll_total", "read_views"} {
+        if err := addColumn("analytics_daily_pages", column, "INTEGER NOT NULL DEFAULT 0"); err != nil {
+            return err
+        }
+    }
+
     return nil
 }
 
@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package db
+
+// createAuthorsTables creates the authors and which posts they are credited
+// on. Position 0 is the author of a post, everyone after a co-author.
+func createAuthorsTables() error {
+    var schema = `
+    CREATE TABLE IF NOT EXISTS authors (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        handle TEXT NOT NULL UNIQUE COLLATE NOCASE,
+        name TEXT NOT NULL,
+        bio TEXT,
+        avatar_path TEXT,
+        twitter TEXT,
+        github TEXT,
+        mastodon TEXT,
+        website TEXT,
+        created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+        updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+    );
+
+    CREATE TABLE IF NOT EXISTS blog_authors (
+        blog_id TEXT NOT NULL,
+        author_id INTEGER NOT NULL,
+        position INTEGER NOT NULL,
+        PRIMARY KEY (blog_id, author_id),
+        FOREIGN KEY (blog_id) REFERENCES blogs(id) ON DELETE CASCADE,
+        FOREIGN KEY (author_id) REFERENCES authors(id) ON DELETE CASCADE
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_blog_authors_author_id ON blog_authors(author_id);
+    `
+
+    if _, err := DB.Exec(schema); err != nil {
+        return err
+    }
+
+    return nil
+}
+
@@ -10,6 +10,7 @@ func createBlogsTable() error {
         id TEXT PRIMARY KEY,
         title TEXT NOT NULL,
         excerpt TEXT,
+        lang TEXT NOT NULL DEFAULT 'en',
         published_at DATETIME DEFAULT CURRENT_TIMESTAMP,
         updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
         deleted_at DATETIME,
@@ -30,6 +31,50 @@ func createBlogsTable() error {
         return err
     }
 
+    // Databases created before posts had a language
+    if err := addColumn("blogs", "lang", "TEXT NOT NULL DEFAULT 'en'"); err != nil {
+        return err
+    }
+
+    // Drafts, and the counter that revokes their preview links when bumped
+    if err := addColumn("blogs", "draft", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+        return err
+    }
+    if err := addColumn("blogs", "preview_generation", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+        return err
+    }
+
+    // Pinned posts head the blog list, featured ones show on the home page
+    if err := addColumn("blogs", "pinned", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+        return err
+    }
+    if err := addColumn("blogs", "featured", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+        return err
+    }
+
+    return createBlogTranslationsTable()
+}
+
+// createBlogTranslationsTable creates the table holding the language variants
+// of a post, the markdown itself is stored as `blogs/<id>.<lang>.md`
+func createBlogTranslationsTable() error {
+    var schema = `
+    CREATE TABLE IF NOT EXISTS blog_translations (
+        blog_id TEXT NOT NULL,
+        lang TEXT NOT NULL,
+        title TEXT NOT NULL,
+        excerpt TEXT,
+        published_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+        updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (blog_id, lang),
+        FOREIGN KEY (blog_id) REFERENCES blogs(id) ON DELETE CASCADE
+    );
+    `
+
+    if _, err := DB.Exec(schema); err != nil {
+        return err
+    }
+
     return nil
 }
 
@@ -21,9 +21,12 @@ func createTables() []error {
     var errors []error
     errors = append(errors, createAnalyticsTables())
     errors = append(errors, createBlogsTable())
+    errors = append(errors, createAuthorsTables())
     errors = append(errors, createLinksTable())
     errors = append(errors, createHomeTables())
     errors = append(errors, createMetadataTable())
+    errors = append(errors, createLinkCheckTables())
+    errors = append(errors, createNewsletterTables())
     return errors
 }
 
@@ -105,3 +108,4 @@ func CloseDB() error {
     return nil
 }
 
+
@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package db
+
+// createLinkCheckTables creates the tables used by the background link checker,
+// `link_occurrences` is rebuilt on every run while `link_check_results` keeps the full history
+func createLinkCheckTables() error {
+    var schema = `
+        CREATE TABLE IF NOT EXISTS link_occurrences (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            url TEXT NOT NULL,
+            source_kind TEXT NOT NULL,
+            source_id TEXT NOT NULL,
+            line INTEGER NOT NULL DEFAULT 0,
+            discovered_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+            UNIQUE (url, source_kind, source_id, line)
+        );
+
+        CREATE TABLE IF NOT EXISTS link_check_results (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            url TEXT NOT NULL,
+            state INTEGER NOT NULL,
+            status_code INTEGER,
+            final_url TEXT,
+            error TEXT,
+            checked_at DATETIME DEFAULT CURRENT_TIMESTAMP
+        );
+
+        CREATE INDEX IF NOT EXISTS idx_link_occurrences_url ON link_occurrences(url);
+        CREATE INDEX IF NOT EXISTS idx_link_check_results_url ON link_check_results(url, checked_at);
+    `
+
+    if _, err := DB.Exec(schema); err != nil {
+        return err
+    }
+
+    return nil
+}
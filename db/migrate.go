@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package db
+
+import "fmt"
+
+// addColumn adds a column to a table created by an older version of the
+// schema. `CREATE TABLE IF NOT EXISTS` leaves existing tables alone, so new
+// columns have to be added by hand; it's a no-op once the column exists.
+func addColumn(table, column, definition string) error {
+    var rows, err = DB.Query(`SELECT name FROM pragma_table_info(?)`, table)
+    if err != nil {
+        return err
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var name string
+        if err := rows.Scan(&name); err != nil {
+            return err
+        }
+        if name == column {
+            return nil
+        }
+    }
+    if err := rows.Err(); err != nil {
+        return err
+    }
+    rows.Close()
+
+    _, err = DB.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, definition))
+    return err
+}
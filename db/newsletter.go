@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package db
+
+// createNewsletterTables creates the newsletter subscriber list and the queue
+// of mails waiting to go out, a queue row is kept after sending as the record
+// of who got what
+func createNewsletterTables() error {
+    var schema = `
+    CREATE TABLE IF NOT EXISTS newsletter_subscribers (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        email TEXT NOT NULL UNIQUE COLLATE NOCASE,
+        confirm_token TEXT UNIQUE,
+        unsubscribe_token TEXT NOT NULL UNIQUE,
+        requested_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+        confirmed_at DATETIME,
+        unsubscribed_at DATETIME,
+        bounced_at DATETIME,
+        bounce_reason TEXT,
+        created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+    );
+
+    CREATE TABLE IF NOT EXISTS newsletter_queue (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        kind INTEGER NOT NULL,
+        subscriber_id INTEGER NOT NULL,
+        blog_id TEXT,
+        attempts INTEGER NOT NULL DEFAULT 0,
+        next_attempt_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+        last_error TEXT,
+        sent_at DATETIME,
+        failed_at DATETIME,
+        created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+        UNIQUE (kind, subscriber_id, blog_id),
+        FOREIGN KEY (subscriber_id) REFERENCES newsletter_subscribers(id) ON DELETE CASCADE,
+        FOREIGN KEY (blog_id) REFERENCES blogs(id) ON DELETE CASCADE
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_newsletter_queue_due ON newsletter_queue(next_attempt_at) WHERE sent_at IS NULL AND failed_at IS NULL;
+    `
+
+    if _, err := DB.Exec(schema); err != nil {
+        return err
+    }
+
+    return nil
+}
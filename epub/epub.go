@@ -0,0 +1,309 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+// Package epub writes EPUB 3 books: a generated cover, a navigation document
+// and one XHTML chapter per part, with the images the parts use bundled in.
+package epub
+
+import (
+    "archive/zip"
+    "bytes"
+    "encoding/xml"
+    "fmt"
+    "hash/crc32"
+    "io"
+    "net/http"
+    "net/url"
+    "time"
+
+    "git.jelius.dev/jelius-sama/Portfolio/markdown"
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+)
+
+const MediaType = "application/epub+zip"
+
+type Options struct {
+    // LoadImage returns the bytes of the image an `![alt](src)` points to.
+    // Images that can't be loaded, or aren't in a format every reader has to
+    // support, are replaced by their alt text.
+    LoadImage func(src string) ([]byte, error)
+}
+
+// imageTypes are the core image media types of EPUB 3, by extension.
+var imageTypes = map[string]string{
+    "image/jpeg":    ".jpg",
+    "image/png":     ".png",
+    "image/gif":     ".gif",
+    "image/webp":    ".webp",
+    "image/svg+xml": ".svg",
+}
+
+// item is one file of the book besides the container boilerplate, which
+// also makes it an entry of the package manifest.
+type item struct {
+    id         string
+    href       string
+    mediaType  string
+    properties string
+    data       []byte
+}
+
+type builder struct {
+    book   *types.ExportBook
+    opts   Options
+    items  []item
+    images map[string]string
+    // chapters maps the page URL of every part to its file, so links
+    // between parts stay inside the book
+    chapters map[string]string
+}
+
+// Render writes book as an EPUB 3 file.
+func Render(w io.Writer, book *types.ExportBook, opts Options) error {
+    var b = &builder{
+        book:     book,
+        opts:     opts,
+        images:   make(map[string]string),
+        chapters: make(map[string]string),
+    }
+
+    for i, chapter := range book.Chapters {
+        b.chapters[chapter.URL] = chapterHref(i)
+    }
+
+    b.items = append(b.items,
+        item{id: "style", href: "style.css", mediaType: "text/css", data: []byte(stylesheet)},
+        item{id: "cover-image", href: "images/cover.svg", mediaType: "image/svg+xml", properties: "cover-image", data: coverImage(book)},
+        item{id: "cover", href: "cover.xhtml", mediaType: "application/xhtml+xml", properties: "svg", data: coverPage(book)},
+        item{id: "nav", href: "nav.xhtml", mediaType: "application/xhtml+xml", properties: "nav", data: navPage(book)},
+    )
+
+    for i := range book.Chapters {
+        var chapter = &book.Chapters[i]
+        var base, _ = url.Parse(chapter.URL)
+        var body = markdown.Parse(chapter.Markdown).HTML(markdown.HTMLOptions{
+            Link:  func(href string) string { return b.link(base, href) },
+            Image: b.image,
+        })
+        b.items = append(b.items, item{
+            id:        fmt.Sprintf("chapter-%d", i+1),
+            href:      chapterHref(i),
+            mediaType: "application/xhtml+xml",
+            data:      chapterPage(book, i, body),
+        })
+    }
+
+    return b.write(w)
+}
+
+func chapterHref(i int) string {
+    return fmt.Sprintf("chapter-%d.xhtml", i+1)
+}
+
+// link makes a link in a chapter absolute, or points it at the chapter it
+// links to when that is part of the book.
+func (b *builder) link(base *url.URL, href string) string {
+    var u, err = url.Parse(href)
+    if err != nil || len(href) == 0 || href[0] == '#' {
+        return href
+    }
+    if base != nil {
+        u = base.ResolveReference(u)
+    }
+
+    var fragment = u.Fragment
+    u.Fragment = ""
+    if chapter, ok := b.chapters[u.String()]; ok {
+        if len(fragment) != 0 {
+            return chapter + "#" + fragment
+        }
+        return chapter
+    }
+
+    u.Fragment = fragment
+    return u.String()
+}
+
+// image bundles the image behind src, returning its path in the book or ""
+// when it can't be used.
+func (b *builder) image(src string) string {
+    if href, ok := b.images[src]; ok {
+        return href
+    }
+    b.images[src] = ""
+    if b.opts.LoadImage == nil {
+        return ""
+    }
+
+    var data, err = b.opts.LoadImage(src)
+    if err != nil {
+        return ""
+    }
+
+    var mediaType = http.DetectContentType(data)
+    if bytes.Contains(data[:min(len(data), 512)], []byte("<svg")) {
+        mediaType = "image/svg+xml"
+    }
+    var ext, ok = imageTypes[mediaType]
+    if !ok {
+        return ""
+    }
+
+    var n = len(b.images)
+    var href = fmt.Sprintf("images/image-%d%s", n, ext)
+    b.items = append(b.items, item{id: fmt.Sprintf("image-%d", n), href: href, mediaType: mediaType, data: data})
+    b.images[src] = href
+    return href
+}
+
+type opfPackage struct {
+    XMLName          xml.Name     `xml:"package"`
+    Xmlns            string       `xml:"xmlns,attr"`
+    Version          string       `xml:"version,attr"`
+    UniqueIdentifier string       `xml:"unique-identifier,attr"`
+    Lang             string       `xml:"xml:lang,attr"`
+    Metadata         opfMetadata  `xml:"metadata"`
+    Manifest         []opfItem    `xml:"manifest>item"`
+    Spine            []opfItemRef `xml:"spine>itemref"`
+}
+
+type opfMetadata struct {
+    XmlnsDC     string    `xml:"xmlns:dc,attr"`
+    Identifier  opfID     `xml:"dc:identifier"`
+    Title       string    `xml:"dc:title"`
+    Language    string    `xml:"dc:language"`
+    Creator     string    `xml:"dc:creator,omitempty"`
+    Description string    `xml:"dc:description,omitempty"`
+    Publisher   string    `xml:"dc:publisher,omitempty"`
+    Date        string    `xml:"dc:date,omitempty"`
+    Source      string    `xml:"dc:source,omitempty"`
+    Meta        []opfMeta `xml:"meta"`
+}
+
+type opfID struct {
+    ID    string `xml:"id,attr"`
+    Value string `xml:",chardata"`
+}
+
+type opfMeta struct {
+    Property string `xml:"property,attr,omitempty"`
+    Name     string `xml:"name,attr,omitempty"`
+    Content  string `xml:"content,attr,omitempty"`
+    Value    string `xml:",chardata"`
+}
+
+type opfItem struct {
+    ID         string `xml:"id,attr"`
+    Href       string `xml:"href,attr"`
+    MediaType  string `xml:"media-type,attr"`
+    Properties string `xml:"properties,attr,omitempty"`
+}
+
+type opfItemRef struct {
+    IDRef  string `xml:"idref,attr"`
+    Linear string `xml:"linear,attr,omitempty"`
+}
+
+func (b *builder) packageDocument() ([]byte, error) {
+    var modified = b.book.Updated
+    if modified.IsZero() {
+        modified = time.Now()
+    }
+
+    var pkg = opfPackage{
+        Xmlns:            "http://www.idpf.org/2007/opf",
+        Version:          "3.0",
+        UniqueIdentifier: "book-id",
+        Lang:             b.book.Lang,
+        Metadata: opfMetadata{
+            XmlnsDC:     "http://purl.org/dc/elements/1.1/",
+            Identifier:  opfID{ID: "book-id", Value: b.book.URL},
+            Title:       b.book.Title,
+            Language:    b.book.Lang,
+            Creator:     b.book.Author,
+            Description: b.book.Description,
+            Publisher:   b.book.Site,
+            Source:      b.book.URL,
+            Meta: []opfMeta{
+                {Property: "dcterms:modified", Value: modified.UTC().Format("2006-01-02T15:04:05Z")},
+                // EPUB 2 readers find the cover through this
+                {Name: "cover", Content: "cover-image"},
+            },
+        },
+    }
+    if !b.book.Published.IsZero() {
+        pkg.Metadata.Date = b.book.Published.UTC().Format("2006-01-02T15:04:05Z")
+    }
+
+    for _, it := range b.items {
+        pkg.Manifest = append(pkg.Manifest, opfItem{ID: it.id, Href: it.href, MediaType: it.mediaType, Properties: it.properties})
+    }
+    pkg.Spine = []opfItemRef{{IDRef: "cover", Linear: "no"}, {IDRef: "nav"}}
+    for i := range b.book.Chapters {
+        pkg.Spine = append(pkg.Spine, opfItemRef{IDRef: fmt.Sprintf("chapter-%d", i+1)})
+    }
+
+    var out, err = xml.MarshalIndent(pkg, "", "  ")
+    if err != nil {
+        return nil, err
+    }
+    return append([]byte(xml.Header), out...), nil
+}
+
+const container = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+func (b *builder) write(w io.Writer) error {
+    var opf, err = b.packageDocument()
+    if err != nil {
+        return err
+    }
+
+    var zw = zip.NewWriter(w)
+
+    // The mimetype has to come first, stored rather than deflated and
+    // without a data descriptor, so readers can sniff the file type from a
+    // fixed offset.
+    var mimetype = []byte(MediaType)
+    mw, err := zw.CreateRaw(&zip.FileHeader{
+        Name:               "mimetype",
+        Method:             zip.Store,
+        CRC32:              crc32.ChecksumIEEE(mimetype),
+        CompressedSize64:   uint64(len(mimetype)),
+        UncompressedSize64: uint64(len(mimetype)),
+    })
+    if err != nil {
+        return err
+    }
+    if _, err := mw.Write(mimetype); err != nil {
+        return err
+    }
+
+    var add = func(name string, data []byte) error {
+        var fw, err = zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: b.book.Updated})
+        if err != nil {
+            return err
+        }
+        _, err = fw.Write(data)
+        return err
+    }
+
+    if err := add("META-INF/container.xml", []byte(container)); err != nil {
+        return err
+    }
+    if err := add("OEBPS/content.opf", opf); err != nil {
+        return err
+    }
+    for _, it := range b.items {
+        if err := add("OEBPS/"+it.href, it.data); err != nil {
+            return err
+        }
+    }
+
+    return zw.Close()
+}
@@ -0,0 +1,255 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package epub
+
+import (
+    "archive/zip"
+    "bytes"
+    "encoding/xml"
+    "errors"
+    "fmt"
+    "image"
+    "image/png"
+    "io"
+    "strings"
+    "testing"
+    "time"
+
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+)
+
+// manifest is the part of content.opf the tests look at.
+type manifest struct {
+    Items []struct {
+        ID         string `xml:"id,attr"`
+        Href       string `xml:"href,attr"`
+        MediaType  string `xml:"media-type,attr"`
+        Properties string `xml:"properties,attr"`
+    } `xml:"manifest>item"`
+    Spine []struct {
+        IDRef  string `xml:"idref,attr"`
+        Linear string `xml:"linear,attr"`
+    } `xml:"spine>itemref"`
+}
+
+func testBook() *types.ExportBook {
+    var published = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+    return &types.ExportBook{
+        ID:        "series",
+        Title:     "A Series",
+        Author:    "Jelius",
+        Lang:      "en",
+        Site:      "jelius.dev",
+        URL:       "https://jelius.dev/series/series",
+        Published: published,
+        Updated:   published,
+        Chapters: []types.ExportChapter{
+            {
+                ID:       "first",
+                Title:    "First",
+                URL:      "https://jelius.dev/blog/first",
+                Markdown: "# First\n\n![diagram](/assets/diagram.png)\n\nOn to [the second part](/blog/second).\n",
+            },
+            {
+                ID:       "second",
+                Title:    "Second",
+                URL:      "https://jelius.dev/blog/second",
+                Markdown: "# Second\n\nThe same ![diagram](/assets/diagram.png) again, and a ![missing](/assets/missing.png).\n",
+            },
+        },
+    }
+}
+
+func testImage(t *testing.T) []byte {
+    var buf bytes.Buffer
+    if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 4, 4))); err != nil {
+        t.Fatal(err)
+    }
+    return buf.Bytes()
+}
+
+func render(t *testing.T, book *types.ExportBook, opts Options) ([]byte, map[string][]byte, *zip.Reader) {
+    t.Helper()
+
+    var buf bytes.Buffer
+    if err := Render(&buf, book, opts); err != nil {
+        t.Fatalf("Render: %v", err)
+    }
+
+    var zr, err = zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+    if err != nil {
+        t.Fatalf("not a zip file: %v", err)
+    }
+
+    var files = make(map[string][]byte)
+    for _, f := range zr.File {
+        var rc, err = f.Open()
+        if err != nil {
+            t.Fatalf("open %s: %v", f.Name, err)
+        }
+        var data, _ = io.ReadAll(rc)
+        rc.Close()
+        files[f.Name] = data
+    }
+    return buf.Bytes(), files, zr
+}
+
+func TestRenderContainer(t *testing.T) {
+    var raw, files, zr = render(t, testBook(), Options{})
+
+    var first = zr.File[0]
+    if first.Name != "mimetype" {
+        t.Fatalf("first entry is %q, want mimetype", first.Name)
+    }
+    if first.Method != zip.Store {
+        t.Errorf("mimetype is compressed with method %d, want it stored", first.Method)
+    }
+    if len(first.Extra) != 0 {
+        t.Errorf("mimetype has %d bytes of extra fields, want none", len(first.Extra))
+    }
+    // Readers sniff the type at a fixed offset: right after the 30 byte local
+    // header and the file name.
+    if got := string(raw[30:38]); got != "mimetype" {
+        t.Errorf("local header names %q, want mimetype", got)
+    }
+    if got := string(raw[38 : 38+len(MediaType)]); got != MediaType {
+        t.Errorf("mimetype content at offset 38 is %q, want %q", got, MediaType)
+    }
+
+    var container, ok = files["META-INF/container.xml"]
+    if !ok {
+        t.Fatal("META-INF/container.xml is missing")
+    }
+    if !bytes.Contains(container, []byte(`full-path="OEBPS/content.opf"`)) {
+        t.Errorf("container.xml doesn't point at OEBPS/content.opf:\n%s", container)
+    }
+    if _, ok := files["OEBPS/content.opf"]; !ok {
+        t.Fatal("OEBPS/content.opf is missing")
+    }
+}
+
+func TestRenderManifest(t *testing.T) {
+    var book = testBook()
+    var img = testImage(t)
+    var loaded []string
+    var _, files, _ = render(t, book, Options{
+        LoadImage: func(src string) ([]byte, error) {
+            loaded = append(loaded, src)
+            if src == "/assets/diagram.png" {
+                return img, nil
+            }
+            return nil, errors.New("not found")
+        },
+    })
+
+    var pkg manifest
+    if err := xml.Unmarshal(files["OEBPS/content.opf"], &pkg); err != nil {
+        t.Fatalf("content.opf: %v", err)
+    }
+
+    var byID = make(map[string]int)
+    for i, it := range pkg.Items {
+        if _, ok := byID[it.ID]; ok {
+            t.Errorf("manifest id %q is used twice", it.ID)
+        }
+        byID[it.ID] = i
+        if _, ok := files["OEBPS/"+it.Href]; !ok {
+            t.Errorf("manifest item %q points at %s, which isn't in the book", it.ID, it.Href)
+        }
+    }
+
+    // Every file besides the boilerplate has to be in the manifest
+    var hrefs = make(map[string]bool)
+    for _, it := range pkg.Items {
+        hrefs["OEBPS/"+it.Href] = true
+    }
+    for name := range files {
+        if name == "mimetype" || name == "META-INF/container.xml" || name == "OEBPS/content.opf" {
+            continue
+        }
+        if !hrefs[name] {
+            t.Errorf("%s is in the book but not in the manifest", name)
+        }
+    }
+
+    var want = []string{"cover", "nav"}
+    for i := range book.Chapters {
+        want = append(want, fmt.Sprintf("chapter-%d", i+1))
+    }
+    var spine []string
+    for _, ref := range pkg.Spine {
+        spine = append(spine, ref.IDRef)
+        if _, ok := byID[ref.IDRef]; !ok {
+            t.Errorf("spine refers to %q, which isn't in the manifest", ref.IDRef)
+        }
+    }
+    if strings.Join(spine, " ") != strings.Join(want, " ") {
+        t.Errorf("spine is %v, want %v", spine, want)
+    }
+    if pkg.Spine[0].Linear != "no" {
+        t.Errorf("cover is linear %q in the spine, want no", pkg.Spine[0].Linear)
+    }
+
+    for i, chapter := range book.Chapters {
+        var it = pkg.Items[byID[fmt.Sprintf("chapter-%d", i+1)]]
+        if it.MediaType != "application/xhtml+xml" {
+            t.Errorf("%s has media type %q", it.Href, it.MediaType)
+        }
+        if !bytes.Contains(files["OEBPS/"+it.Href], []byte(chapter.Title)) {
+            t.Errorf("%s doesn't contain the title of %q", it.Href, chapter.ID)
+        }
+    }
+
+    var nav = pkg.Items[byID["nav"]]
+    if nav.Properties != "nav" {
+        t.Errorf("nav document has properties %q, want nav", nav.Properties)
+    }
+    for i := range book.Chapters {
+        if !bytes.Contains(files["OEBPS/"+nav.Href], []byte(`href="`+chapterHref(i)+`"`)) {
+            t.Errorf("nav document doesn't link to %s", chapterHref(i))
+        }
+    }
+
+    var cover, ok = byID["cover-image"]
+    if !ok || pkg.Items[cover].Properties != "cover-image" {
+        t.Error("manifest has no cover-image item")
+    }
+    if _, ok := byID["cover"]; !ok {
+        t.Error("manifest has no cover page")
+    }
+
+    // The image used twice is bundled once, the one that failed to load is
+    // left out and its alt text used instead.
+    var images []string
+    for _, it := range pkg.Items {
+        if strings.HasPrefix(it.ID, "image-") {
+            images = append(images, it.Href)
+            if it.MediaType != "image/png" {
+                t.Errorf("%s has media type %q, want image/png", it.Href, it.MediaType)
+            }
+            if !bytes.Equal(files["OEBPS/"+it.Href], img) {
+                t.Errorf("%s doesn't hold the loaded image", it.Href)
+            }
+        }
+    }
+    if len(images) != 1 {
+        t.Fatalf("bundled images %v, want exactly one", images)
+    }
+    if len(loaded) != 2 {
+        t.Errorf("LoadImage called for %v, want each source once", loaded)
+    }
+    for i := range book.Chapters {
+        if !bytes.Contains(files["OEBPS/"+chapterHref(i)], []byte(`src="`+images[0]+`"`)) {
+            t.Errorf("%s doesn't use the bundled image", chapterHref(i))
+        }
+    }
+    if bytes.Contains(files["OEBPS/"+chapterHref(1)], []byte("missing.png")) {
+        t.Errorf("%s still points at the image that failed to load", chapterHref(1))
+    }
+
+    // Links between parts of the book stay inside it
+    if !bytes.Contains(files["OEBPS/"+chapterHref(0)], []byte(`href="`+chapterHref(1)+`"`)) {
+        t.Errorf("%s doesn't link to the second part inside the book", chapterHref(0))
+    }
+}
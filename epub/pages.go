@@ -0,0 +1,178 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package epub
+
+import (
+    "fmt"
+    "html"
+    "strings"
+
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+)
+
+// Readers bring their own typography, this only spaces things out and keeps
+// code and tables from spilling off the page.
+const stylesheet = `body { line-height: 1.5; }
+h1, h2, h3, h4, h5, h6 { line-height: 1.25; page-break-after: avoid; }
+header { margin-bottom: 2em; }
+header .part { text-transform: uppercase; letter-spacing: 0.08em; font-size: 0.8em; opacity: 0.7; margin: 0; }
+header .excerpt { font-style: italic; opacity: 0.8; }
+pre { white-space: pre-wrap; word-wrap: break-word; font-size: 0.85em; padding: 0.75em; background: #f4f4f6; border-radius: 4px; }
+code { font-family: monospace; }
+blockquote { margin: 1em 0; padding: 0 1em; border-left: 3px solid #d0d0d8; }
+blockquote.alert { border-left-color: #1f70d9; }
+blockquote.alert-tip { border-left-color: #29994d; }
+blockquote.alert-warning { border-left-color: #bf800d; }
+blockquote.alert-danger { border-left-color: #d13333; }
+.alert-title { font-weight: bold; }
+table { border-collapse: collapse; margin: 1em 0; font-size: 0.9em; }
+th, td { border: 1px solid #d0d0d8; padding: 0.3em 0.6em; }
+th { background: #eeeef2; }
+img { max-width: 100%; height: auto; }
+nav ol { list-style: none; padding: 0; }
+nav li { margin: 0.4em 0; }
+`
+
+// page wraps body in the XHTML boilerplate every content document needs.
+func page(lang, title, bodyAttr, body string) []byte {
+    var sb strings.Builder
+    fmt.Fprintf(&sb, `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="%[1]s" xml:lang="%[1]s">
+<head>
+<meta charset="UTF-8"/>
+<title>%[2]s</title>
+<link rel="stylesheet" type="text/css" href="style.css"/>
+</head>
+<body%[3]s>
+`, html.EscapeString(lang), html.EscapeString(title), bodyAttr)
+    sb.WriteString(body)
+    sb.WriteString("</body>\n</html>\n")
+    return []byte(sb.String())
+}
+
+func chapterPage(book *types.ExportBook, i int, body string) []byte {
+    var chapter = &book.Chapters[i]
+    var sb strings.Builder
+
+    fmt.Fprintf(&sb, "<section epub:type=\"chapter\" lang=\"%[1]s\" xml:lang=\"%[1]s\">\n<header>\n", html.EscapeString(chapter.Lang))
+    if len(book.Chapters) > 1 {
+        fmt.Fprintf(&sb, "<p class=\"part\">Part %d</p>\n", i+1)
+    }
+    fmt.Fprintf(&sb, "<h1>%s</h1>\n", html.EscapeString(chapter.Title))
+    if len(chapter.Excerpt) != 0 {
+        fmt.Fprintf(&sb, "<p class=\"excerpt\">%s</p>\n", html.EscapeString(chapter.Excerpt))
+    }
+    sb.WriteString("</header>\n")
+    sb.WriteString(body)
+    sb.WriteString("</section>\n")
+
+    return page(book.Lang, chapter.Title, "", sb.String())
+}
+
+func navPage(book *types.ExportBook) []byte {
+    var sb strings.Builder
+    sb.WriteString("<nav epub:type=\"toc\" id=\"toc\">\n<h1>Contents</h1>\n<ol>\n")
+    for i, chapter := range book.Chapters {
+        fmt.Fprintf(&sb, "<li><a href=\"%s\">%s</a></li>\n", chapterHref(i), html.EscapeString(chapter.Title))
+    }
+    sb.WriteString("</ol>\n</nav>\n")
+
+    sb.WriteString("<nav epub:type=\"landmarks\" id=\"landmarks\" hidden=\"hidden\">\n<ol>\n")
+    sb.WriteString("<li><a epub:type=\"cover\" href=\"cover.xhtml\">Cover</a></li>\n")
+    sb.WriteString("<li><a epub:type=\"toc\" href=\"nav.xhtml\">Contents</a></li>\n")
+    if len(book.Chapters) != 0 {
+        fmt.Fprintf(&sb, "<li><a epub:type=\"bodymatter\" href=\"%s\">Start</a></li>\n", chapterHref(0))
+    }
+    sb.WriteString("</ol>\n</nav>\n")
+
+    return page(book.Lang, book.Title, "", sb.String())
+}
+
+func coverPage(book *types.ExportBook) []byte {
+    var body = fmt.Sprintf(`<div style="text-align: center; height: 100%%;">
+<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" version="1.1" width="100%%" height="100%%" viewBox="0 0 %d %d" preserveAspectRatio="xMidYMid meet">
+<image width="%d" height="%d" xlink:href="images/cover.svg"/>
+</svg>
+</div>
+`, coverWidth, coverHeight, coverWidth, coverHeight)
+    return page(book.Lang, book.Title, ` epub:type="cover" style="margin: 0; padding: 0;"`, body)
+}
+
+const (
+    coverWidth  = 1200
+    coverHeight = 1800
+)
+
+// coverImage draws the cover: the title set large on a dark background, with
+// the author, the number of parts and the site underneath. There are no
+// fonts to measure with, so lines are broken by an average glyph width.
+func coverImage(book *types.ExportBook) []byte {
+    const margin = 110
+    const titleSize = 104
+
+    // Bold sans serif glyphs average a little over half an em
+    var titleLines = wrapWords(book.Title, (coverWidth-2*margin)*100/(titleSize*56))
+    if len(titleLines) > 7 {
+        titleLines = append(titleLines[:6], titleLines[6]+"…")
+    }
+
+    var sb strings.Builder
+    fmt.Fprintf(&sb, `<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" version="1.1" width="%[1]d" height="%[2]d" viewBox="0 0 %[1]d %[2]d">
+<rect width="%[1]d" height="%[2]d" fill="#111114"/>
+<rect x="%[3]d" y="300" width="120" height="12" fill="#e5484d"/>
+`, coverWidth, coverHeight, margin)
+
+    var y = 300 + 60 + titleSize
+    fmt.Fprintf(&sb, `<text font-family="Helvetica, Arial, sans-serif" font-weight="bold" font-size="%d" fill="#f5f5f7">`+"\n", titleSize)
+    for _, l := range titleLines {
+        fmt.Fprintf(&sb, "<tspan x=\"%d\" y=\"%d\">%s</tspan>\n", margin, y, html.EscapeString(l))
+        y += titleSize * 6 / 5
+    }
+    sb.WriteString("</text>\n")
+
+    var details []string
+    if len(book.Author) != 0 {
+        details = append(details, book.Author)
+    }
+    if len(book.Chapters) > 1 {
+        details = append(details, fmt.Sprintf("A series in %d parts", len(book.Chapters)))
+    }
+    y += 40
+    for _, d := range details {
+        fmt.Fprintf(&sb, `<text x="%d" y="%d" font-family="Helvetica, Arial, sans-serif" font-size="48" fill="#a1a1aa">%s</text>`+"\n",
+            margin, y, html.EscapeString(d))
+        y += 66
+    }
+
+    if len(book.Site) != 0 {
+        fmt.Fprintf(&sb, `<text x="%d" y="%d" font-family="Menlo, Consolas, monospace" font-size="40" fill="#e5484d">%s</text>`+"\n",
+            margin, coverHeight-margin, html.EscapeString(book.Site))
+    }
+    sb.WriteString("</svg>\n")
+    return []byte(sb.String())
+}
+
+// wrapWords breaks s into lines of at most width characters, or longer when
+// a single word doesn't fit.
+func wrapWords(s string, width int) []string {
+    var lines []string
+    var cur string
+    for _, w := range strings.Fields(s) {
+        switch {
+        case len(cur) == 0:
+            cur = w
+        case len([]rune(cur))+1+len([]rune(w)) <= width:
+            cur += " " + w
+        default:
+            lines = append(lines, cur)
+            cur = w
+        }
+    }
+    if len(cur) != 0 {
+        lines = append(lines, cur)
+    }
+    return lines
+}
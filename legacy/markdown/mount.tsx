@@ -18,13 +18,17 @@ if (!(window as any).__mdRendererLoaded) {
         if (!src?.textContent) return
         const content = JSON.parse(src.textContent) as string
 
+        // Placeholder -> MathML, pre-rendered on the server (see markdown.RenderMath)
+        const mathSrc = rootEl.querySelector<HTMLScriptElement>('script[data-markdown-math]')
+        const math = mathSrc?.textContent ? (JSON.parse(mathSrc.textContent) as Record<string, string>) : {}
+
         rootEl.querySelector('[data-markdown-loading]')?.remove()
 
         const mount = rootEl.querySelector<HTMLElement>('[data-markdown-mount]')
         if (!mount) return // markup out of date — nothing to mount into
 
         const root = createRoot(mount)
-        root.render(<MarkdownRenderer content={content} />)
+        root.render(<MarkdownRenderer content={content} math={math} />)
         roots.set(rootEl, root)
     }
 
@@ -13,8 +13,12 @@ import { Copy, Check, ExternalLink, AlertTriangle, Info, CheckCircle, XCircle }
 interface MarkdownRendererProps {
     content: string
     className?: string
+    math?: Record<string, string>
 }
 
+// Must match markdown.MathPlaceholderPrefix on the server
+const MATH_PLACEHOLDER_PREFIX = "\uE000math:"
+
 function CodeBlock({ children, className }: { children: string; className?: string }) {
     const [copied, setCopied] = useState(false)
     const language = className?.replace("language-", "") || ""
@@ -43,6 +47,8 @@ function CodeBlock({ children, className }: { children: string; className?: stri
                 </div>
                 <button
                     onClick={handleCopy}
+                    data-analytics-event="code_copy"
+                    data-analytics-props={JSON.stringify({ language, lines: children.replace(/\n$/, "").split("\n").length })}
                     className="flex items-center gap-1 px-2 py-1 text-xs text-muted-foreground hover:text-primary transition-colors"
                 >
                     {copied ? <Check size={12} /> : <Copy size={12} />}
@@ -205,7 +211,7 @@ function TaskListItem({ children, checked }: { children: React.ReactNode; checke
     )
 }
 
-export function MarkdownRenderer({ content, className = "" }: MarkdownRendererProps) {
+export function MarkdownRenderer({ content, className = "", math = {} }: MarkdownRendererProps) {
     return (
         <div className={`terminal-markdown prose prose-invert max-w-none ${className}`}>
             <ReactMarkdown
@@ -262,6 +268,16 @@ export function MarkdownRenderer({ content, className = "" }: MarkdownRendererPr
                     code: ({ children, className, inline, node, ...props }) => {
                         const codeContent = String(children).replace(/\n$/, "")
 
+                        // Math is converted to MathML on the server and arrives as a placeholder code span
+                        if (codeContent.startsWith(MATH_PLACEHOLDER_PREFIX) && math[codeContent]) {
+                            return (
+                                <span
+                                    className="text-foreground [&_math[display=block]]:my-6 [&_math[display=block]]:overflow-x-auto"
+                                    dangerouslySetInnerHTML={{ __html: math[codeContent] }}
+                                />
+                            )
+                        }
+
                         // Check if this is definitely a fenced code block
                         const isFencedCodeBlock = className && className.startsWith("language-")
 
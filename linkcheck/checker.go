@@ -0,0 +1,535 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+// Package linkcheck periodically walks every outbound link the site
+// publishes (blog markdown, `link_entries.href` and `home_project_link.href`),
+// checks that they still resolve and records the outcome in SQLite so rot
+// shows up in a report instead of being discovered by readers.
+//
+// Internal `/blog/:id`, `/:lang/blog/:id` and `/authors/:handle` links are
+// verified against the `blogs`, `blog_translations` and `authors` tables
+// directly, everything else goes over the network with a bounded worker pool,
+// a per-host delay so we never hammer one server, and a few retries for
+// transient errors.
+package linkcheck
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "os"
+    "path"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+
+    "git.jelius.dev/jelius-sama/Portfolio/api/blogs"
+    "git.jelius.dev/jelius-sama/Portfolio/db"
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+    "github.com/gofiber/fiber/v3"
+    "github.com/jelius-sama/logger"
+)
+
+const maxRedirects = 5
+
+// Config controls how aggressive the checker is.
+type Config struct {
+    // Concurrency is the number of links checked at the same time
+    Concurrency int
+    // HostDelay is the minimum gap between two requests to the same host
+    HostDelay time.Duration
+    // Retries is how many extra attempts a link gets on network errors, 429 or 5xx
+    Retries int
+    // Timeout bounds a single HTTP request
+    Timeout time.Duration
+    // Interval is the gap between two full runs started by Start
+    Interval time.Duration
+}
+
+// hostGate serializes requests to one host and spaces them out by HostDelay.
+type hostGate struct {
+    mu   sync.Mutex
+    last time.Time
+}
+
+type Checker struct {
+    cfg     Config
+    client  *http.Client
+    hostsMu sync.Mutex
+    hosts   map[string]*hostGate
+    running sync.Mutex
+}
+
+// result is the outcome of checking one URL.
+type result struct {
+    state      types.LinkState
+    statusCode int
+    finalURL   string
+    err        error
+}
+
+func New(cfg Config) *Checker {
+    if cfg.Concurrency < 1 {
+        cfg.Concurrency = 1
+    }
+
+    return &Checker{
+        cfg: cfg,
+        client: &http.Client{
+            Timeout: cfg.Timeout,
+            // Redirects are followed by hand so each hop goes through the
+            // per-host gate and the chain can be reported.
+            CheckRedirect: func(*http.Request, []*http.Request) error {
+                return http.ErrUseLastResponse
+            },
+        },
+        hosts: make(map[string]*hostGate),
+    }
+}
+
+// Start runs the checker once right away and then every Interval in the
+// background, same lifecycle as the cache janitor.
+func (lc *Checker) Start() {
+    go func() {
+        var t = time.NewTicker(lc.cfg.Interval)
+        defer t.Stop()
+
+        for {
+            if err := lc.Run(context.Background()); err != nil {
+                logger.Error("Link check failed:", err.Error())
+            }
+            <-t.C
+        }
+    }()
+}
+
+// Run does a single full pass: collect every link, record where each one
+// appears, check them and store the results.
+func (lc *Checker) Run(ctx context.Context) error {
+    // A slow run overlapping the next tick would just double the traffic.
+    if !lc.running.TryLock() {
+        return nil
+    }
+    defer lc.running.Unlock()
+
+    var started = time.Now()
+
+    var occurrences, err = collectOccurrences(ctx)
+    if err != nil {
+        return err
+    }
+
+    if err := storeOccurrences(ctx, occurrences); err != nil {
+        return err
+    }
+
+    var jobs = make(chan string)
+    var wg sync.WaitGroup
+    var broken, redirected int
+    var countMu sync.Mutex
+
+    for range lc.cfg.Concurrency {
+        wg.Go(func() {
+            for target := range jobs {
+                var res = lc.check(ctx, target)
+                if err := storeResult(ctx, target, res); err != nil {
+                    logger.Error("Failed to store link check result:", err.Error())
+                }
+
+                countMu.Lock()
+                switch res.state {
+                case types.LSBroken:
+                    broken++
+                case types.LSRedirected:
+                    redirected++
+                }
+                countMu.Unlock()
+            }
+        })
+    }
+
+    for target := range occurrences {
+        jobs <- target
+    }
+    close(jobs)
+    wg.Wait()
+
+    logger.Info(fmt.Sprintf(
+        "Link check finished in %s: %d links, %d broken, %d redirected",
+        time.Since(started).Round(time.Second), len(occurrences), broken, redirected,
+    ))
+    return nil
+}
+
+// normalizeTarget turns an href found on a page into something checkable.
+// Root-relative links stay as paths, page-relative links are resolved against
+// the page they appear on, and schemes we can't check return an empty string.
+func normalizeTarget(href string, base string) string {
+    href = strings.TrimSpace(href)
+    if len(href) == 0 || strings.HasPrefix(href, "#") {
+        return ""
+    }
+
+    var u, err = url.Parse(href)
+    if err != nil {
+        return href // unparsable links are broken by definition, let check() say so
+    }
+    u.Fragment = ""
+
+    if u.IsAbs() {
+        if u.Scheme != "http" && u.Scheme != "https" {
+            return ""
+        }
+        return u.String()
+    }
+
+    if len(u.Host) != 0 { // protocol-relative
+        u.Scheme = "https"
+        return u.String()
+    }
+
+    if !strings.HasPrefix(u.Path, "/") {
+        if b, err := url.Parse(base); err == nil {
+            u = b.ResolveReference(u)
+        }
+    }
+    return u.String()
+}
+
+func collectOccurrences(ctx context.Context) (map[string][]types.LinkOccurrence, error) {
+    var occurrences = make(map[string][]types.LinkOccurrence)
+    var add = func(href, base string, occ types.LinkOccurrence) {
+        if target := normalizeTarget(href, base); len(target) != 0 {
+            occurrences[target] = append(occurrences[target], occ)
+        }
+    }
+
+    // Blog markdown, drafts are left out since the report is public
+    if rows, err := db.DB.QueryContext(ctx, `SELECT id FROM blogs WHERE deleted_at IS NULL AND draft = 0`); err != nil {
+        return nil, err
+    } else {
+        var ids []string
+        for rows.Next() {
+            var id string
+            if err := rows.Scan(&id); err != nil {
+                rows.Close()
+                return nil, err
+            }
+            ids = append(ids, id)
+        }
+        rows.Close()
+        if err := rows.Err(); err != nil {
+            return nil, err
+        }
+
+        for _, id := range ids {
+            var content, err = os.ReadFile(filepath.Join(types.EVDataDir.Get().Value, "blogs", id+".md"))
+            if err != nil {
+                logger.Error("Link check could not read markdown for blog", id+":", err.Error())
+                continue
+            }
+
+            for _, l := range ExtractMarkdownLinks(string(content)) {
+                add(l.URL, "/blog/"+id, types.LinkOccurrence{
+                    SourceKind: types.LSrcBlog.String(),
+                    SourceID:   id,
+                    Line:       l.Line,
+                })
+            }
+        }
+    }
+
+    // Plain href columns
+    for _, src := range []struct {
+        kind  types.LinkSource
+        query string
+    }{
+        {types.LSrcLinkEntry, `SELECT id, href FROM link_entries`},
+        {types.LSrcHomeProjectLink, `SELECT id, href FROM home_project_link`},
+    } {
+        var rows, err = db.DB.QueryContext(ctx, src.query)
+        if err != nil {
+            return nil, err
+        }
+
+        for rows.Next() {
+            var id int64
+            var href string
+            if err := rows.Scan(&id, &href); err != nil {
+                rows.Close()
+                return nil, err
+            }
+            add(href, "/", types.LinkOccurrence{
+                SourceKind: src.kind.String(),
+                SourceID:   strconv.FormatInt(id, 10),
+            })
+        }
+        rows.Close()
+        if err := rows.Err(); err != nil {
+            return nil, err
+        }
+    }
+
+    return occurrences, nil
+}
+
+func storeOccurrences(ctx context.Context, occurrences map[string][]types.LinkOccurrence) error {
+    var tx, err = db.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    if _, err := tx.ExecContext(ctx, `DELETE FROM link_occurrences`); err != nil {
+        return err
+    }
+
+    var stmt, stmtErr = tx.PrepareContext(ctx, `
+        INSERT OR IGNORE INTO link_occurrences (url, source_kind, source_id, line)
+        VALUES (?, ?, ?, ?)
+    `)
+    if stmtErr != nil {
+        return stmtErr
+    }
+    defer stmt.Close()
+
+    for target, occs := range occurrences {
+        for _, occ := range occs {
+            if _, err := stmt.ExecContext(ctx, target, occ.SourceKind, occ.SourceID, occ.Line); err != nil {
+                return err
+            }
+        }
+    }
+
+    return tx.Commit()
+}
+
+func storeResult(ctx context.Context, target string, res result) error {
+    var errMsg sql.NullString
+    if res.err != nil {
+        errMsg = sql.NullString{String: res.err.Error(), Valid: true}
+    }
+
+    var statusCode sql.NullInt64
+    if res.statusCode != 0 {
+        statusCode = sql.NullInt64{Int64: int64(res.statusCode), Valid: true}
+    }
+
+    var finalURL sql.NullString
+    if len(res.finalURL) != 0 {
+        finalURL = sql.NullString{String: res.finalURL, Valid: true}
+    }
+
+    var _, err = db.DB.ExecContext(ctx, `
+        INSERT INTO link_check_results (url, state, status_code, final_url, error, checked_at)
+        VALUES (?, ?, ?, ?, ?, datetime('now'))
+    `, target, res.state, statusCode, finalURL, errMsg)
+    return err
+}
+
+func (lc *Checker) check(ctx context.Context, target string) result {
+    if strings.HasPrefix(target, "/") {
+        if res, ok := checkInternal(ctx, target); ok {
+            return res
+        }
+
+        var host, err = url.Parse(types.EVHostname.Get().Value)
+        if err != nil {
+            return result{state: types.LSBroken, err: err}
+        }
+        target = host.JoinPath(target).String()
+    }
+
+    var res result
+    for attempt := 0; attempt <= lc.cfg.Retries; attempt++ {
+        res = lc.follow(ctx, target)
+        if !retryable(res) || attempt == lc.cfg.Retries {
+            break
+        }
+
+        select {
+        case <-ctx.Done():
+            return result{state: types.LSBroken, err: ctx.Err()}
+        case <-time.After(time.Second << attempt):
+        }
+    }
+    return res
+}
+
+// checkInternal resolves root-relative links without touching the network
+// where it can. The boolean is false when the link has to be fetched instead.
+func checkInternal(ctx context.Context, target string) (result, bool) {
+    var u, err = url.Parse(target)
+    if err != nil {
+        return result{state: types.LSBroken, err: err}, true
+    }
+    // Files such as `/blog/:id.pdf` exports and assets aren't pages, they are
+    // fetched like any other link.
+    if len(path.Ext(u.Path)) != 0 {
+        return result{}, false
+    }
+
+    if fiber.RoutePatternMatch(u.Path, "/blog/:id") {
+        var id = strings.TrimPrefix(u.Path, "/blog/")
+        var exists int
+        if err := db.DB.QueryRowContext(ctx,
+            `SELECT 1 FROM blogs WHERE id = ? AND deleted_at IS NULL AND draft = 0`, id,
+        ).Scan(&exists); err != nil {
+            if errors.Is(err, sql.ErrNoRows) {
+                return result{state: types.LSBroken, statusCode: fiber.StatusNotFound, err: errors.New("blog post does not exist")}, true
+            }
+            return result{state: types.LSBroken, err: err}, true
+        }
+        return result{state: types.LSOk, statusCode: fiber.StatusOK}, true
+    }
+
+    // `/:lang/blog/:id` only renders when the post is written in that language
+    // or has a translation for it, same as `RenderBlog`.
+    if fiber.RoutePatternMatch(u.Path, "/:lang/blog/:id") {
+        var parts = strings.SplitN(strings.TrimPrefix(u.Path, "/"), "/", 3)
+        var lang, ok = blogs.NormalizeLang(parts[0])
+        if !ok {
+            return result{state: types.LSBroken, statusCode: fiber.StatusNotFound, err: errors.New("invalid language")}, true
+        }
+
+        var exists int
+        if err := db.DB.QueryRowContext(ctx, `
+            SELECT 1 FROM blogs b
+            WHERE b.id = ? AND b.deleted_at IS NULL AND b.draft = 0
+                AND (b.lang = ? OR EXISTS (
+                    SELECT 1 FROM blog_translations t WHERE t.blog_id = b.id AND t.lang = ?
+                ))
+        `, parts[2], lang, lang).Scan(&exists); err != nil {
+            if errors.Is(err, sql.ErrNoRows) {
+                return result{state: types.LSBroken, statusCode: fiber.StatusNotFound, err: errors.New("blog post translation does not exist")}, true
+            }
+            return result{state: types.LSBroken, err: err}, true
+        }
+        return result{state: types.LSOk, statusCode: fiber.StatusOK}, true
+    }
+
+    if fiber.RoutePatternMatch(u.Path, "/authors/:handle") {
+        var exists int
+        if err := db.DB.QueryRowContext(ctx,
+            `SELECT 1 FROM authors WHERE handle = ?`, strings.TrimPrefix(u.Path, "/authors/"),
+        ).Scan(&exists); err != nil {
+            if errors.Is(err, sql.ErrNoRows) {
+                return result{state: types.LSBroken, statusCode: fiber.StatusNotFound, err: errors.New("author does not exist")}, true
+            }
+            return result{state: types.LSBroken, err: err}, true
+        }
+        return result{state: types.LSOk, statusCode: fiber.StatusOK}, true
+    }
+
+    // Only pages without parameters are known to exist from their route
+    // alone, anything else is fetched.
+    for pattern := range types.Pages {
+        if !strings.Contains(pattern, ":") && fiber.RoutePatternMatch(u.Path, pattern) {
+            return result{state: types.LSOk, statusCode: fiber.StatusOK}, true
+        }
+    }
+
+    return result{}, false
+}
+
+func retryable(res result) bool {
+    if res.err != nil && res.statusCode == 0 {
+        return true
+    }
+    return res.statusCode == http.StatusTooManyRequests || res.statusCode >= 500
+}
+
+// follow walks the redirect chain of target and classifies the final hop.
+func (lc *Checker) follow(ctx context.Context, target string) result {
+    var current = target
+
+    for hop := 0; hop <= maxRedirects; hop++ {
+        var status, location, err = lc.probe(ctx, current)
+        if err != nil {
+            return result{state: types.LSBroken, statusCode: status, err: err}
+        }
+
+        if status >= 300 && status < 400 && len(location) != 0 {
+            var base, _ = url.Parse(current)
+            var next, err = base.Parse(location)
+            if err != nil {
+                return result{state: types.LSBroken, statusCode: status, err: err}
+            }
+            current = next.String()
+            continue
+        }
+
+        var res = result{statusCode: status}
+        switch {
+        case status >= 400:
+            res.state = types.LSBroken
+        case current != target:
+            res.state = types.LSRedirected
+            res.finalURL = current
+        default:
+            res.state = types.LSOk
+        }
+        return res
+    }
+
+    return result{state: types.LSBroken, finalURL: current, err: errors.New("too many redirects")}
+}
+
+// probe issues a HEAD and falls back to GET for servers that don't
+// implement HEAD properly, which is a depressingly large number of them.
+func (lc *Checker) probe(ctx context.Context, target string) (int, string, error) {
+    var status, location, err = lc.do(ctx, http.MethodHead, target)
+    if err == nil && status < 400 {
+        return status, location, nil
+    }
+    return lc.do(ctx, http.MethodGet, target)
+}
+
+func (lc *Checker) gate(host string) *hostGate {
+    lc.hostsMu.Lock()
+    defer lc.hostsMu.Unlock()
+
+    var g, ok = lc.hosts[host]
+    if !ok {
+        g = &hostGate{}
+        lc.hosts[host] = g
+    }
+    return g
+}
+
+func (lc *Checker) do(ctx context.Context, method, target string) (int, string, error) {
+    var req, err = http.NewRequestWithContext(ctx, method, target, nil)
+    if err != nil {
+        return 0, "", err
+    }
+    req.Header.Set("User-Agent", fmt.Sprintf(
+        "Mozilla/5.0 (compatible; LinkChecker/%s; +%s)",
+        types.EVVersion.Get().Value, types.EVHostname.Get().Value,
+    ))
+
+    var g = lc.gate(req.URL.Host)
+    g.mu.Lock()
+    if wait := time.Until(g.last.Add(lc.cfg.HostDelay)); wait > 0 {
+        time.Sleep(wait)
+    }
+    var resp, doErr = lc.client.Do(req)
+    g.last = time.Now()
+    g.mu.Unlock()
+
+    if doErr != nil {
+        return 0, "", doErr
+    }
+    defer resp.Body.Close()
+
+    // Drain a bounded amount so the connection can be reused without
+    // downloading whole pages.
+    io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+    return resp.StatusCode, resp.Header.Get("Location"), nil
+}
@@ -0,0 +1,28 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package linkcheck
+
+import (
+    "git.jelius.dev/jelius-sama/Portfolio/markdown"
+)
+
+// Link is one URL found in a markdown document, Line is 1-based.
+type Link struct {
+    URL  string
+    Line int
+}
+
+// ExtractMarkdownLinks returns every link and image target in src, in the
+// order they appear. It goes by the parsed document, so code blocks and code
+// spans are skipped the way they are rendered and example URLs in snippets
+// don't get reported as broken.
+func ExtractMarkdownLinks(src string) []Link {
+    var links []Link
+    markdown.Parse(src).WalkInlines(func(_ *markdown.Block, in *markdown.Inline) {
+        if in.Kind == markdown.IKLink || in.Kind == markdown.IKImage {
+            links = append(links, Link{URL: in.URL, Line: in.Line})
+        }
+    })
+    return links
+}
@@ -0,0 +1,217 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package markdown
+
+import "strings"
+
+type BlockKind uint8
+
+const (
+    BKParagraph BlockKind = iota
+    BKHeading
+    BKCodeBlock
+    BKQuote
+    BKList
+    BKListItem
+    BKRule
+    BKTable
+    BKHTML
+)
+
+func (bk BlockKind) String() string {
+    switch bk {
+    case BKParagraph:
+        return "paragraph"
+    case BKHeading:
+        return "heading"
+    case BKCodeBlock:
+        return "code_block"
+    case BKQuote:
+        return "blockquote"
+    case BKList:
+        return "list"
+    case BKListItem:
+        return "list_item"
+    case BKRule:
+        return "thematic_break"
+    case BKTable:
+        return "table"
+    case BKHTML:
+        return "html"
+    default:
+        return ""
+    }
+}
+
+type InlineKind uint8
+
+const (
+    IKText InlineKind = iota
+    IKCode
+    IKEmphasis
+    IKStrong
+    IKStrike
+    IKLink
+    IKImage
+    IKLineBreak
+    IKHTML
+)
+
+func (ik InlineKind) String() string {
+    switch ik {
+    case IKText:
+        return "text"
+    case IKCode:
+        return "code"
+    case IKEmphasis:
+        return "emphasis"
+    case IKStrong:
+        return "strong"
+    case IKStrike:
+        return "strikethrough"
+    case IKLink:
+        return "link"
+    case IKImage:
+        return "image"
+    case IKLineBreak:
+        return "line_break"
+    case IKHTML:
+        return "html"
+    default:
+        return ""
+    }
+}
+
+type Align uint8
+
+const (
+    AlignNone Align = iota
+    AlignLeft
+    AlignCenter
+    AlignRight
+)
+
+// Block is a block level node. Which fields are set depends on Kind.
+type Block struct {
+    Kind BlockKind
+    // Line is the 1-based source line the block starts on.
+    Line int
+
+    // BKHeading
+    Level int
+    // BKList
+    Ordered bool
+    Start   int
+    // BKListItem of a task list, nil for a plain item
+    Checked *bool
+    // BKQuote written as a GitHub style alert (`> [!NOTE]`), lowercased
+    Alert string
+    // BKCodeBlock info string and BKCodeBlock/BKHTML content
+    Info    string
+    Literal string
+    // BKCodeBlock, the 1-based source line it ends on, closing fence included
+    End int
+
+    // BKParagraph, BKHeading
+    Inlines []Inline
+    // BKQuote, BKList, BKListItem
+    Children []*Block
+    // BKTable, header row first
+    Rows  [][][]Inline
+    Align []Align
+
+    raw    string   // inline source, parsed once link references are known
+    rawRow []string // table cells, same
+}
+
+// Lang is the language of a code block, the first word of its info string.
+func (b *Block) Lang() string {
+    var lang, _, _ = strings.Cut(strings.TrimSpace(b.Info), " ")
+    return lang
+}
+
+// Inline is an inline level node. Which fields are set depends on Kind.
+type Inline struct {
+    Kind InlineKind
+    // Line is the 1-based source line the node starts on.
+    Line int
+    // IKText, IKCode and IKHTML content
+    Text string
+    // IKLink and IKImage
+    URL   string
+    Title string
+    // IKEmphasis, IKStrong, IKStrike, IKLink text and IKImage alt text
+    Children []Inline
+}
+
+// LinkRef is a link reference definition (`[name]: url "title"`).
+type LinkRef struct {
+    URL   string
+    Title string
+    Line  int
+}
+
+type Document struct {
+    Blocks []*Block
+    // Refs holds the link reference definitions, keyed by normalized label.
+    Refs map[string]LinkRef
+}
+
+// Walk calls fn for every block in document order, parents before their
+// children. Returning false skips the children of that block.
+func (d *Document) Walk(fn func(b *Block) bool) {
+    var walk func(blocks []*Block)
+    walk = func(blocks []*Block) {
+        for _, b := range blocks {
+            if fn(b) {
+                walk(b.Children)
+            }
+        }
+    }
+    walk(d.Blocks)
+}
+
+// WalkInlines calls fn for every inline node of every block, parents before
+// their children, table cells included.
+func (d *Document) WalkInlines(fn func(b *Block, in *Inline)) {
+    var walk func(b *Block, inlines []Inline)
+    walk = func(b *Block, inlines []Inline) {
+        for i := range inlines {
+            fn(b, &inlines[i])
+            walk(b, inlines[i].Children)
+        }
+    }
+
+    d.Walk(func(b *Block) bool {
+        walk(b, b.Inlines)
+        for _, row := range b.Rows {
+            for _, cell := range row {
+                walk(b, cell)
+            }
+        }
+        return true
+    })
+}
+
+// PlainText flattens inlines into their text, as used for headings in a
+// table of contents or the alt text of an image.
+func PlainText(inlines []Inline) string {
+    var sb strings.Builder
+    var write func(inlines []Inline)
+    write = func(inlines []Inline) {
+        for _, in := range inlines {
+            switch in.Kind {
+            case IKText, IKCode:
+                sb.WriteString(in.Text)
+            case IKLineBreak:
+                sb.WriteByte(' ')
+            case IKHTML:
+            default:
+                write(in.Children)
+            }
+        }
+    }
+    write(inlines)
+    return sb.String()
+}
@@ -0,0 +1,242 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package markdown
+
+import (
+    "fmt"
+    "html"
+    "strconv"
+    "strings"
+    "unicode"
+)
+
+// HTMLOptions adjusts how a document is written out as HTML.
+type HTMLOptions struct {
+    // Link rewrites link targets and Image image sources, nil leaves them as
+    // they are. An image Image maps to "" is replaced by its alt text.
+    Link  func(href string) string
+    Image func(src string) string
+}
+
+// HTML writes the document out as HTML that is also well formed XML (every
+// element closed, every attribute quoted), so it works in XHTML documents
+// like EPUB chapters too. Raw HTML from the source is dropped since there is
+// no telling whether it is well formed. Headings get GitHub style slugs as
+// their ids so `#anchor` links keep working.
+func (d *Document) HTML(opts HTMLOptions) string {
+    var w = htmlWriter{opts: opts, ids: make(map[string]int)}
+    w.blocks(d.Blocks, false)
+    return w.sb.String()
+}
+
+type htmlWriter struct {
+    sb   strings.Builder
+    opts HTMLOptions
+    ids  map[string]int
+}
+
+var alertTitles = map[string]string{
+    "note":    "Note",
+    "info":    "Info",
+    "tip":     "Tip",
+    "warning": "Warning",
+    "danger":  "Danger",
+}
+
+func (w *htmlWriter) blocks(blocks []*Block, tight bool) {
+    for _, b := range blocks {
+        w.block(b, tight)
+    }
+}
+
+func (w *htmlWriter) block(b *Block, tight bool) {
+    switch b.Kind {
+    case BKParagraph:
+        if tight {
+            w.inlines(b.Inlines)
+            w.sb.WriteByte('\n')
+            return
+        }
+        w.sb.WriteString("<p>")
+        w.inlines(b.Inlines)
+        w.sb.WriteString("</p>\n")
+    case BKHeading:
+        var level = min(max(b.Level, 1), 6)
+        fmt.Fprintf(&w.sb, "<h%d id=\"%s\">", level, w.headingID(PlainText(b.Inlines)))
+        w.inlines(b.Inlines)
+        fmt.Fprintf(&w.sb, "</h%d>\n", level)
+    case BKCodeBlock:
+        w.sb.WriteString("<pre><code")
+        if lang := b.Lang(); len(lang) != 0 {
+            fmt.Fprintf(&w.sb, " class=\"language-%s\"", html.EscapeString(lang))
+        }
+        w.sb.WriteByte('>')
+        w.sb.WriteString(html.EscapeString(b.Literal))
+        w.sb.WriteString("</code></pre>\n")
+    case BKQuote:
+        if title, ok := alertTitles[b.Alert]; ok {
+            fmt.Fprintf(&w.sb, "<blockquote class=\"alert alert-%s\">\n<p class=\"alert-title\">%s</p>\n", b.Alert, title)
+        } else {
+            w.sb.WriteString("<blockquote>\n")
+        }
+        w.blocks(b.Children, false)
+        w.sb.WriteString("</blockquote>\n")
+    case BKList:
+        w.list(b)
+    case BKRule:
+        w.sb.WriteString("<hr/>\n")
+    case BKTable:
+        w.table(b)
+    case BKHTML:
+    }
+}
+
+func (w *htmlWriter) list(b *Block) {
+    var tag = "ul"
+    if b.Ordered {
+        tag = "ol"
+    }
+    w.sb.WriteString("<" + tag)
+    if b.Ordered && b.Start != 1 {
+        fmt.Fprintf(&w.sb, " start=\"%d\"", b.Start)
+    }
+    w.sb.WriteString(">\n")
+
+    // Items holding a single paragraph are written without the <p>, like a
+    // tight list
+    for _, item := range b.Children {
+        w.sb.WriteString("<li>")
+        if item.Checked != nil {
+            w.sb.WriteString("<input type=\"checkbox\" disabled=\"disabled\"")
+            if *item.Checked {
+                w.sb.WriteString(" checked=\"checked\"")
+            }
+            w.sb.WriteString("/> ")
+        }
+        var tight = len(item.Children) != 0 && item.Children[0].Kind == BKParagraph
+        for _, child := range item.Children[min(1, len(item.Children)):] {
+            tight = tight && child.Kind == BKList
+        }
+        w.blocks(item.Children, tight)
+        w.sb.WriteString("</li>\n")
+    }
+    w.sb.WriteString("</" + tag + ">\n")
+}
+
+func (w *htmlWriter) table(b *Block) {
+    w.sb.WriteString("<table>\n")
+    for r, row := range b.Rows {
+        var cell = "td"
+        if r == 0 {
+            cell = "th"
+            w.sb.WriteString("<thead>\n")
+        } else if r == 1 {
+            w.sb.WriteString("<tbody>\n")
+        }
+
+        w.sb.WriteString("<tr>")
+        for c, inlines := range row {
+            w.sb.WriteString("<" + cell)
+            if c < len(b.Align) {
+                switch b.Align[c] {
+                case AlignLeft:
+                    w.sb.WriteString(" style=\"text-align: left\"")
+                case AlignCenter:
+                    w.sb.WriteString(" style=\"text-align: center\"")
+                case AlignRight:
+                    w.sb.WriteString(" style=\"text-align: right\"")
+                }
+            }
+            w.sb.WriteByte('>')
+            w.inlines(inlines)
+            w.sb.WriteString("</" + cell + ">")
+        }
+        w.sb.WriteString("</tr>\n")
+
+        if r == 0 {
+            w.sb.WriteString("</thead>\n")
+        }
+    }
+    if len(b.Rows) > 1 {
+        w.sb.WriteString("</tbody>\n")
+    }
+    w.sb.WriteString("</table>\n")
+}
+
+func (w *htmlWriter) inlines(inlines []Inline) {
+    for _, in := range inlines {
+        switch in.Kind {
+        case IKText:
+            w.sb.WriteString(html.EscapeString(in.Text))
+        case IKCode:
+            w.sb.WriteString("<code>" + html.EscapeString(in.Text) + "</code>")
+        case IKEmphasis:
+            w.wrap("em", in.Children)
+        case IKStrong:
+            w.wrap("strong", in.Children)
+        case IKStrike:
+            w.wrap("del", in.Children)
+        case IKLink:
+            var href = in.URL
+            if w.opts.Link != nil {
+                href = w.opts.Link(href)
+            }
+            fmt.Fprintf(&w.sb, "<a href=\"%s\"", html.EscapeString(href))
+            if len(in.Title) != 0 {
+                fmt.Fprintf(&w.sb, " title=\"%s\"", html.EscapeString(in.Title))
+            }
+            w.sb.WriteByte('>')
+            w.inlines(in.Children)
+            w.sb.WriteString("</a>")
+        case IKImage:
+            var src, alt = in.URL, PlainText(in.Children)
+            if w.opts.Image != nil {
+                src = w.opts.Image(src)
+            }
+            if len(src) == 0 {
+                w.sb.WriteString(html.EscapeString(alt))
+                continue
+            }
+            fmt.Fprintf(&w.sb, "<img src=\"%s\" alt=\"%s\"", html.EscapeString(src), html.EscapeString(alt))
+            if len(in.Title) != 0 {
+                fmt.Fprintf(&w.sb, " title=\"%s\"", html.EscapeString(in.Title))
+            }
+            w.sb.WriteString("/>")
+        case IKLineBreak:
+            w.sb.WriteString("<br/>\n")
+        case IKHTML:
+        }
+    }
+}
+
+func (w *htmlWriter) wrap(tag string, children []Inline) {
+    w.sb.WriteString("<" + tag + ">")
+    w.inlines(children)
+    w.sb.WriteString("</" + tag + ">")
+}
+
+// headingID slugs text the way GitHub does: lowercased, punctuation dropped,
+// spaces turned into dashes, and a counter added to repeats.
+func (w *htmlWriter) headingID(text string) string {
+    var sb strings.Builder
+    for _, r := range strings.ToLower(text) {
+        switch {
+        case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '_':
+            sb.WriteRune(r)
+        case r == ' ':
+            sb.WriteByte('-')
+        }
+    }
+
+    var id = sb.String()
+    if len(id) == 0 {
+        id = "section"
+    }
+    if n := w.ids[id]; n != 0 {
+        w.ids[id]++
+        return id + "-" + strconv.Itoa(n)
+    }
+    w.ids[id] = 1
+    return id
+}
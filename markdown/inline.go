@@ -0,0 +1,393 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package markdown
+
+import (
+    "html"
+    "regexp"
+    "sort"
+    "strings"
+    "unicode"
+    "unicode/utf8"
+)
+
+var (
+    autolinkRe    = regexp.MustCompile(`^<([A-Za-z][A-Za-z0-9+.-]{1,31}:[^\s<>]*)>`)
+    emailLinkRe   = regexp.MustCompile(`^<([A-Za-z0-9.!#$%&'*+/=?^_{|}~-]+@[A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?(?:\.[A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?)*)>`)
+    inlineHTMLRe  = regexp.MustCompile(`^<(?:/?[A-Za-z][A-Za-z0-9-]*(?:\s+[A-Za-z_:][A-Za-z0-9_.:-]*(?:\s*=\s*(?:"[^"]*"|'[^']*'|[^\s"'=<>` + "`" + `]+))?)*\s*/?>|!--[\s\S]*?-->)`)
+    lineBreakTag  = regexp.MustCompile(`(?i)^<br\s*/?>$`)
+    bareURLRe     = regexp.MustCompile(`^(?:https?://|www\.)[^\s<]*`)
+    linkDestTitle = regexp.MustCompile(`^\(\s*(?:<([^<>\n]*)>|([^\s()]*(?:\([^\s()]*\)[^\s()]*)*))(?:\s+(?:"((?:[^"\\]|\\.)*)"|'((?:[^'\\]|\\.)*)'|\(((?:[^()\\]|\\.)*)\)))?\s*\)`)
+)
+
+type inlineParser struct {
+    src      string
+    refs     map[string]LinkRef
+    baseLine int
+    newlines []int // offsets of '\n' in src
+}
+
+func parseInlines(src string, line int, refs map[string]LinkRef) []Inline {
+    var p = inlineParser{src: src, refs: refs, baseLine: line}
+    for i := 0; i < len(src); i++ {
+        if src[i] == '\n' {
+            p.newlines = append(p.newlines, i)
+        }
+    }
+    return p.parse(0, len(src))
+}
+
+func (p *inlineParser) lineAt(off int) int {
+    return p.baseLine + sort.SearchInts(p.newlines, off)
+}
+
+func isPunct(b byte) bool {
+    return b < utf8.RuneSelf && unicode.IsPunct(rune(b)) || strings.IndexByte("$+<=>^`|~", b) != -1
+}
+
+// unescapeText resolves backslash escapes and entities.
+func unescapeText(s string) string {
+    if !strings.ContainsAny(s, `\&`) {
+        return s
+    }
+
+    var sb strings.Builder
+    for i := 0; i < len(s); i++ {
+        if s[i] == '\\' && i+1 < len(s) && isPunct(s[i+1]) {
+            sb.WriteByte(s[i+1])
+            i++
+            continue
+        }
+        sb.WriteByte(s[i])
+    }
+    return html.UnescapeString(sb.String())
+}
+
+// parse turns src[start:end] into inline nodes.
+func (p *inlineParser) parse(start, end int) []Inline {
+    var out []Inline
+    var text strings.Builder
+    var textStart = start
+
+    var flush = func() {
+        if text.Len() != 0 {
+            out = append(out, Inline{Kind: IKText, Line: p.lineAt(textStart), Text: html.UnescapeString(text.String())})
+            text.Reset()
+        }
+    }
+    var emit = func(in Inline) {
+        flush()
+        out = append(out, in)
+    }
+
+    var s = p.src
+    for i := start; i < end; {
+        if text.Len() == 0 {
+            textStart = i
+        }
+
+        switch c := s[i]; c {
+        case '\\':
+            if i+1 < end && s[i+1] == '\n' {
+                emit(Inline{Kind: IKLineBreak, Line: p.lineAt(i)})
+                i += 2
+                continue
+            }
+            if i+1 < end && isPunct(s[i+1]) {
+                // Written out escaped so a following entity isn't decoded
+                text.WriteString(html.EscapeString(s[i+1 : i+2]))
+                i += 2
+                continue
+            }
+
+        case '\n':
+            // remark-breaks: every newline is a line break
+            var t = strings.TrimRight(text.String(), " ")
+            text.Reset()
+            text.WriteString(t)
+            emit(Inline{Kind: IKLineBreak, Line: p.lineAt(i)})
+            i++
+            for i < end && s[i] == ' ' {
+                i++
+            }
+            continue
+
+        case '`':
+            var n = runLength(s[i:end], '`')
+            if close := findBacktickRun(s[i+n:end], n); close != -1 {
+                var code = strings.ReplaceAll(s[i+n:i+n+close], "\n", " ")
+                if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+                    code = code[1 : len(code)-1]
+                }
+                emit(Inline{Kind: IKCode, Line: p.lineAt(i), Text: code})
+                i += n + close + n
+                continue
+            }
+            text.WriteString(s[i : i+n])
+            i += n
+            continue
+
+        case '!':
+            if i+1 < end && s[i+1] == '[' {
+                if in, next, ok := p.link(i+1, end, true); ok {
+                    in.Line = p.lineAt(i)
+                    emit(in)
+                    i = next
+                    continue
+                }
+            }
+
+        case '[':
+            if in, next, ok := p.link(i, end, false); ok {
+                in.Line = p.lineAt(i)
+                emit(in)
+                i = next
+                continue
+            }
+
+        case '<':
+            if m := autolinkRe.FindStringSubmatch(s[i:end]); m != nil {
+                emit(Inline{Kind: IKLink, Line: p.lineAt(i), URL: m[1], Children: []Inline{{Kind: IKText, Line: p.lineAt(i), Text: m[1]}}})
+                i += len(m[0])
+                continue
+            }
+            if m := emailLinkRe.FindStringSubmatch(s[i:end]); m != nil {
+                emit(Inline{Kind: IKLink, Line: p.lineAt(i), URL: "mailto:" + m[1], Children: []Inline{{Kind: IKText, Line: p.lineAt(i), Text: m[1]}}})
+                i += len(m[0])
+                continue
+            }
+            if m := inlineHTMLRe.FindString(s[i:end]); len(m) != 0 {
+                if lineBreakTag.MatchString(m) {
+                    emit(Inline{Kind: IKLineBreak, Line: p.lineAt(i)})
+                } else {
+                    emit(Inline{Kind: IKHTML, Line: p.lineAt(i), Text: m})
+                }
+                i += len(m)
+                continue
+            }
+
+        case '*', '_', '~':
+            if in, next, ok := p.emphasis(i, end); ok {
+                emit(in)
+                i = next
+                continue
+            }
+            var n = runLength(s[i:end], c)
+            text.WriteString(s[i : i+n])
+            i += n
+            continue
+
+        case 'h', 'w':
+            // GitHub autolinked URLs, only at the start of a word
+            if i == start || !isWordByte(s[i-1]) {
+                if m := bareURLRe.FindString(s[i:end]); len(m) != 0 {
+                    m = trimURLTail(m)
+                    if len(m) > 4 && m != "www." {
+                        var href = m
+                        if strings.HasPrefix(href, "www.") {
+                            href = "http://" + href
+                        }
+                        emit(Inline{Kind: IKLink, Line: p.lineAt(i), URL: href, Children: []Inline{{Kind: IKText, Line: p.lineAt(i), Text: m}}})
+                        i += len(m)
+                        continue
+                    }
+                }
+            }
+        }
+
+        text.WriteByte(s[i])
+        i++
+    }
+    flush()
+
+    return out
+}
+
+func isWordByte(b byte) bool {
+    return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+// trimURLTail drops trailing punctuation that almost certainly isn't part of
+// an autolinked URL, keeping balanced closing parentheses.
+func trimURLTail(u string) string {
+    for len(u) != 0 {
+        switch u[len(u)-1] {
+        case '.', ',', ':', ';', '!', '?', '"', '\'', '*', '_', '~':
+            u = u[:len(u)-1]
+            continue
+        case ')':
+            if strings.Count(u, "(") < strings.Count(u, ")") {
+                u = u[:len(u)-1]
+                continue
+            }
+        }
+        break
+    }
+    return u
+}
+
+// closingBracket finds the `]` matching the `[` at open, skipping code spans,
+// escapes and nested brackets.
+func (p *inlineParser) closingBracket(open, end int) int {
+    var s = p.src
+    var depth int
+    for i := open; i < end; i++ {
+        switch s[i] {
+        case '\\':
+            i++
+        case '`':
+            var n = runLength(s[i:end], '`')
+            if close := findBacktickRun(s[i+n:end], n); close != -1 {
+                i += n + close + n - 1
+            } else {
+                i += n - 1
+            }
+        case '[':
+            depth++
+        case ']':
+            depth--
+            if depth == 0 {
+                return i
+            }
+        }
+    }
+    return -1
+}
+
+// link parses an inline link, reference link or, with image set, an image
+// whose `[` is at open.
+func (p *inlineParser) link(open, end int, image bool) (Inline, int, bool) {
+    var s = p.src
+    var close = p.closingBracket(open, end)
+    if close == -1 {
+        return Inline{}, 0, false
+    }
+
+    var kind = IKLink
+    if image {
+        kind = IKImage
+    }
+    var label = s[open+1 : close]
+    var children = p.parse(open+1, close)
+    if !image && containsLink(children) {
+        return Inline{}, 0, false
+    }
+
+    // [text](url "title")
+    if m := linkDestTitle.FindStringSubmatch(s[close+1 : end]); m != nil {
+        return Inline{
+            Kind:     kind,
+            URL:      unescapeText(m[1] + m[2]),
+            Title:    unescapeText(m[3] + m[4] + m[5]),
+            Children: children,
+        }, close + 1 + len(m[0]), true
+    }
+
+    // [text][ref], [text][] and [text]
+    var ref = label
+    var next = close + 1
+    if next < end && s[next] == '[' {
+        if refClose := strings.IndexByte(s[next:end], ']'); refClose != -1 {
+            if r := s[next+1 : next+refClose]; len(strings.TrimSpace(r)) != 0 {
+                ref = r
+            }
+            next += refClose + 1
+        }
+    }
+
+    if def, ok := p.refs[normalizeLabel(ref)]; ok {
+        return Inline{Kind: kind, URL: def.URL, Title: def.Title, Children: children}, next, true
+    }
+    return Inline{}, 0, false
+}
+
+func containsLink(inlines []Inline) bool {
+    for _, in := range inlines {
+        if in.Kind == IKLink || containsLink(in.Children) {
+            return true
+        }
+    }
+    return false
+}
+
+// emphasis parses `*em*`, `**strong**`, `***both***` and `~~strike~~`, or
+// the `_` variants, opening at i.
+func (p *inlineParser) emphasis(i, end int) (Inline, int, bool) {
+    var s = p.src
+    var c = s[i]
+    var n = runLength(s[i:end], c)
+
+    if c == '~' && n != 2 && n != 1 {
+        return Inline{}, 0, false
+    }
+
+    // The opener has to be left-flanking
+    if i+n >= end || unicode.IsSpace(rune(s[i+n])) {
+        return Inline{}, 0, false
+    }
+    if c == '_' && i > 0 && isWordByte(s[i-1]) {
+        return Inline{}, 0, false
+    }
+
+    for j := i + n; j < end; j++ {
+        switch s[j] {
+        case '\\':
+            j++
+            continue
+        case '`':
+            var m = runLength(s[j:end], '`')
+            if close := findBacktickRun(s[j+m:end], m); close != -1 {
+                j += m + close + m - 1
+            } else {
+                j += m - 1
+            }
+            continue
+        case '[':
+            // Delimiters inside link text belong to it
+            if close := p.closingBracket(j, end); close != -1 {
+                j = close
+            }
+            continue
+        }
+
+        if s[j] != c {
+            continue
+        }
+
+        var m = runLength(s[j:end], c)
+        // The closer has to be right-flanking
+        if unicode.IsSpace(rune(s[j-1])) || (c == '_' && j+m < end && isWordByte(s[j+m])) {
+            j += m - 1
+            continue
+        }
+
+        var use = min(n, m, 3)
+        if c == '~' {
+            if m != n {
+                j += m - 1
+                continue
+            }
+            return Inline{Kind: IKStrike, Line: p.lineAt(i), Children: p.parse(i+n, j)}, j + m, true
+        }
+
+        // Unmatched extra delimiters stay literal text on the outside
+        var inner = p.parse(i+n, j)
+        var node Inline
+        switch use {
+        case 1:
+            node = Inline{Kind: IKEmphasis, Line: p.lineAt(i), Children: inner}
+        case 2:
+            node = Inline{Kind: IKStrong, Line: p.lineAt(i), Children: inner}
+        default:
+            node = Inline{Kind: IKEmphasis, Line: p.lineAt(i), Children: []Inline{{Kind: IKStrong, Line: p.lineAt(i), Children: inner}}}
+        }
+        if n > use {
+            node = Inline{Kind: IKText, Line: p.lineAt(i), Text: strings.Repeat(string(c), n-use)}
+            return node, i + n - use, true
+        }
+        return node, j + use, true
+    }
+
+    return Inline{}, 0, false
+}
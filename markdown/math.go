@@ -0,0 +1,209 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+// Package markdown holds the server-side markdown processing shared by the
+// blog renderer and everything else that needs to understand post content.
+package markdown
+
+import (
+    "strconv"
+    "strings"
+)
+
+// MathPlaceholderPrefix starts the inline code span that stands in for a
+// rendered math expression. It lives in the Unicode private use area so it
+// can't collide with anything an author would type into a code span; the
+// client renderer looks the full span up in the math map and swaps in the
+// MathML.
+const MathPlaceholderPrefix = "\uE000math:"
+
+// MathWarning describes an expression that couldn't be converted and was
+// left as escaped source instead.
+type MathWarning struct {
+    Line   int
+    Source string
+    Err    error
+}
+
+// RenderMath finds `$inline$` and `$$display$$` math in src, converts each
+// expression to MathML and replaces it with a placeholder code span. The
+// returned map goes from placeholder to MathML. Expressions using
+// unsupported commands are turned into a plain code span of their source so
+// they still read sensibly, and are reported back as warnings.
+//
+// Code blocks, fenced or indented, code spans and `\$` are left untouched.
+// Which lines are code is up to Parse, the same as for everything else that
+// reads posts.
+func RenderMath(src string) (string, map[string]string, []MathWarning) {
+    var out strings.Builder
+    var rendered = make(map[string]string)
+    var warnings []MathWarning
+
+    var code = make(map[int]bool)
+    Parse(src).Walk(func(b *Block) bool {
+        if b.Kind == BKCodeBlock {
+            for line := b.Line; line <= b.End; line++ {
+                code[line] = true
+            }
+        }
+        return true
+    })
+
+    var chunk strings.Builder
+    var chunkLine = 1
+
+    var flush = func() {
+        if chunk.Len() != 0 {
+            out.WriteString(renderMathChunk(chunk.String(), chunkLine, rendered, &warnings))
+            chunk.Reset()
+        }
+    }
+
+    for i, line := range strings.SplitAfter(src, "\n") {
+        if code[i+1] {
+            flush()
+            out.WriteString(line)
+            continue
+        }
+
+        if chunk.Len() == 0 {
+            chunkLine = i + 1
+        }
+        chunk.WriteString(line)
+    }
+    flush()
+
+    return out.String(), rendered, warnings
+}
+
+// renderMathChunk handles a run of lines that sits outside any code block.
+func renderMathChunk(s string, firstLine int, rendered map[string]string, warnings *[]MathWarning) string {
+    var out strings.Builder
+
+    for i := 0; i < len(s); {
+        switch s[i] {
+        case '\\':
+            if i+1 < len(s) {
+                out.WriteString(s[i : i+2])
+                i += 2
+                continue
+            }
+        case '`':
+            var n = runLength(s[i:], '`')
+            if end := findBacktickRun(s[i+n:], n); end != -1 {
+                out.WriteString(s[i : i+n+end+n])
+                i += n + end + n
+            } else {
+                out.WriteString(s[i : i+n])
+                i += n
+            }
+            continue
+        case '$':
+            var line = firstLine + strings.Count(s[:i], "\n")
+
+            if strings.HasPrefix(s[i:], "$$") {
+                if end := strings.Index(s[i+2:], "$$"); end != -1 {
+                    var tex = s[i+2 : i+2+end]
+                    out.WriteString(mathSpan(tex, "$$"+tex+"$$", true, line, rendered, warnings))
+                    i += 2 + end + 2
+                    continue
+                }
+            } else if end := findInlineMathEnd(s[i+1:]); end != -1 {
+                var tex = s[i+1 : i+1+end]
+                out.WriteString(mathSpan(tex, "$"+tex+"$", false, line, rendered, warnings))
+                i += 1 + end + 1
+                continue
+            }
+        }
+
+        out.WriteByte(s[i])
+        i++
+    }
+
+    return out.String()
+}
+
+// findInlineMathEnd returns the index of the closing `$` for an inline
+// expression starting right after an opening `$`, or -1. Follows the usual
+// pandoc rules so prices like "$5 and $10" stay text: no whitespace just
+// inside either delimiter, no digit right after the closing one, and no
+// crossing a blank line.
+func findInlineMathEnd(s string) int {
+    if len(s) == 0 || s[0] == ' ' || s[0] == '\t' || s[0] == '\n' || s[0] == '$' {
+        return -1
+    }
+
+    for i := 1; i < len(s); i++ {
+        switch s[i] {
+        case '\\':
+            i++
+        case '\n':
+            if i+1 < len(s) && strings.TrimSpace(strings.SplitN(s[i+1:], "\n", 2)[0]) == "" {
+                return -1
+            }
+        case '$':
+            if prev := s[i-1]; prev == ' ' || prev == '\t' || prev == '\n' {
+                continue
+            }
+            if i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9' {
+                continue
+            }
+            return i
+        }
+    }
+    return -1
+}
+
+func mathSpan(tex, source string, display bool, line int, rendered map[string]string, warnings *[]MathWarning) string {
+    var mathml, err = TexToMathML(strings.TrimSpace(tex), display)
+    if err != nil {
+        *warnings = append(*warnings, MathWarning{Line: line, Source: source, Err: err})
+        return codeSpan(strings.ReplaceAll(source, "\n", " "))
+    }
+
+    var key = MathPlaceholderPrefix + strconv.Itoa(len(rendered))
+    rendered[key] = mathml
+    return "`" + key + "`"
+}
+
+// codeSpan wraps s in enough backticks that it can't close the span early.
+func codeSpan(s string) string {
+    var longest int
+    for i := 0; i < len(s); i++ {
+        if s[i] == '`' {
+            var n = runLength(s[i:], '`')
+            longest = max(longest, n)
+            i += n - 1
+        }
+    }
+
+    var fence = strings.Repeat("`", longest+1)
+    if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+        return fence + " " + s + " " + fence
+    }
+    return fence + s + fence
+}
+
+func runLength(s string, c byte) int {
+    var n int
+    for n < len(s) && s[n] == c {
+        n++
+    }
+    return n
+}
+
+// findBacktickRun returns the index of the next run of exactly n backticks.
+func findBacktickRun(s string, n int) int {
+    for i := 0; i < len(s); {
+        if s[i] != '`' {
+            i++
+            continue
+        }
+        var run = runLength(s[i:], '`')
+        if run == n {
+            return i
+        }
+        i += run
+    }
+    return -1
+}
@@ -0,0 +1,164 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package markdown
+
+import (
+    "errors"
+    "strings"
+    "testing"
+)
+
+// mathBody is the MathML of an expression without the <math> and
+// <semantics> wrapper and the TeX annotation.
+func mathBody(t *testing.T, mathml string) string {
+    t.Helper()
+    var _, body, ok = strings.Cut(mathml, "<semantics>")
+    if !ok {
+        t.Fatalf("no <semantics> in %s", mathml)
+    }
+    body, _, ok = strings.Cut(body, "<annotation")
+    if !ok {
+        t.Fatalf("no annotation in %s", mathml)
+    }
+    return body
+}
+
+func TestTexToMathML(t *testing.T) {
+    var tests = []struct {
+        tex  string
+        want string
+    }{
+        {`x^2`, `<msup><mi>x</mi><mn>2</mn></msup>`},
+        {`\frac{a}{b}`, `<mfrac><mi>a</mi><mi>b</mi></mfrac>`},
+        // Unbraced arguments are a single character, not the whole number
+        {`\frac12`, `<mfrac><mn>1</mn><mn>2</mn></mfrac>`},
+        {`\sqrt2`, `<msqrt><mn>2</mn></msqrt>`},
+        {`x^23`, `<mrow><msup><mi>x</mi><mn>2</mn></msup><mn>3</mn></mrow>`},
+        // Function application goes after the scripted name, not inside it
+        {`\sin x`, `<mrow><mi>sin</mi><mo>` + "⁡" + `</mo><mi>x</mi></mrow>`},
+        {`\sin^2 x`, `<mrow><msup><mi>sin</mi><mn>2</mn></msup><mo>` + "⁡" + `</mo><mi>x</mi></mrow>`},
+        {`\log_2 n`, `<mrow><msub><mi>log</mi><mn>2</mn></msub><mo>` + "⁡" + `</mo><mi>n</mi></mrow>`},
+        {`\lim_{x\to0} f`, `<mrow><munder><mi>lim</mi><mrow><mi>x</mi><mo>→</mo><mn>0</mn></mrow></munder><mo>` + "⁡" + `</mo><mi>f</mi></mrow>`},
+    }
+
+    for _, tt := range tests {
+        var mathml, err = TexToMathML(tt.tex, false)
+        if err != nil {
+            t.Errorf("TexToMathML(%q): %v", tt.tex, err)
+            continue
+        }
+        if got := mathBody(t, mathml); got != tt.want {
+            t.Errorf("TexToMathML(%q)\n got %s\nwant %s", tt.tex, got, tt.want)
+        }
+        if !strings.Contains(mathml, `display="inline"`) {
+            t.Errorf("TexToMathML(%q) isn't inline: %s", tt.tex, mathml)
+        }
+    }
+}
+
+func TestTexToMathMLUnsupported(t *testing.T) {
+    var tests = []struct {
+        tex     string
+        command string
+    }{
+        {`\foo`, `\foo`},
+        {`x + \foo{y}`, `\foo`},
+        {`\begin{foo}x\end{foo}`, `\begin{foo}`},
+    }
+
+    for _, tt := range tests {
+        var _, err = TexToMathML(tt.tex, false)
+        var unsupported *UnsupportedError
+        if !errors.As(err, &unsupported) {
+            t.Errorf("TexToMathML(%q) error is %v, want an UnsupportedError", tt.tex, err)
+            continue
+        }
+        if unsupported.Command != tt.command {
+            t.Errorf("TexToMathML(%q) blames %q, want %q", tt.tex, unsupported.Command, tt.command)
+        }
+    }
+}
+
+func TestRenderMath(t *testing.T) {
+    var tests = []struct {
+        name     string
+        src      string
+        want     string
+        rendered int
+    }{
+        {"inline", "a $x$ b", "a `" + MathPlaceholderPrefix + "0` b", 1},
+        {"display", "$$x^2$$\n", "`" + MathPlaceholderPrefix + "0`\n", 1},
+        {"prices", "$5 and $10", "$5 and $10", 0},
+        {"space inside", "$ x$ and $x $", "$ x$ and $x $", 0},
+        {"escaped", `\$x$ costs \$5`, `\$x$ costs \$5`, 0},
+        {"code span", "`$x$` and ``$y$``", "`$x$` and ``$y$``", 0},
+        {"fenced code", "```\n$x$\n```\n$y$", "```\n$x$\n```\n`" + MathPlaceholderPrefix + "0`", 1},
+        {"indented code", "text\n\n    $x$\n\n$y$", "text\n\n    $x$\n\n`" + MathPlaceholderPrefix + "0`", 1},
+        {"blank line", "$x\n\ny$", "$x\n\ny$", 0},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            var out, rendered, warnings = RenderMath(tt.src)
+            if out != tt.want {
+                t.Errorf("RenderMath(%q)\n got %q\nwant %q", tt.src, out, tt.want)
+            }
+            if len(rendered) != tt.rendered {
+                t.Errorf("RenderMath(%q) rendered %d expressions, want %d", tt.src, len(rendered), tt.rendered)
+            }
+            if len(warnings) != 0 {
+                t.Errorf("RenderMath(%q) warned %+v", tt.src, warnings)
+            }
+            for key, mathml := range rendered {
+                if !strings.Contains(out, "`"+key+"`") {
+                    t.Errorf("placeholder %q isn't in the output", key)
+                }
+                if !strings.HasPrefix(mathml, "<math") {
+                    t.Errorf("%q maps to %q, want MathML", key, mathml)
+                }
+            }
+        })
+    }
+}
+
+func TestRenderMathFallback(t *testing.T) {
+    var src = "a $x$ b\n\nthen $$\\foo{y}$$ and `$\\bar$`\n"
+    var out, rendered, warnings = RenderMath(src)
+
+    if want := "a `" + MathPlaceholderPrefix + "0` b\n\nthen `$$\\foo{y}$$` and `$\\bar$`\n"; out != want {
+        t.Errorf("got %q\nwant %q", out, want)
+    }
+    if len(rendered) != 1 {
+        t.Errorf("rendered %d expressions, want 1", len(rendered))
+    }
+
+    if len(warnings) != 1 {
+        t.Fatalf("warnings %+v, want one", warnings)
+    }
+    var w = warnings[0]
+    if w.Line != 3 || w.Source != `$$\foo{y}$$` {
+        t.Errorf("warning is for %q on line %d, want $$\\foo{y}$$ on line 3", w.Source, w.Line)
+    }
+    var unsupported *UnsupportedError
+    if !errors.As(w.Err, &unsupported) || unsupported.Command != `\foo` {
+        t.Errorf("warning error is %v, want \\foo unsupported", w.Err)
+    }
+}
+
+func TestCodeSpan(t *testing.T) {
+    var tests = []struct {
+        s    string
+        want string
+    }{
+        {"$x$", "`$x$`"},
+        {"a`b", "``a`b``"},
+        {"`x`", "`` `x` ``"},
+    }
+
+    for _, tt := range tests {
+        if got := codeSpan(tt.s); got != tt.want {
+            t.Errorf("codeSpan(%q) = %q, want %q", tt.s, got, tt.want)
+        }
+    }
+}
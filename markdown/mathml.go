@@ -0,0 +1,722 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package markdown
+
+import (
+    "fmt"
+    "html"
+    "strings"
+    "unicode"
+)
+
+// UnsupportedError is returned by TexToMathML when the input uses a command
+// or environment the converter doesn't know about.
+type UnsupportedError struct {
+    Command string
+}
+
+func (e *UnsupportedError) Error() string {
+    return fmt.Sprintf("unsupported TeX command %q", e.Command)
+}
+
+type texTokenKind uint8
+
+const (
+    ttCommand texTokenKind = iota
+    ttLetter
+    ttNumber
+    ttChar
+    ttOpen
+    ttClose
+    ttSup
+    ttSub
+    ttAlign
+    ttSpace
+)
+
+type texToken struct {
+    kind  texTokenKind
+    value string
+}
+
+var greekLetters = map[string]string{
+    "alpha": "α", "beta": "β", "gamma": "γ", "delta": "δ", "epsilon": "ϵ", "varepsilon": "ε",
+    "zeta": "ζ", "eta": "η", "theta": "θ", "vartheta": "ϑ", "iota": "ι", "kappa": "κ",
+    "lambda": "λ", "mu": "μ", "nu": "ν", "xi": "ξ", "pi": "π", "varpi": "ϖ", "rho": "ρ",
+    "varrho": "ϱ", "sigma": "σ", "varsigma": "ς", "tau": "τ", "upsilon": "υ", "phi": "ϕ",
+    "varphi": "φ", "chi": "χ", "psi": "ψ", "omega": "ω",
+    "Gamma": "Γ", "Delta": "Δ", "Theta": "Θ", "Lambda": "Λ", "Xi": "Ξ", "Pi": "Π",
+    "Sigma": "Σ", "Upsilon": "Υ", "Phi": "Φ", "Psi": "Ψ", "Omega": "Ω",
+}
+
+// texIdentifiers render as <mi>, texOperators as <mo>.
+var texIdentifiers = map[string]string{
+    "infty": "∞", "partial": "∂", "nabla": "∇", "emptyset": "∅", "varnothing": "∅",
+    "hbar": "ℏ", "ell": "ℓ", "Re": "ℜ", "Im": "ℑ", "aleph": "ℵ", "wp": "℘",
+    "top": "⊤", "bot": "⊥", "angle": "∠", "triangle": "△", "dagger": "†",
+}
+
+var texOperators = map[string]string{
+    "times": "×", "cdot": "⋅", "pm": "±", "mp": "∓", "div": "÷", "ast": "∗", "star": "⋆",
+    "circ": "∘", "bullet": "∙", "oplus": "⊕", "ominus": "⊖", "otimes": "⊗", "odot": "⊙",
+    "leq": "≤", "le": "≤", "geq": "≥", "ge": "≥", "neq": "≠", "ne": "≠", "approx": "≈",
+    "equiv": "≡", "sim": "∼", "simeq": "≃", "cong": "≅", "propto": "∝", "ll": "≪", "gg": "≫",
+    "to": "→", "rightarrow": "→", "leftarrow": "←", "gets": "←", "leftrightarrow": "↔",
+    "Rightarrow": "⇒", "Leftarrow": "⇐", "Leftrightarrow": "⇔", "iff": "⟺", "implies": "⟹",
+    "impliedby": "⟸", "mapsto": "↦", "longrightarrow": "⟶", "longleftarrow": "⟵",
+    "uparrow": "↑", "downarrow": "↓",
+    "in": "∈", "notin": "∉", "ni": "∋", "subset": "⊂", "subseteq": "⊆", "supset": "⊃",
+    "supseteq": "⊇", "cup": "∪", "cap": "∩", "setminus": "∖",
+    "forall": "∀", "exists": "∃", "nexists": "∄", "neg": "¬", "lnot": "¬",
+    "land": "∧", "wedge": "∧", "lor": "∨", "vee": "∨",
+    "ldots": "…", "dots": "…", "cdots": "⋯", "vdots": "⋮", "ddots": "⋱",
+    "prime": "′", "perp": "⊥", "parallel": "∥", "mid": "∣", "colon": ":",
+    "langle": "⟨", "rangle": "⟩", "lfloor": "⌊", "rfloor": "⌋", "lceil": "⌈", "rceil": "⌉",
+    "vert": "|", "Vert": "‖", "|": "‖", "{": "{", "}": "}", "lbrace": "{", "rbrace": "}",
+}
+
+// texBigOperators take their scripts as limits above and below in display mode,
+// except for integrals which always keep them to the side.
+var texBigOperators = map[string]string{
+    "sum": "∑", "prod": "∏", "coprod": "∐", "bigcup": "⋃", "bigcap": "⋂",
+    "bigoplus": "⨁", "bigotimes": "⨂", "bigvee": "⋁", "bigwedge": "⋀",
+    "int": "∫", "iint": "∬", "iiint": "∭", "oint": "∮",
+}
+
+var texFunctions = map[string]bool{
+    "sin": true, "cos": true, "tan": true, "cot": true, "sec": true, "csc": true,
+    "arcsin": true, "arccos": true, "arctan": true, "sinh": true, "cosh": true, "tanh": true,
+    "log": true, "ln": true, "lg": true, "exp": true, "det": true, "gcd": true, "deg": true,
+    "dim": true, "ker": true, "arg": true, "hom": true, "Pr": true,
+}
+
+// functionApplication is the invisible operator between a function name
+// and its argument.
+const functionApplication = "<mo>\u2061</mo>"
+
+// texLimitFunctions are functions whose subscript sits underneath, like \lim_{x \to 0}.
+var texLimitFunctions = map[string]bool{
+    "lim": true, "liminf": true, "limsup": true, "max": true, "min": true, "sup": true, "inf": true,
+}
+
+var texSpaces = map[string]string{
+    ",": "0.1667em", ":": "0.2222em", ">": "0.2222em", ";": "0.2778em", "!": "-0.1667em",
+    " ": "0.25em", "quad": "1em", "qquad": "2em", "enspace": "0.5em", "thinspace": "0.1667em",
+}
+
+var texAccents = map[string]string{
+    "hat": "^", "widehat": "^", "bar": "¯", "overline": "‾", "vec": "→", "dot": "˙",
+    "ddot": "¨", "tilde": "~", "widetilde": "~", "check": "ˇ", "breve": "˘", "acute": "´",
+    "grave": "`", "overrightarrow": "→", "overleftarrow": "←", "overbrace": "⏞",
+}
+
+var texUnderAccents = map[string]string{
+    "underline": "_", "underbrace": "⏟",
+}
+
+var texFonts = map[string]string{
+    "mathrm": "normal", "mathbf": "bold", "mathit": "italic", "mathbb": "double-struck",
+    "mathcal": "script", "mathscr": "script", "mathfrak": "fraktur", "mathsf": "sans-serif",
+    "mathtt": "monospace", "boldsymbol": "bold-italic", "bm": "bold-italic",
+}
+
+var texTextCommands = map[string]bool{
+    "text": true, "textrm": true, "textnormal": true, "mbox": true, "textit": true, "textbf": true,
+}
+
+// texIgnored are style switches that don't change the MathML we produce.
+var texIgnored = map[string]bool{
+    "displaystyle": true, "textstyle": true, "limits": true, "nolimits": true,
+    "big": true, "Big": true, "bigg": true, "Bigg": true,
+    "bigl": true, "bigr": true, "Bigl": true, "Bigr": true,
+}
+
+// texMatrixFences maps matrix-like environments to their surrounding delimiters.
+var texMatrixFences = map[string][2]string{
+    "matrix": {"", ""}, "smallmatrix": {"", ""}, "pmatrix": {"(", ")"}, "bmatrix": {"[", "]"},
+    "Bmatrix": {"{", "}"}, "vmatrix": {"|", "|"}, "Vmatrix": {"‖", "‖"}, "cases": {"{", ""},
+    "aligned": {"", ""}, "align": {"", ""}, "align*": {"", ""}, "gathered": {"", ""},
+    "array": {"", ""}, "split": {"", ""},
+}
+
+func tokenizeTex(src string) []texToken {
+    var toks []texToken
+    var rs = []rune(src)
+
+    for i := 0; i < len(rs); i++ {
+        var r = rs[i]
+        switch {
+        case r == '\\':
+            if i+1 >= len(rs) {
+                toks = append(toks, texToken{kind: ttChar, value: "\\"})
+                continue
+            }
+            var j = i + 1
+            if unicode.IsLetter(rs[j]) {
+                for j < len(rs) && unicode.IsLetter(rs[j]) {
+                    j++
+                }
+            } else {
+                j++
+            }
+            toks = append(toks, texToken{kind: ttCommand, value: string(rs[i+1 : j])})
+            i = j - 1
+        case r == '{':
+            toks = append(toks, texToken{kind: ttOpen, value: "{"})
+        case r == '}':
+            toks = append(toks, texToken{kind: ttClose, value: "}"})
+        case r == '^':
+            toks = append(toks, texToken{kind: ttSup, value: "^"})
+        case r == '_':
+            toks = append(toks, texToken{kind: ttSub, value: "_"})
+        case r == '&':
+            toks = append(toks, texToken{kind: ttAlign, value: "&"})
+        case r == '~':
+            toks = append(toks, texToken{kind: ttCommand, value: " "})
+        case unicode.IsSpace(r):
+            toks = append(toks, texToken{kind: ttSpace, value: " "})
+        case unicode.IsDigit(r) || (r == '.' && i+1 < len(rs) && unicode.IsDigit(rs[i+1])):
+            var j = i
+            for j < len(rs) && (unicode.IsDigit(rs[j]) || rs[j] == '.') {
+                j++
+            }
+            toks = append(toks, texToken{kind: ttNumber, value: string(rs[i:j])})
+            i = j - 1
+        case unicode.IsLetter(r):
+            toks = append(toks, texToken{kind: ttLetter, value: string(r)})
+        default:
+            toks = append(toks, texToken{kind: ttChar, value: string(r)})
+        }
+    }
+
+    return toks
+}
+
+type texParser struct {
+    toks    []texToken
+    pos     int
+    display bool
+    variant string
+}
+
+// TexToMathML converts a TeX math expression into a MathML <math> element.
+// Only a practical subset of LaTeX is understood; anything outside of it
+// yields an *UnsupportedError so callers can fall back to the source text.
+func TexToMathML(src string, display bool) (string, error) {
+    var p = texParser{toks: tokenizeTex(src), display: display}
+
+    var body, err = p.parseRow(func(t texToken) bool { return false })
+    if err != nil {
+        return "", err
+    }
+    if p.pos < len(p.toks) {
+        return "", fmt.Errorf("unexpected %q", p.toks[p.pos].value)
+    }
+
+    var mode = "inline"
+    if display {
+        mode = "block"
+    }
+
+    return fmt.Sprintf(
+        `<math xmlns="http://www.w3.org/1998/Math/MathML" display="%s"><semantics>%s<annotation encoding="application/x-tex">%s</annotation></semantics></math>`,
+        mode, body, html.EscapeString(src),
+    ), nil
+}
+
+func (p *texParser) peek() (texToken, bool) {
+    for p.pos < len(p.toks) && p.toks[p.pos].kind == ttSpace {
+        p.pos++
+    }
+    if p.pos >= len(p.toks) {
+        return texToken{}, false
+    }
+    return p.toks[p.pos], true
+}
+
+func (p *texParser) next() (texToken, bool) {
+    var t, ok = p.peek()
+    if ok {
+        p.pos++
+    }
+    return t, ok
+}
+
+// parseRow parses atoms until stop reports true or the input runs out and
+// returns them wrapped in a single <mrow> when there's more than one.
+func (p *texParser) parseRow(stop func(texToken) bool) (string, error) {
+    var parts []string
+    for {
+        var t, ok = p.peek()
+        if !ok || stop(t) || t.kind == ttClose {
+            break
+        }
+
+        var atom, err = p.parseAtom()
+        if err != nil {
+            return "", err
+        }
+        if len(atom) != 0 {
+            parts = append(parts, atom)
+        }
+    }
+
+    if len(parts) == 1 {
+        return parts[0], nil
+    }
+    return "<mrow>" + strings.Join(parts, "") + "</mrow>", nil
+}
+
+// parseArg parses a single required argument: a braced group or one token.
+func (p *texParser) parseArg() (string, error) {
+    var t, ok = p.peek()
+    if !ok {
+        return "", fmt.Errorf("missing argument")
+    }
+
+    if t.kind == ttOpen {
+        p.pos++
+        var row, err = p.parseRow(func(texToken) bool { return false })
+        if err != nil {
+            return "", err
+        }
+        if c, ok := p.next(); !ok || c.kind != ttClose {
+            return "", fmt.Errorf("unbalanced braces")
+        }
+        if len(row) == 0 {
+            return "<mrow></mrow>", nil
+        }
+        return row, nil
+    }
+
+    // An unbraced argument is a single character, \frac12 is a half. The
+    // tokenizer reads the digits as one number, the rest of it is left for
+    // after the argument.
+    if t.kind == ttNumber && len(t.value) > 1 {
+        p.toks[p.pos].value = t.value[1:]
+        return p.number(t.value[:1]), nil
+    }
+
+    return p.parseBase()
+}
+
+// rawGroup returns the literal text of a braced group, used by \text and friends.
+func (p *texParser) rawGroup() (string, error) {
+    if t, ok := p.next(); !ok || t.kind != ttOpen {
+        return "", fmt.Errorf("expected {")
+    }
+
+    var b strings.Builder
+    var depth = 1
+    for p.pos < len(p.toks) {
+        var t = p.toks[p.pos]
+        p.pos++
+        switch t.kind {
+        case ttOpen:
+            depth++
+        case ttClose:
+            depth--
+            if depth == 0 {
+                return b.String(), nil
+            }
+        case ttCommand:
+            if op, ok := texOperators[t.value]; ok && len([]rune(t.value)) == 1 {
+                b.WriteString(op)
+                continue
+            }
+            if strings.ContainsAny(t.value, "$%&#_") {
+                b.WriteString(t.value)
+                continue
+            }
+            b.WriteString("\\" + t.value)
+            continue
+        }
+        b.WriteString(t.value)
+    }
+    return "", fmt.Errorf("unbalanced braces")
+}
+
+func (p *texParser) parseAtom() (string, error) {
+    var t, _ = p.peek()
+    var isBigOp, isLimitFunc bool
+    if t.kind == ttCommand {
+        _, isBigOp = texBigOperators[t.value]
+        isBigOp = isBigOp && !strings.Contains(t.value, "int")
+        isLimitFunc = texLimitFunctions[t.value]
+    }
+
+    var base, err = p.parseBase()
+    if err != nil {
+        return "", err
+    }
+
+    var sub, sup string
+    for {
+        var s, ok = p.peek()
+        if !ok || (s.kind != ttSup && s.kind != ttSub) {
+            break
+        }
+        p.pos++
+
+        var arg, err = p.parseArg()
+        if err != nil {
+            return "", err
+        }
+        if s.kind == ttSup {
+            sup = arg
+        } else {
+            sub = arg
+        }
+    }
+
+    if len(sub) == 0 && len(sup) == 0 {
+        return base, nil
+    }
+    if len(base) == 0 {
+        base = "<mrow></mrow>"
+    }
+
+    // Scripts on a function like \sin^2 or \lim_{x \to 0} belong to its name,
+    // the function application operator goes after the whole thing.
+    var apply string
+    if name, ok := strings.CutSuffix(base, functionApplication); ok {
+        base, apply = name, functionApplication
+    }
+
+    var under = (isBigOp && p.display) || isLimitFunc
+    switch {
+    case len(sub) != 0 && len(sup) != 0:
+        if under {
+            return "<munderover>" + base + sub + sup + "</munderover>" + apply, nil
+        }
+        return "<msubsup>" + base + sub + sup + "</msubsup>" + apply, nil
+    case len(sub) != 0:
+        if under {
+            return "<munder>" + base + sub + "</munder>" + apply, nil
+        }
+        return "<msub>" + base + sub + "</msub>" + apply, nil
+    default:
+        if under {
+            return "<mover>" + base + sup + "</mover>" + apply, nil
+        }
+        return "<msup>" + base + sup + "</msup>" + apply, nil
+    }
+}
+
+func (p *texParser) identifier(s string) string {
+    if len(p.variant) != 0 {
+        return fmt.Sprintf(`<mi mathvariant="%s">%s</mi>`, p.variant, html.EscapeString(s))
+    }
+    return "<mi>" + html.EscapeString(s) + "</mi>"
+}
+
+func (p *texParser) number(s string) string {
+    if len(p.variant) != 0 {
+        return fmt.Sprintf(`<mn mathvariant="%s">%s</mn>`, p.variant, s)
+    }
+    return "<mn>" + s + "</mn>"
+}
+
+func (p *texParser) parseBase() (string, error) {
+    var t, ok = p.next()
+    if !ok {
+        return "", fmt.Errorf("unexpected end of input")
+    }
+
+    switch t.kind {
+    case ttLetter:
+        return p.identifier(t.value), nil
+    case ttNumber:
+        return p.number(t.value), nil
+    case ttOpen:
+        p.pos--
+        return p.parseArg()
+    case ttChar:
+        switch t.value {
+        case "-":
+            return "<mo>−</mo>", nil
+        case "*":
+            return "<mo>∗</mo>", nil
+        case "'":
+            return "<mo>′</mo>", nil
+        }
+        return "<mo>" + html.EscapeString(t.value) + "</mo>", nil
+    case ttSup, ttSub:
+        // a script with no base, e.g. {}^{14}C
+        p.pos--
+        return "", nil
+    case ttAlign:
+        return "", fmt.Errorf("unexpected &")
+    case ttCommand:
+        return p.parseCommand(t.value)
+    }
+
+    return "", fmt.Errorf("unexpected %q", t.value)
+}
+
+func (p *texParser) parseCommand(name string) (string, error) {
+    if s, ok := greekLetters[name]; ok {
+        // Upper-case Greek is upright by convention, lower-case follows the variant
+        if unicode.IsUpper([]rune(s)[0]) && len(p.variant) == 0 {
+            return `<mi mathvariant="normal">` + s + `</mi>`, nil
+        }
+        return p.identifier(s), nil
+    }
+    if s, ok := texIdentifiers[name]; ok {
+        return "<mi>" + s + "</mi>", nil
+    }
+    if s, ok := texOperators[name]; ok {
+        return "<mo>" + html.EscapeString(s) + "</mo>", nil
+    }
+    if s, ok := texBigOperators[name]; ok {
+        if !strings.Contains(name, "int") {
+            return `<mo movablelimits="true">` + s + `</mo>`, nil
+        }
+        return "<mo>" + s + "</mo>", nil
+    }
+    if texFunctions[name] || texLimitFunctions[name] {
+        var label = name
+        switch name {
+        case "liminf":
+            label = "lim inf"
+        case "limsup":
+            label = "lim sup"
+        }
+        return "<mi>" + label + "</mi>" + functionApplication, nil
+    }
+    if w, ok := texSpaces[name]; ok {
+        return fmt.Sprintf(`<mspace width="%s"></mspace>`, w), nil
+    }
+    if texIgnored[name] {
+        return "", nil
+    }
+
+    switch name {
+    case "$", "%", "&", "#", "_":
+        return "<mo>" + html.EscapeString(name) + "</mo>", nil
+    case "\\":
+        // A bare line break outside of an environment has nothing to break
+        return "", nil
+    case "frac", "dfrac", "tfrac", "cfrac":
+        var num, err = p.parseArg()
+        if err != nil {
+            return "", err
+        }
+        den, err := p.parseArg()
+        if err != nil {
+            return "", err
+        }
+        return "<mfrac>" + num + den + "</mfrac>", nil
+    case "binom", "dbinom", "tbinom":
+        var top, err = p.parseArg()
+        if err != nil {
+            return "", err
+        }
+        bottom, err := p.parseArg()
+        if err != nil {
+            return "", err
+        }
+        return `<mrow><mo>(</mo><mfrac linethickness="0">` + top + bottom + `</mfrac><mo>)</mo></mrow>`, nil
+    case "sqrt":
+        if t, ok := p.peek(); ok && t.kind == ttChar && t.value == "[" {
+            p.pos++
+            var index, err = p.parseRow(func(t texToken) bool { return t.kind == ttChar && t.value == "]" })
+            if err != nil {
+                return "", err
+            }
+            if t, ok := p.next(); !ok || t.value != "]" {
+                return "", fmt.Errorf("unterminated root index")
+            }
+            radicand, err := p.parseArg()
+            if err != nil {
+                return "", err
+            }
+            return "<mroot>" + radicand + index + "</mroot>", nil
+        }
+        var radicand, err = p.parseArg()
+        if err != nil {
+            return "", err
+        }
+        return "<msqrt>" + radicand + "</msqrt>", nil
+    case "operatorname":
+        var text, err = p.rawGroup()
+        if err != nil {
+            return "", err
+        }
+        return "<mi>" + html.EscapeString(text) + "</mi>" + functionApplication, nil
+    case "left":
+        return p.parseLeftRight()
+    case "right":
+        return "", fmt.Errorf("\\right without matching \\left")
+    case "begin":
+        return p.parseEnvironment()
+    }
+
+    if texTextCommands[name] {
+        var text, err = p.rawGroup()
+        if err != nil {
+            return "", err
+        }
+        return "<mtext>" + html.EscapeString(text) + "</mtext>", nil
+    }
+
+    if accent, ok := texAccents[name]; ok {
+        var arg, err = p.parseArg()
+        if err != nil {
+            return "", err
+        }
+        return `<mover accent="true">` + arg + `<mo stretchy="true">` + html.EscapeString(accent) + `</mo></mover>`, nil
+    }
+
+    if accent, ok := texUnderAccents[name]; ok {
+        var arg, err = p.parseArg()
+        if err != nil {
+            return "", err
+        }
+        return `<munder accentunder="true">` + arg + `<mo stretchy="true">` + accent + `</mo></munder>`, nil
+    }
+
+    if variant, ok := texFonts[name]; ok {
+        var previous = p.variant
+        p.variant = variant
+        var arg, err = p.parseArg()
+        p.variant = previous
+        return arg, err
+    }
+
+    return "", &UnsupportedError{Command: "\\" + name}
+}
+
+// fence reads the delimiter following \left or \right.
+func (p *texParser) fence() (string, error) {
+    var t, ok = p.next()
+    if !ok {
+        return "", fmt.Errorf("missing delimiter")
+    }
+
+    switch t.kind {
+    case ttChar:
+        if t.value == "." {
+            return "", nil
+        }
+        return t.value, nil
+    case ttCommand:
+        if s, ok := texOperators[t.value]; ok {
+            return s, nil
+        }
+    }
+    return "", &UnsupportedError{Command: "\\left" + t.value}
+}
+
+func (p *texParser) parseLeftRight() (string, error) {
+    var open, err = p.fence()
+    if err != nil {
+        return "", err
+    }
+
+    inner, err := p.parseRow(func(t texToken) bool { return t.kind == ttCommand && t.value == "right" })
+    if err != nil {
+        return "", err
+    }
+    if t, ok := p.next(); !ok || t.value != "right" {
+        return "", fmt.Errorf("\\left without matching \\right")
+    }
+
+    closing, err := p.fence()
+    if err != nil {
+        return "", err
+    }
+
+    var b strings.Builder
+    b.WriteString("<mrow>")
+    if len(open) != 0 {
+        b.WriteString(`<mo fence="true" stretchy="true">` + html.EscapeString(open) + `</mo>`)
+    }
+    b.WriteString(inner)
+    if len(closing) != 0 {
+        b.WriteString(`<mo fence="true" stretchy="true">` + html.EscapeString(closing) + `</mo>`)
+    }
+    b.WriteString("</mrow>")
+    return b.String(), nil
+}
+
+func (p *texParser) parseEnvironment() (string, error) {
+    var name, err = p.rawGroup()
+    if err != nil {
+        return "", err
+    }
+
+    var fences, ok = texMatrixFences[name]
+    if !ok {
+        return "", &UnsupportedError{Command: "\\begin{" + name + "}"}
+    }
+
+    // array carries a column spec we don't use
+    if name == "array" {
+        if _, err := p.rawGroup(); err != nil {
+            return "", err
+        }
+    }
+
+    var cellEnd = func(t texToken) bool {
+        return t.kind == ttAlign || (t.kind == ttCommand && (t.value == "\\" || t.value == "end"))
+    }
+
+    var rows []string
+    var cells []string
+    for {
+        var cell, err = p.parseRow(cellEnd)
+        if err != nil {
+            return "", err
+        }
+        cells = append(cells, "<mtd>"+cell+"</mtd>")
+
+        var t, ok = p.next()
+        if !ok {
+            return "", fmt.Errorf("missing \\end{%s}", name)
+        }
+        if t.kind == ttAlign {
+            continue
+        }
+
+        rows = append(rows, "<mtr>"+strings.Join(cells, "")+"</mtr>")
+        cells = nil
+
+        if t.value == "end" {
+            var end, err = p.rawGroup()
+            if err != nil {
+                return "", err
+            }
+            if end != name {
+                return "", fmt.Errorf("\\begin{%s} closed by \\end{%s}", name, end)
+            }
+            break
+        }
+    }
+
+    var attrs string
+    switch name {
+    case "cases":
+        attrs = ` columnalign="left left"`
+    case "aligned", "align", "align*", "split":
+        attrs = ` columnalign="right left" columnspacing="0em"`
+    }
+
+    var table = "<mtable" + attrs + ">" + strings.Join(rows, "") + "</mtable>"
+    if len(fences[0]) == 0 && len(fences[1]) == 0 {
+        return table, nil
+    }
+
+    var b strings.Builder
+    b.WriteString("<mrow>")
+    if len(fences[0]) != 0 {
+        b.WriteString(`<mo fence="true" stretchy="true">` + fences[0] + `</mo>`)
+    }
+    b.WriteString(table)
+    if len(fences[1]) != 0 {
+        b.WriteString(`<mo fence="true" stretchy="true">` + fences[1] + `</mo>`)
+    }
+    b.WriteString("</mrow>")
+    return b.String(), nil
+}
@@ -0,0 +1,226 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package markdown
+
+import (
+    "encoding/xml"
+    "strings"
+    "unicode/utf8"
+)
+
+// mathNode is an element of the MathML TexToMathML writes, or a bit of text
+// inside one when name is empty.
+type mathNode struct {
+    name     string
+    attrs    map[string]string
+    text     string
+    children []*mathNode
+}
+
+// MathText flattens MathML produced by TexToMathML into a single line of
+// Unicode text, for output that can't show MathML such as the PDF export:
+// `\frac{a+b}{2}` comes out as "(a + b)/2" and `x^2` as "x²". The second
+// result is the TeX source the expression was converted from.
+func MathText(mathml string) (string, string) {
+    var root, err = parseMathML(mathml)
+    if err != nil {
+        return "", ""
+    }
+
+    var tex string
+    var w mathTextWriter
+    var walk func(n *mathNode)
+    walk = func(n *mathNode) {
+        if n.name == "annotation" {
+            tex = n.text
+            return
+        }
+        for _, c := range n.children {
+            walk(c)
+        }
+    }
+    walk(root)
+
+    w.node(root)
+    return strings.Join(strings.Fields(w.sb.String()), " "), tex
+}
+
+func parseMathML(mathml string) (*mathNode, error) {
+    var d = xml.NewDecoder(strings.NewReader(mathml))
+    var root = &mathNode{}
+    var stack = []*mathNode{root}
+
+    for {
+        var tok, err = d.Token()
+        if err != nil {
+            if len(stack) == 1 {
+                return root, nil
+            }
+            return nil, err
+        }
+
+        var top = stack[len(stack)-1]
+        switch tok := tok.(type) {
+        case xml.StartElement:
+            var n = &mathNode{name: tok.Name.Local, attrs: make(map[string]string)}
+            for _, a := range tok.Attr {
+                n.attrs[a.Name.Local] = a.Value
+            }
+            top.children = append(top.children, n)
+            stack = append(stack, n)
+        case xml.EndElement:
+            stack = stack[:len(stack)-1]
+        case xml.CharData:
+            top.text += string(tok)
+        }
+    }
+}
+
+// spacedOperators get a space on either side, the way they are set in
+// MathML.
+var spacedOperators = map[string]bool{
+    "=": true, "+": true, "−": true, "±": true, "∓": true, "×": true, "÷": true,
+    "<": true, ">": true, "≤": true, "≥": true, "≠": true, "≈": true, "≡": true,
+    "∼": true, "≅": true, "∝": true, "→": true, "←": true, "↔": true, "⇒": true,
+    "⇐": true, "⇔": true, "↦": true, "∈": true, "∉": true, "⊂": true, "⊆": true,
+    "⊃": true, "⊇": true, "∪": true, "∩": true, "∧": true, "∨": true, "⋅": true,
+    "∘": true,
+}
+
+var superscripts = map[rune]rune{
+    '0': '⁰', '1': '¹', '2': '²', '3': '³', '4': '⁴', '5': '⁵', '6': '⁶', '7': '⁷',
+    '8': '⁸', '9': '⁹', '+': '⁺', '−': '⁻', '=': '⁼', '(': '⁽', ')': '⁾', 'n': 'ⁿ',
+    'i': 'ⁱ',
+}
+
+var subscripts = map[rune]rune{
+    '0': '₀', '1': '₁', '2': '₂', '3': '₃', '4': '₄', '5': '₅', '6': '₆', '7': '₇',
+    '8': '₈', '9': '₉', '+': '₊', '−': '₋', '=': '₌', '(': '₍', ')': '₎',
+}
+
+// combiningAccents turns the accents of `\hat` and friends into combining
+// marks so the accent sits on the character it belongs to.
+var combiningAccents = map[string]string{
+    "^": "̂", "¯": "̄", "‾": "̅", "→": "⃗", "˙": "̇",
+    "¨": "̈", "~": "̃", "ˇ": "̌", "˘": "̆", "´": "́",
+    "`": "̀",
+}
+
+type mathTextWriter struct {
+    sb strings.Builder
+}
+
+func (w *mathTextWriter) node(n *mathNode) {
+    switch n.name {
+    case "annotation":
+    case "mi", "mn", "mtext":
+        w.sb.WriteString(n.text)
+    case "mo":
+        if n.text == "\u2061" {
+            // function application, as in "sin x"
+            w.sb.WriteByte(' ')
+        } else if spacedOperators[n.text] {
+            w.sb.WriteString(" " + n.text + " ")
+        } else if n.text == "," || n.text == ";" {
+            w.sb.WriteString(n.text + " ")
+        } else {
+            w.sb.WriteString(n.text)
+        }
+    case "mspace":
+        w.sb.WriteByte(' ')
+    case "mfrac":
+        w.sb.WriteString(mathGroup(mathChild(n, 0)) + "/" + mathGroup(mathChild(n, 1)))
+    case "msqrt":
+        w.sb.WriteString("√" + mathGroup(&mathNode{name: "mrow", children: n.children}))
+    case "mroot":
+        w.sb.WriteString(mathScript(mathChild(n, 1), superscripts, "") + "√" + mathGroup(mathChild(n, 0)))
+    case "msup":
+        w.sb.WriteString(mathString(mathChild(n, 0)) + mathScript(mathChild(n, 1), superscripts, "^"))
+    case "msub":
+        w.sb.WriteString(mathString(mathChild(n, 0)) + mathScript(mathChild(n, 1), subscripts, "_"))
+    case "msubsup", "munderover":
+        w.sb.WriteString(mathString(mathChild(n, 0)) + mathScript(mathChild(n, 1), subscripts, "_") + mathScript(mathChild(n, 2), superscripts, "^"))
+        if mathChild(n, 0).name == "mo" {
+            // a big operator like ∑ reads as a prefix of what follows
+            w.sb.WriteByte(' ')
+        }
+    case "munder":
+        if n.attrs["accentunder"] == "true" {
+            w.sb.WriteString(mathString(mathChild(n, 0)))
+            return
+        }
+        w.sb.WriteString(mathString(mathChild(n, 0)) + mathScript(mathChild(n, 1), subscripts, "_"))
+    case "mover":
+        if n.attrs["accent"] == "true" {
+            var base, accent = mathString(mathChild(n, 0)), mathString(mathChild(n, 1))
+            if mark, ok := combiningAccents[accent]; ok && utf8.RuneCountInString(base) == 1 {
+                w.sb.WriteString(base + mark)
+            } else {
+                w.sb.WriteString(base + accent)
+            }
+            return
+        }
+        w.sb.WriteString(mathString(mathChild(n, 0)) + mathScript(mathChild(n, 1), superscripts, "^"))
+    case "mtable":
+        var rows []string
+        for _, r := range n.children {
+            var cells []string
+            for _, c := range r.children {
+                cells = append(cells, mathString(c))
+            }
+            rows = append(rows, strings.Join(cells, ", "))
+        }
+        w.sb.WriteString(strings.Join(rows, "; "))
+    default:
+        for _, c := range n.children {
+            w.node(c)
+        }
+    }
+}
+
+func mathChild(n *mathNode, i int) *mathNode {
+    if i < len(n.children) {
+        return n.children[i]
+    }
+    return &mathNode{}
+}
+
+func mathString(n *mathNode) string {
+    var w mathTextWriter
+    w.node(n)
+    return strings.Join(strings.Fields(w.sb.String()), " ")
+}
+
+// mathGroup is the text of n, in parentheses when it is more than a single
+// number or symbol, so "(a + b)/2" doesn't read as "a + b/2".
+func mathGroup(n *mathNode) string {
+    var s = mathString(n)
+    if utf8.RuneCountInString(s) <= 1 || strings.Trim(s, "0123456789.") == "" {
+        return s
+    }
+    if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") && strings.Count(s, "(") == 1 {
+        return s
+    }
+    return "(" + s + ")"
+}
+
+// mathScript writes n raised or lowered with the Unicode super/subscript forms
+// when every character has one, and as marker followed by the group
+// otherwise.
+func mathScript(n *mathNode, forms map[rune]rune, marker string) string {
+    var s = mathString(n)
+    var out strings.Builder
+    for _, r := range strings.ReplaceAll(s, " ", "") {
+        var f, ok = forms[r]
+        if !ok {
+            if r == '′' && marker == "^" {
+                out.WriteRune(r)
+                continue
+            }
+            return marker + mathGroup(n)
+        }
+        out.WriteRune(f)
+    }
+    return out.String()
+}
@@ -0,0 +1,452 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package markdown
+
+import (
+    "regexp"
+    "strconv"
+    "strings"
+)
+
+// Parse turns markdown into a document tree. It understands the same dialect
+// the client renderer does, CommonMark plus the GitHub extensions (tables,
+// task lists, strikethrough, autolinked URLs and `> [!NOTE]` alerts), and
+// like remark-breaks it treats every newline inside a paragraph as a hard
+// line break.
+//
+// It is not a full CommonMark implementation: the corner cases of emphasis
+// and list nesting resolve close to, but not always exactly like, the
+// reference parser.
+func Parse(src string) *Document {
+    src = strings.ReplaceAll(src, "\r\n", "\n")
+    src = strings.ReplaceAll(src, "\r", "\n")
+
+    var lines []srcLine
+    for i, text := range strings.Split(src, "\n") {
+        lines = append(lines, srcLine{text: expandTabs(text), num: i + 1})
+    }
+
+    var doc = &Document{Refs: make(map[string]LinkRef)}
+    doc.Blocks = parseBlocks(lines, doc)
+
+    var resolve func(blocks []*Block)
+    resolve = func(blocks []*Block) {
+        for _, b := range blocks {
+            switch b.Kind {
+            case BKParagraph, BKHeading:
+                b.Inlines = parseInlines(b.raw, b.Line, doc.Refs)
+            case BKTable:
+                b.Rows = make([][][]Inline, 0, len(b.rawRow)/max(1, len(b.Align)))
+                for i := 0; i+len(b.Align) <= len(b.rawRow); i += len(b.Align) {
+                    var row = make([][]Inline, len(b.Align))
+                    for j := range row {
+                        row[j] = parseInlines(b.rawRow[i+j], b.Line+i/len(b.Align), doc.Refs)
+                    }
+                    b.Rows = append(b.Rows, row)
+                }
+            }
+            b.raw, b.rawRow = "", nil
+            resolve(b.Children)
+        }
+    }
+    resolve(doc.Blocks)
+
+    return doc
+}
+
+type srcLine struct {
+    text string
+    num  int
+}
+
+var (
+    atxHeadingRe  = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+    fenceRe       = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})(.*)$")
+    ruleRe        = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+    setextRe      = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+    quoteRe       = regexp.MustCompile(`^ {0,3}> ?`)
+    bulletRe      = regexp.MustCompile(`^( {0,3})([-*+])( +|$)`)
+    orderedRe     = regexp.MustCompile(`^( {0,3})(\d{1,9})([.)])( +|$)`)
+    htmlBlockRe   = regexp.MustCompile(`^ {0,3}<(?:/?[A-Za-z][A-Za-z0-9-]*(?:[\s/>]|$)|!--)`)
+    linkRefRe     = regexp.MustCompile(`^ {0,3}\[([^\]]+)\]:[ \t]*<?([^\s>]*)>?(?:[ \t]+(?:"([^"]*)"|'([^']*)'|\(([^)]*)\)))?[ \t]*$`)
+    tableDelimRe  = regexp.MustCompile(`^ {0,3}\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+    taskMarkerRe  = regexp.MustCompile(`^\[([ xX])\](?:[ \t]+|$)`)
+    alertMarkerRe = regexp.MustCompile(`^\[!(NOTE|WARNING|TIP|DANGER|INFO)\][ \t]*`)
+)
+
+func expandTabs(s string) string {
+    if !strings.Contains(s, "\t") {
+        return s
+    }
+
+    var sb strings.Builder
+    var col int
+    for _, r := range s {
+        if r == '\t' {
+            var n = 4 - col%4
+            sb.WriteString(strings.Repeat(" ", n))
+            col += n
+            continue
+        }
+        sb.WriteRune(r)
+        col++
+    }
+    return sb.String()
+}
+
+func isBlank(s string) bool {
+    return len(strings.TrimSpace(s)) == 0
+}
+
+func indentOf(s string) int {
+    return len(s) - len(strings.TrimLeft(s, " "))
+}
+
+// stripIndent removes up to n leading spaces.
+func stripIndent(s string, n int) string {
+    return s[min(n, indentOf(s)):]
+}
+
+// listMarker reports whether s starts a list item and where its content starts.
+func listMarker(s string) (ordered bool, start int, marker string, contentIndent int, ok bool) {
+    if m := bulletRe.FindStringSubmatch(s); m != nil && !ruleRe.MatchString(s) {
+        var spaces = len(m[3])
+        if spaces > 4 || isBlank(s[len(m[0]):]) {
+            spaces = 1
+        }
+        return false, 0, m[2], len(m[1]) + 1 + spaces, true
+    }
+    if m := orderedRe.FindStringSubmatch(s); m != nil {
+        var spaces = len(m[4])
+        if spaces > 4 || isBlank(s[len(m[0]):]) {
+            spaces = 1
+        }
+        var n, _ = strconv.Atoi(m[2])
+        return true, n, m[3], len(m[1]) + len(m[2]) + 1 + spaces, true
+    }
+    return false, 0, "", 0, false
+}
+
+// interruptsParagraph reports whether s starts a block that can end a paragraph.
+func interruptsParagraph(s string) bool {
+    if atxHeadingRe.MatchString(s) || fenceRe.MatchString(s) || ruleRe.MatchString(s) ||
+        quoteRe.MatchString(s) || htmlBlockRe.MatchString(s) {
+        return true
+    }
+    if ordered, start, _, _, ok := listMarker(s); ok {
+        var rest = strings.TrimSpace(s)
+        if ordered {
+            _, rest, _ = strings.Cut(rest, " ")
+        } else {
+            rest = strings.TrimSpace(rest[1:])
+        }
+        // Only a non-empty item, and for ordered lists one starting at 1, may interrupt
+        return len(rest) != 0 && (!ordered || start == 1)
+    }
+    return false
+}
+
+func splitTableRow(s string) []string {
+    s = strings.TrimSpace(s)
+    s = strings.TrimPrefix(s, "|")
+    if strings.HasSuffix(s, "|") && !strings.HasSuffix(s, `\|`) {
+        s = s[:len(s)-1]
+    }
+
+    var cells []string
+    var cell strings.Builder
+    var inCode int
+    for i := 0; i < len(s); i++ {
+        switch {
+        case s[i] == '\\' && i+1 < len(s) && s[i+1] == '|':
+            cell.WriteByte('|')
+            i++
+            continue
+        case s[i] == '`':
+            var n = runLength(s[i:], '`')
+            if inCode == 0 {
+                inCode = n
+            } else if inCode == n {
+                inCode = 0
+            }
+            cell.WriteString(s[i : i+n])
+            i += n - 1
+            continue
+        case s[i] == '|' && inCode == 0:
+            cells = append(cells, strings.TrimSpace(cell.String()))
+            cell.Reset()
+            continue
+        }
+        cell.WriteByte(s[i])
+    }
+    return append(cells, strings.TrimSpace(cell.String()))
+}
+
+func parseBlocks(lines []srcLine, doc *Document) []*Block {
+    var blocks []*Block
+
+    for i := 0; i < len(lines); {
+        var line = lines[i]
+        var text = line.text
+
+        if isBlank(text) {
+            i++
+            continue
+        }
+
+        // Indented code
+        if indentOf(text) >= 4 {
+            var end = i
+            var body []string
+            for j := i; j < len(lines) && (isBlank(lines[j].text) || indentOf(lines[j].text) >= 4); j++ {
+                body = append(body, stripIndent(lines[j].text, 4))
+                if !isBlank(lines[j].text) {
+                    end = j
+                }
+            }
+            body = body[:end-i+1]
+            blocks = append(blocks, &Block{Kind: BKCodeBlock, Line: line.num, End: lines[end].num, Literal: strings.Join(body, "\n")})
+            i = end + 1
+            continue
+        }
+
+        // Fenced code
+        if m := fenceRe.FindStringSubmatch(text); m != nil && !(m[2][0] == '`' && strings.Contains(m[3], "`")) {
+            var indent, fence = len(m[1]), m[2]
+            var body []string
+            var j = i + 1
+            for ; j < len(lines); j++ {
+                var t = strings.TrimSpace(lines[j].text)
+                if indentOf(lines[j].text) < 4 && strings.HasPrefix(t, fence[:1]) &&
+                    runLength(t, fence[0]) >= len(fence) && len(t) == runLength(t, fence[0]) {
+                    break
+                }
+                body = append(body, stripIndent(lines[j].text, indent))
+            }
+            blocks = append(blocks, &Block{
+                Kind:    BKCodeBlock,
+                Line:    line.num,
+                End:     lines[min(j, len(lines)-1)].num,
+                Info:    unescapeText(strings.TrimSpace(m[3])),
+                Literal: strings.Join(body, "\n"),
+            })
+            i = j + 1
+            continue
+        }
+
+        // ATX heading
+        if m := atxHeadingRe.FindStringSubmatch(text); m != nil {
+            blocks = append(blocks, &Block{Kind: BKHeading, Line: line.num, Level: len(m[1]), raw: strings.TrimSpace(m[2])})
+            i++
+            continue
+        }
+
+        // Thematic break
+        if ruleRe.MatchString(text) {
+            blocks = append(blocks, &Block{Kind: BKRule, Line: line.num})
+            i++
+            continue
+        }
+
+        // Blockquote, lazy continuation lines included
+        if quoteRe.MatchString(text) {
+            var inner []srcLine
+            var j = i
+            for ; j < len(lines); j++ {
+                var t = lines[j].text
+                if loc := quoteRe.FindStringIndex(t); loc != nil {
+                    inner = append(inner, srcLine{text: t[loc[1]:], num: lines[j].num})
+                    continue
+                }
+                if isBlank(t) || len(inner) == 0 || isBlank(inner[len(inner)-1].text) || interruptsParagraph(t) {
+                    break
+                }
+                inner = append(inner, srcLine{text: t, num: lines[j].num})
+            }
+
+            var quote = &Block{Kind: BKQuote, Line: line.num}
+            for k := range inner {
+                if isBlank(inner[k].text) {
+                    continue
+                }
+                if m := alertMarkerRe.FindStringSubmatch(strings.TrimSpace(inner[k].text)); m != nil {
+                    quote.Alert = strings.ToLower(m[1])
+                    inner[k].text = strings.TrimSpace(inner[k].text)[len(m[0]):]
+                }
+                break
+            }
+            quote.Children = parseBlocks(inner, doc)
+            blocks = append(blocks, quote)
+            i = j
+            continue
+        }
+
+        // List
+        if ordered, start, marker, _, ok := listMarker(text); ok {
+            var list = &Block{Kind: BKList, Line: line.num, Ordered: ordered, Start: start}
+            var j = i
+            for j < len(lines) {
+                var itemOrdered, _, itemMarker, contentIndent, isItem = listMarker(lines[j].text)
+                if !isItem || itemOrdered != ordered || itemMarker != marker {
+                    break
+                }
+
+                var first = lines[j].text[min(contentIndent, len(lines[j].text)):]
+                if contentIndent > len(lines[j].text) {
+                    first = ""
+                }
+                var inner = []srcLine{{text: first, num: lines[j].num}}
+                j++
+
+                for j < len(lines) {
+                    var t = lines[j].text
+                    if isBlank(t) {
+                        inner = append(inner, srcLine{text: "", num: lines[j].num})
+                        j++
+                        continue
+                    }
+                    if indentOf(t) >= contentIndent {
+                        inner = append(inner, srcLine{text: stripIndent(t, contentIndent), num: lines[j].num})
+                        j++
+                        continue
+                    }
+                    // Lazy continuation of the item's paragraph
+                    if !isBlank(inner[len(inner)-1].text) && !interruptsParagraph(t) {
+                        if _, _, _, _, startsItem := listMarker(t); !startsItem {
+                            inner = append(inner, srcLine{text: strings.TrimLeft(t, " "), num: lines[j].num})
+                            j++
+                            continue
+                        }
+                    }
+                    break
+                }
+
+                // Trailing blank lines belong between items, not inside them
+                var k = len(inner)
+                for k > 1 && isBlank(inner[k-1].text) {
+                    k--
+                }
+                var endsBlank = k != len(inner)
+                inner = inner[:k]
+
+                var item = &Block{Kind: BKListItem, Line: inner[0].num}
+                if m := taskMarkerRe.FindStringSubmatch(inner[0].text); m != nil {
+                    var checked = m[1] != " "
+                    item.Checked = &checked
+                    inner[0].text = inner[0].text[len(m[0]):]
+                }
+                item.Children = parseBlocks(inner, doc)
+                list.Children = append(list.Children, item)
+
+                if endsBlank && j < len(lines) {
+                    if _, _, nextMarker, _, next := listMarker(lines[j].text); !next || nextMarker != marker {
+                        break
+                    }
+                }
+            }
+            blocks = append(blocks, list)
+            i = j
+            continue
+        }
+
+        // HTML block, runs until a blank line
+        if htmlBlockRe.MatchString(text) {
+            var body []string
+            var j = i
+            for ; j < len(lines) && !isBlank(lines[j].text); j++ {
+                body = append(body, lines[j].text)
+            }
+            blocks = append(blocks, &Block{Kind: BKHTML, Line: line.num, Literal: strings.Join(body, "\n")})
+            i = j
+            continue
+        }
+
+        // Link reference definition
+        if m := linkRefRe.FindStringSubmatch(text); m != nil {
+            var label = normalizeLabel(m[1])
+            if _, exists := doc.Refs[label]; !exists {
+                doc.Refs[label] = LinkRef{URL: unescapeText(m[2]), Title: unescapeText(m[3] + m[4] + m[5]), Line: line.num}
+            }
+            i++
+            continue
+        }
+
+        // Table
+        if i+1 < len(lines) && strings.Contains(text, "|") && tableDelimRe.MatchString(lines[i+1].text) {
+            var header = splitTableRow(text)
+            var delims = splitTableRow(lines[i+1].text)
+            if len(header) == len(delims) {
+                var table = &Block{Kind: BKTable, Line: line.num}
+                for _, d := range delims {
+                    switch {
+                    case strings.HasPrefix(d, ":") && strings.HasSuffix(d, ":"):
+                        table.Align = append(table.Align, AlignCenter)
+                    case strings.HasPrefix(d, ":"):
+                        table.Align = append(table.Align, AlignLeft)
+                    case strings.HasSuffix(d, ":"):
+                        table.Align = append(table.Align, AlignRight)
+                    default:
+                        table.Align = append(table.Align, AlignNone)
+                    }
+                }
+
+                table.rawRow = append(table.rawRow, header...)
+                var j = i + 2
+                for ; j < len(lines) && !isBlank(lines[j].text) && !interruptsParagraph(lines[j].text); j++ {
+                    var cells = splitTableRow(lines[j].text)
+                    // Rows are padded or cut to the header's width
+                    for len(cells) < len(header) {
+                        cells = append(cells, "")
+                    }
+                    table.rawRow = append(table.rawRow, cells[:len(header)]...)
+                }
+                blocks = append(blocks, table)
+                i = j
+                continue
+            }
+        }
+
+        // Paragraph, possibly turned into a setext heading
+        var body = []string{strings.TrimLeft(text, " ")}
+        var j = i + 1
+        var heading = 0
+        for ; j < len(lines); j++ {
+            var t = lines[j].text
+            if isBlank(t) {
+                break
+            }
+            if m := setextRe.FindStringSubmatch(t); m != nil {
+                if m[1][0] == '=' {
+                    heading = 1
+                } else {
+                    heading = 2
+                }
+                j++
+                break
+            }
+            if interruptsParagraph(t) {
+                break
+            }
+            if j+1 < len(lines) && strings.Contains(t, "|") && tableDelimRe.MatchString(lines[j+1].text) &&
+                len(splitTableRow(t)) == len(splitTableRow(lines[j+1].text)) {
+                break
+            }
+            body = append(body, strings.TrimLeft(t, " "))
+        }
+
+        var raw = strings.TrimRight(strings.Join(body, "\n"), " ")
+        if heading != 0 {
+            blocks = append(blocks, &Block{Kind: BKHeading, Line: line.num, Level: heading, raw: raw})
+        } else {
+            blocks = append(blocks, &Block{Kind: BKParagraph, Line: line.num, raw: raw})
+        }
+        i = j
+    }
+
+    return blocks
+}
+
+func normalizeLabel(label string) string {
+    return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
@@ -0,0 +1,36 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package middleware
+
+import (
+    "strings"
+
+    "git.jelius.dev/jelius-sama/Portfolio/admin"
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+    "github.com/gofiber/fiber/v3"
+)
+
+// NewAdminAuth only lets requests through that carry `ADMIN_TOKEN` as a
+// bearer token. Without a token configured the routes behind it are off.
+func NewAdminAuth() fiber.Handler {
+    return func(c fiber.Ctx) error {
+        var token = types.EVAdminToken.Get().Value
+        if len(token) == 0 {
+            return c.Status(fiber.StatusServiceUnavailable).JSON(types.ErrorResp{
+                Code:    fiber.StatusServiceUnavailable,
+                Message: "Admin API is disabled",
+            })
+        }
+
+        var given, ok = strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+        if !ok || !admin.ValidToken(given) {
+            return c.Status(fiber.StatusUnauthorized).JSON(types.ErrorResp{
+                Code:    fiber.StatusUnauthorized,
+                Message: "Unauthorized",
+            })
+        }
+
+        return c.Next()
+    }
+}
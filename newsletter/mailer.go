@@ -0,0 +1,216 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package newsletter
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "net/mail"
+    "sync"
+    "time"
+
+    "git.jelius.dev/jelius-sama/Portfolio/db"
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+    "github.com/jelius-sama/logger"
+)
+
+// batchSize bounds how many queued mails are picked up in one go.
+const batchSize = 50
+
+// Config points the Mailer at an SMTP relay.
+type Config struct {
+    Host     string
+    Port     string
+    Username string
+    Password string
+    // From is the sender, with or without a display name
+    From string
+    // Retries is how many extra attempts a mail gets after a temporary
+    // failure, spaced out further every time
+    Retries int
+    // RetryDelay is the wait before the first retry, it doubles after that
+    RetryDelay time.Duration
+    // Timeout bounds a whole SMTP conversation
+    Timeout time.Duration
+    // PollInterval is how often the queue is checked for retries that came
+    // due, new mails are picked up right away
+    PollInterval time.Duration
+}
+
+type Mailer struct {
+    cfg     Config
+    from    *mail.Address
+    running sync.Mutex
+}
+
+// job is one queued mail along with everything needed to write it.
+type job struct {
+    id               int64
+    kind             types.NewsletterMail
+    attempts         int
+    email            string
+    confirmToken     sql.NullString
+    unsubscribeToken string
+    // active is true while the subscriber still wants posts, pending is true
+    // while a signup waits for its confirmation
+    active  bool
+    pending bool
+    blogID  sql.NullString
+    title   sql.NullString
+    excerpt sql.NullString
+    hidden  bool
+}
+
+func New(cfg Config) (*Mailer, error) {
+    var from, err = mail.ParseAddress(cfg.From)
+    if err != nil {
+        return nil, fmt.Errorf("invalid sender %q: %w", cfg.From, err)
+    }
+    if cfg.RetryDelay <= 0 {
+        cfg.RetryDelay = time.Minute
+    }
+
+    return &Mailer{cfg: cfg, from: from}, nil
+}
+
+// Start works through the queue in the background, whenever something new
+// is queued and every PollInterval for retries.
+func (m *Mailer) Start() {
+    go func() {
+        var t = time.NewTicker(m.cfg.PollInterval)
+        defer t.Stop()
+
+        for {
+            if err := m.Run(context.Background()); err != nil {
+                logger.Error("Newsletter delivery failed:", err.Error())
+            }
+
+            select {
+            case <-t.C:
+            case <-wake:
+            }
+        }
+    }()
+}
+
+// Run sends every queued mail that is due.
+func (m *Mailer) Run(ctx context.Context) error {
+    if !m.running.TryLock() {
+        return nil
+    }
+    defer m.running.Unlock()
+
+    for {
+        var jobs, err = dueJobs(ctx)
+        if err != nil {
+            return err
+        }
+
+        for _, j := range jobs {
+            if err := m.deliver(ctx, j); err != nil {
+                return err
+            }
+        }
+
+        if len(jobs) < batchSize {
+            return nil
+        }
+    }
+}
+
+func dueJobs(ctx context.Context) ([]job, error) {
+    var rows, err = db.DB.QueryContext(ctx, `
+        SELECT q.id, q.kind, q.attempts, s.email, s.confirm_token, s.unsubscribe_token,
+            s.confirmed_at IS NOT NULL AND s.unsubscribed_at IS NULL AND s.bounced_at IS NULL,
+            s.confirmed_at IS NULL AND s.bounced_at IS NULL,
+            b.id, b.title, b.excerpt, b.deleted_at IS NOT NULL OR b.draft = 1
+        FROM newsletter_queue q
+        JOIN newsletter_subscribers s ON s.id = q.subscriber_id
+        LEFT JOIN blogs b ON b.id = q.blog_id
+        WHERE q.sent_at IS NULL AND q.failed_at IS NULL AND q.next_attempt_at <= datetime('now')
+        ORDER BY q.id ASC
+        LIMIT ?
+    `, batchSize)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var jobs []job
+    for rows.Next() {
+        var j job
+        var hidden sql.NullBool
+        if err := rows.Scan(
+            &j.id, &j.kind, &j.attempts, &j.email, &j.confirmToken, &j.unsubscribeToken,
+            &j.active, &j.pending, &j.blogID, &j.title, &j.excerpt, &hidden,
+        ); err != nil {
+            return nil, err
+        }
+        j.hidden = hidden.Bool
+        jobs = append(jobs, j)
+    }
+    return jobs, rows.Err()
+}
+
+// deliver sends one queued mail and records how it went. Only database
+// errors are returned, delivery failures end up on the queue row.
+func (m *Mailer) deliver(ctx context.Context, j job) error {
+    var msg *message
+    switch {
+    case j.kind == types.NMConfirm && j.pending && j.confirmToken.Valid:
+        msg = confirmMessage(j)
+    case j.kind == types.NMPost && j.active && j.blogID.Valid && !j.hidden:
+        msg = postMessage(j)
+    default:
+        // Confirmed, unsubscribed or bounced since it was queued, or the
+        // post is gone or unpublished
+        return finish(ctx, j.id, j.attempts, "skipped, no longer applies")
+    }
+
+    var err = m.send(ctx, j.email, msg)
+    if err == nil {
+        var _, err = db.DB.ExecContext(ctx, `
+            UPDATE newsletter_queue SET sent_at = datetime('now'), attempts = attempts + 1, last_error = NULL
+            WHERE id = ?
+        `, j.id)
+        return err
+    }
+
+    var de *deliveryError
+    if errors.As(err, &de) && de.permanent {
+        logger.Error(fmt.Sprintf("Newsletter mail %d to %s rejected: %s", j.id, j.email, err.Error()))
+        if de.bounce {
+            if _, err := db.DB.ExecContext(ctx, `
+                UPDATE newsletter_subscribers SET bounced_at = datetime('now'), bounce_reason = ?
+                WHERE email = ?
+            `, de.Error(), j.email); err != nil {
+                return err
+            }
+        }
+        return finish(ctx, j.id, j.attempts+1, de.Error())
+    }
+
+    if j.attempts >= m.cfg.Retries {
+        logger.Error(fmt.Sprintf("Newsletter mail %d to %s gave up after %d attempts: %s", j.id, j.email, j.attempts+1, err.Error()))
+        return finish(ctx, j.id, j.attempts+1, err.Error())
+    }
+
+    logger.Warning(fmt.Sprintf("Newsletter mail %d to %s failed, retrying: %s", j.id, j.email, err.Error()))
+    var _, dbErr = db.DB.ExecContext(ctx, `
+        UPDATE newsletter_queue SET attempts = attempts + 1, last_error = ?, next_attempt_at = datetime('now', ?)
+        WHERE id = ?
+    `, err.Error(), sqliteOffset(m.cfg.RetryDelay<<j.attempts), j.id)
+    return dbErr
+}
+
+// finish takes a mail off the queue without it having been sent.
+func finish(ctx context.Context, id int64, attempts int, reason string) error {
+    var _, err = db.DB.ExecContext(ctx, `
+        UPDATE newsletter_queue SET failed_at = datetime('now'), attempts = ?, last_error = ?
+        WHERE id = ?
+    `, attempts, reason, id)
+    return err
+}
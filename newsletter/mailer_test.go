@@ -0,0 +1,198 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package newsletter
+
+import (
+    "context"
+    "strings"
+    "testing"
+
+    "git.jelius.dev/jelius-sama/Portfolio/db"
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+)
+
+// due makes every retry waiting on the queue due now.
+func due(t *testing.T) {
+    t.Helper()
+    if _, err := db.DB.Exec(`UPDATE newsletter_queue SET next_attempt_at = datetime('now') WHERE sent_at IS NULL AND failed_at IS NULL`); err != nil {
+        t.Fatal(err)
+    }
+}
+
+func TestRetryTemporaryFailure(t *testing.T) {
+    reset(t)
+    var r = newRelay(t)
+    var m = r.mailer(t, 2)
+    var ctx = context.Background()
+
+    subscriber(t, "flaky@example.test")
+    post(t, "abc1234", "A New Post", false)
+    r.reply("flaky@example.test", "451 4.3.0 try again later")
+
+    if err := QueuePost(ctx, "abc1234"); err != nil {
+        t.Fatal(err)
+    }
+    if err := m.Run(ctx); err != nil {
+        t.Fatal(err)
+    }
+
+    var q = queued(t, "flaky@example.test", types.NMPost)
+    if q.sent || q.failed || q.due || q.attempts != 1 {
+        t.Fatalf("after a 451 the mail is %+v, want it waiting for a retry", q)
+    }
+    if !strings.Contains(q.lastError, "451") {
+        t.Errorf("last error is %q, want the relay's reply", q.lastError)
+    }
+
+    // Not due yet, so running again doesn't touch it
+    if err := m.Run(ctx); err != nil {
+        t.Fatal(err)
+    }
+    if got := r.deliveries(); len(got) != 0 {
+        t.Fatalf("mail went out before its retry was due")
+    }
+
+    due(t)
+    if err := m.Run(ctx); err != nil {
+        t.Fatal(err)
+    }
+    if q := queued(t, "flaky@example.test", types.NMPost); !q.sent || q.attempts != 2 || len(q.lastError) != 0 {
+        t.Errorf("retried mail is %+v, want sent on the second attempt", q)
+    }
+    if got := r.deliveries(); len(got) != 1 {
+        t.Errorf("relay got %d mails, want 1", len(got))
+    }
+}
+
+func TestRetryGivesUp(t *testing.T) {
+    reset(t)
+    var r = newRelay(t)
+    var m = r.mailer(t, 2)
+    var ctx = context.Background()
+
+    subscriber(t, "down@example.test")
+    post(t, "abc1234", "A New Post", false)
+    r.reply("down@example.test", "421 4.7.0 busy", "421 4.7.0 busy", "421 4.7.0 busy", "421 4.7.0 busy")
+
+    if err := QueuePost(ctx, "abc1234"); err != nil {
+        t.Fatal(err)
+    }
+    for range 3 {
+        if err := m.Run(ctx); err != nil {
+            t.Fatal(err)
+        }
+        due(t)
+    }
+
+    var q = queued(t, "down@example.test", types.NMPost)
+    if q.sent || !q.failed || q.attempts != 3 {
+        t.Errorf("mail is %+v, want given up after 3 attempts", q)
+    }
+
+    // A temporary failure says nothing about the address
+    var bounced bool
+    if err := db.DB.QueryRow(`SELECT bounced_at IS NOT NULL FROM newsletter_subscribers WHERE email = ?`, "down@example.test").Scan(&bounced); err != nil {
+        t.Fatal(err)
+    }
+    if bounced {
+        t.Error("address was flagged as bounced after temporary failures")
+    }
+}
+
+func TestBounce(t *testing.T) {
+    reset(t)
+    var r = newRelay(t)
+    var m = r.mailer(t, 2)
+    var ctx = context.Background()
+
+    subscriber(t, "nobody@example.test")
+    subscriber(t, "reader@example.test")
+    post(t, "abc1234", "First", false)
+    post(t, "def5678", "Second", false)
+    r.reply("nobody@example.test", "550 5.1.1 no such user")
+
+    if err := QueuePost(ctx, "abc1234"); err != nil {
+        t.Fatal(err)
+    }
+    if err := m.Run(ctx); err != nil {
+        t.Fatal(err)
+    }
+
+    // Refused outright: no retries and the address is never mailed again
+    if q := queued(t, "nobody@example.test", types.NMPost); q.sent || !q.failed || q.attempts != 1 {
+        t.Errorf("rejected mail is %+v, want failed after one attempt", q)
+    }
+    var reason string
+    if err := db.DB.QueryRow(`SELECT COALESCE(bounce_reason, '') FROM newsletter_subscribers WHERE email = ? AND bounced_at IS NOT NULL`, "nobody@example.test").Scan(&reason); err != nil {
+        t.Fatalf("address wasn't flagged as bounced: %v", err)
+    }
+    if !strings.Contains(reason, "no such user") {
+        t.Errorf("bounce reason is %q", reason)
+    }
+
+    if err := QueuePost(ctx, "def5678"); err != nil {
+        t.Fatal(err)
+    }
+    if err := m.Run(ctx); err != nil {
+        t.Fatal(err)
+    }
+    var n int
+    if err := db.DB.QueryRow(`
+        SELECT COUNT(*) FROM newsletter_queue q JOIN newsletter_subscribers s ON s.id = q.subscriber_id
+        WHERE s.email = ? AND q.blog_id = ?
+    `, "nobody@example.test", "def5678").Scan(&n); err != nil {
+        t.Fatal(err)
+    }
+    if n != 0 {
+        t.Error("the next post was queued for a bounced address")
+    }
+    if got := r.deliveries(); len(got) != 2 {
+        t.Errorf("relay got %d mails, want both posts for the other reader", len(got))
+    }
+
+    // Signing up again gives the address another chance
+    if _, err := db.DB.Exec(`UPDATE newsletter_subscribers SET requested_at = datetime('now', '-1 hour') WHERE email = ?`, "nobody@example.test"); err != nil {
+        t.Fatal(err)
+    }
+    if err := Subscribe(ctx, "nobody@example.test"); err != nil {
+        t.Fatal(err)
+    }
+    var bounced bool
+    if err := db.DB.QueryRow(`SELECT bounced_at IS NOT NULL FROM newsletter_subscribers WHERE email = ?`, "nobody@example.test").Scan(&bounced); err != nil {
+        t.Fatal(err)
+    }
+    if bounced {
+        t.Error("signing up again didn't clear the bounce")
+    }
+}
+
+func TestRejectedMessageIsNotABounce(t *testing.T) {
+    reset(t)
+    var r = newRelay(t)
+    var m = r.mailer(t, 2)
+    var ctx = context.Background()
+
+    subscriber(t, "reader@example.test")
+    post(t, "abc1234", "A New Post", false)
+    r.rejectData = "554 5.7.1 message refused"
+
+    if err := QueuePost(ctx, "abc1234"); err != nil {
+        t.Fatal(err)
+    }
+    if err := m.Run(ctx); err != nil {
+        t.Fatal(err)
+    }
+
+    if q := queued(t, "reader@example.test", types.NMPost); q.sent || !q.failed || q.attempts != 1 {
+        t.Errorf("refused mail is %+v, want failed after one attempt", q)
+    }
+    // It was the message that got refused, not the recipient
+    var bounced bool
+    if err := db.DB.QueryRow(`SELECT bounced_at IS NOT NULL FROM newsletter_subscribers WHERE email = ?`, "reader@example.test").Scan(&bounced); err != nil {
+        t.Fatal(err)
+    }
+    if bounced {
+        t.Error("address was flagged as bounced for a refused message")
+    }
+}
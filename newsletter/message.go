@@ -0,0 +1,150 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package newsletter
+
+import (
+    "bytes"
+    "context"
+    "crypto/rand"
+    "encoding/hex"
+    "fmt"
+    "mime"
+    "mime/multipart"
+    "mime/quotedprintable"
+    "net/textproto"
+    "net/url"
+    "strings"
+    "time"
+
+    "git.jelius.dev/jelius-sama/Portfolio/template/email"
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+    "github.com/a-h/templ"
+)
+
+// message is a mail before it gets its envelope.
+type message struct {
+    subject string
+    html    templ.Component
+    text    string
+    // unsubscribeURL also goes into the List-Unsubscribe header so mail
+    // clients can offer their own button
+    unsubscribeURL string
+}
+
+func siteURL(path string) string {
+    return strings.TrimSuffix(types.EVHostname.Get().Value, "/") + path
+}
+
+func siteName() string {
+    if u, err := url.Parse(types.EVHostname.Get().Value); err == nil && len(u.Hostname()) != 0 {
+        return u.Hostname()
+    }
+    return types.EVHostname.Get().Value
+}
+
+func confirmMessage(j job) *message {
+    var site = siteName()
+    var confirmURL = siteURL("/newsletter/confirm/" + j.confirmToken.String)
+
+    return &message{
+        subject: "Confirm your subscription to " + site,
+        html:    email.Confirm(email.ConfirmArgs{Site: site, ConfirmURL: confirmURL}),
+        text: fmt.Sprintf(
+            "Someone, hopefully you, asked to get new posts from %s by email.\n"+
+                "Confirm it was you and they will start arriving with the next one:\n\n%s\n\n"+
+                "The link is valid for a week. If you didn't sign up, ignore this mail and you won't hear from us again.\n",
+            site, confirmURL,
+        ),
+    }
+}
+
+func postMessage(j job) *message {
+    var site = siteName()
+    var postURL = siteURL("/blog/" + j.blogID.String)
+    var unsubscribeURL = siteURL("/newsletter/unsubscribe/" + j.unsubscribeToken)
+
+    var text strings.Builder
+    fmt.Fprintf(&text, "New on %s\n\n%s\n\n", site, j.title.String)
+    if len(j.excerpt.String) != 0 {
+        fmt.Fprintf(&text, "%s\n\n", j.excerpt.String)
+    }
+    fmt.Fprintf(&text, "Read the post: %s\n\n-- \nYou get this because you subscribed to %s.\nUnsubscribe: %s\n", postURL, site, unsubscribeURL)
+
+    return &message{
+        subject: j.title.String,
+        html: email.Post(email.PostArgs{
+            Site:           site,
+            Title:          j.title.String,
+            Excerpt:        j.excerpt.String,
+            URL:            postURL,
+            UnsubscribeURL: unsubscribeURL,
+        }),
+        text:           text.String(),
+        unsubscribeURL: unsubscribeURL,
+    }
+}
+
+// encode writes msg out as a multipart/alternative mail to to, the plain
+// text first so clients that can't show HTML pick it.
+func (m *Mailer) encode(ctx context.Context, to string, msg *message) ([]byte, error) {
+    var html bytes.Buffer
+    if err := msg.html.Render(ctx, &html); err != nil {
+        return nil, err
+    }
+
+    var id = make([]byte, 16)
+    if _, err := rand.Read(id); err != nil {
+        return nil, err
+    }
+    var domain = m.from.Address[strings.LastIndexByte(m.from.Address, '@')+1:]
+
+    var body bytes.Buffer
+    var mw = multipart.NewWriter(&body)
+
+    var header bytes.Buffer
+    var set = func(key, value string) {
+        fmt.Fprintf(&header, "%s: %s\r\n", key, value)
+    }
+    set("From", m.from.String())
+    set("To", to)
+    set("Subject", mime.QEncoding.Encode("utf-8", msg.subject))
+    set("Date", time.Now().Format(time.RFC1123Z))
+    set("Message-ID", fmt.Sprintf("<%s@%s>", hex.EncodeToString(id), domain))
+    set("MIME-Version", "1.0")
+    set("Content-Type", "multipart/alternative; boundary="+mw.Boundary())
+    if len(msg.unsubscribeURL) != 0 {
+        set("List-Unsubscribe", "<"+msg.unsubscribeURL+">")
+        set("List-Unsubscribe-Post", "List-Unsubscribe=One-Click")
+    }
+    header.WriteString("\r\n")
+
+    for _, part := range []struct {
+        contentType string
+        content     []byte
+    }{
+        {"text/plain; charset=utf-8", []byte(msg.text)},
+        {"text/html; charset=utf-8", html.Bytes()},
+    } {
+        var pw, err = mw.CreatePart(textproto.MIMEHeader{
+            "Content-Type":              {part.contentType},
+            "Content-Transfer-Encoding": {"quoted-printable"},
+        })
+        if err != nil {
+            return nil, err
+        }
+
+        var qw = quotedprintable.NewWriter(pw)
+        if _, err := qw.Write(part.content); err != nil {
+            return nil, err
+        }
+        if err := qw.Close(); err != nil {
+            return nil, err
+        }
+    }
+    if err := mw.Close(); err != nil {
+        return nil, err
+    }
+
+    return append(header.Bytes(), body.Bytes()...), nil
+}
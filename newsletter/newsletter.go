@@ -0,0 +1,216 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+// Package newsletter keeps the list of email subscribers and mails them new
+// posts.
+//
+// Signing up is double opt-in: an address only starts getting posts once the
+// link in its confirmation mail was opened. Everything that has to be sent
+// goes through a queue in SQLite, which the Mailer works through in the
+// background, retrying relay hiccups and flagging addresses the relay
+// rejects outright as bounced so they are never mailed again.
+package newsletter
+
+import (
+    "context"
+    "crypto/rand"
+    "database/sql"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "net/mail"
+    "strings"
+    "time"
+
+    "git.jelius.dev/jelius-sama/Portfolio/db"
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+)
+
+const (
+    // confirmTTL is how long a confirmation link stays valid
+    confirmTTL = 7 * 24 * time.Hour
+    // resendAfter keeps a signup form from being used to flood an inbox
+    // with confirmation mails
+    resendAfter = 10 * time.Minute
+)
+
+var (
+    ErrInvalidEmail = errors.New("invalid email address")
+    ErrInvalidToken = errors.New("invalid or expired link")
+)
+
+// wake nudges a running Mailer to look at the queue right away instead of
+// waiting for its next poll.
+var wake = make(chan struct{}, 1)
+
+func notify() {
+    select {
+    case wake <- struct{}{}:
+    default:
+    }
+}
+
+func newToken() (string, error) {
+    var b = make([]byte, 24)
+    if _, err := rand.Read(b); err != nil {
+        return "", err
+    }
+    return hex.EncodeToString(b), nil
+}
+
+// NormalizeEmail checks that s is a bare email address (no display name) and
+// returns it trimmed.
+func NormalizeEmail(s string) (string, error) {
+    s = strings.TrimSpace(s)
+    if len(s) == 0 || len(s) > 254 {
+        return "", ErrInvalidEmail
+    }
+
+    var addr, err = mail.ParseAddress(s)
+    if err != nil || addr.Address != s || !strings.Contains(s[strings.LastIndexByte(s, '@')+1:], ".") {
+        return "", ErrInvalidEmail
+    }
+    return s, nil
+}
+
+// Subscribe starts the double opt-in for email by queueing a confirmation
+// mail. Callers get the same answer whether or not the address was already
+// on the list, so the form can't be used to find out who is.
+func Subscribe(ctx context.Context, email string) error {
+    email, err := NormalizeEmail(email)
+    if err != nil {
+        return err
+    }
+
+    confirmToken, err := newToken()
+    if err != nil {
+        return err
+    }
+    unsubscribeToken, err := newToken()
+    if err != nil {
+        return err
+    }
+
+    tx, err := db.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    var id int64
+    var active bool
+    var requestedAt time.Time
+    err = tx.QueryRowContext(ctx, `
+        SELECT id, confirmed_at IS NOT NULL AND unsubscribed_at IS NULL AND bounced_at IS NULL, requested_at
+        FROM newsletter_subscribers WHERE email = ?
+    `, email).Scan(&id, &active, &requestedAt)
+
+    switch {
+    case errors.Is(err, sql.ErrNoRows):
+        var res, err = tx.ExecContext(ctx, `
+            INSERT INTO newsletter_subscribers (email, confirm_token, unsubscribe_token)
+            VALUES (?, ?, ?)
+        `, email, confirmToken, unsubscribeToken)
+        if err != nil {
+            return err
+        }
+        if id, err = res.LastInsertId(); err != nil {
+            return err
+        }
+    case err != nil:
+        return err
+    case active:
+        return nil
+    case time.Since(requestedAt) < resendAfter:
+        return nil
+    default:
+        // Signing up again also gives a bounced address another chance,
+        // someone typed it in after all
+        if _, err := tx.ExecContext(ctx, `
+            UPDATE newsletter_subscribers
+            SET confirm_token = ?, requested_at = datetime('now'), confirmed_at = NULL,
+                unsubscribed_at = NULL, bounced_at = NULL, bounce_reason = NULL
+            WHERE id = ?
+        `, confirmToken, id); err != nil {
+            return err
+        }
+    }
+
+    if _, err := tx.ExecContext(ctx, `
+        INSERT INTO newsletter_queue (kind, subscriber_id) VALUES (?, ?)
+    `, types.NMConfirm, id); err != nil {
+        return err
+    }
+
+    if err := tx.Commit(); err != nil {
+        return err
+    }
+    notify()
+    return nil
+}
+
+// Confirm finishes the double opt-in the confirmation mail with token was
+// sent for.
+func Confirm(ctx context.Context, token string) error {
+    if len(token) == 0 {
+        return ErrInvalidToken
+    }
+
+    var res, err = db.DB.ExecContext(ctx, `
+        UPDATE newsletter_subscribers
+        SET confirmed_at = datetime('now'), confirm_token = NULL
+        WHERE confirm_token = ? AND requested_at > datetime('now', ?)
+    `, token, sqliteOffset(-confirmTTL))
+    if err != nil {
+        return err
+    }
+    if n, err := res.RowsAffected(); err != nil {
+        return err
+    } else if n == 0 {
+        return ErrInvalidToken
+    }
+    return nil
+}
+
+// Unsubscribe takes the address token was issued to off the list. Doing it
+// twice is fine, the links in old mails keep working.
+func Unsubscribe(ctx context.Context, token string) error {
+    if len(token) == 0 {
+        return ErrInvalidToken
+    }
+
+    var res, err = db.DB.ExecContext(ctx, `
+        UPDATE newsletter_subscribers
+        SET unsubscribed_at = COALESCE(unsubscribed_at, datetime('now'))
+        WHERE unsubscribe_token = ?
+    `, token)
+    if err != nil {
+        return err
+    }
+    if n, err := res.RowsAffected(); err != nil {
+        return err
+    } else if n == 0 {
+        return ErrInvalidToken
+    }
+    return nil
+}
+
+// QueuePost queues the announcement of a newly published post for every
+// confirmed subscriber.
+func QueuePost(ctx context.Context, blogID string) error {
+    var _, err = db.DB.ExecContext(ctx, `
+        INSERT OR IGNORE INTO newsletter_queue (kind, subscriber_id, blog_id)
+        SELECT ?, id, ? FROM newsletter_subscribers
+        WHERE confirmed_at IS NOT NULL AND unsubscribed_at IS NULL AND bounced_at IS NULL
+    `, types.NMPost, blogID)
+    if err != nil {
+        return err
+    }
+    notify()
+    return nil
+}
+
+// sqliteOffset formats d as a datetime() modifier.
+func sqliteOffset(d time.Duration) string {
+    return fmt.Sprintf("%+d seconds", int64(d/time.Second))
+}
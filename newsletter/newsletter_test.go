@@ -0,0 +1,407 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package newsletter
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "mime"
+    "mime/multipart"
+    "net"
+    "net/mail"
+    "net/textproto"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+
+    "git.jelius.dev/jelius-sama/Portfolio/db"
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+    "github.com/jelius-sama/logger"
+)
+
+func TestMain(m *testing.M) {
+    var dir, err = os.MkdirTemp("", "newsletter-test")
+    if err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        os.Exit(1)
+    }
+
+    logger.Configure(logger.Cnf{IsDev: logger.IsDev{DirectValue: new(false)}})
+    os.Setenv("HOST_NAME", "https://jelius.test")
+    if err := db.InitDB(filepath.Join(dir, "test.db")); err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        os.Exit(1)
+    }
+
+    var code = m.Run()
+    db.CloseDB()
+    os.RemoveAll(dir)
+    os.Exit(code)
+}
+
+// delivery is a mail the relay accepted.
+type delivery struct {
+    from, to string
+    data     string
+}
+
+// text is the plain text part of the mail.
+func (d delivery) text(t *testing.T) string {
+    t.Helper()
+
+    var msg, err = mail.ReadMessage(strings.NewReader(d.data))
+    if err != nil {
+        t.Fatalf("mail to %s: %v", d.to, err)
+    }
+    var _, params, _ = mime.ParseMediaType(msg.Header.Get("Content-Type"))
+    var part, perr = multipart.NewReader(msg.Body, params["boundary"]).NextPart()
+    if perr != nil {
+        t.Fatalf("mail to %s: %v", d.to, perr)
+    }
+    // NextPart already undoes the quoted-printable
+    var body, _ = io.ReadAll(part)
+    return string(body)
+}
+
+// relay is an in-process SMTP server. Recipients it has a reply for in
+// rcpt are answered with it (and the reply dropped, so the next attempt
+// goes through), everyone else is accepted.
+type relay struct {
+    t  *testing.T
+    ln net.Listener
+
+    mu         sync.Mutex
+    rcpt       map[string][]string
+    delivered  []delivery
+    rejectData string
+}
+
+func newRelay(t *testing.T) *relay {
+    t.Helper()
+
+    var ln, err = net.Listen("tcp", "127.0.0.1:0")
+    if err != nil {
+        t.Fatal(err)
+    }
+    var r = &relay{t: t, ln: ln, rcpt: make(map[string][]string)}
+    t.Cleanup(func() { ln.Close() })
+
+    go func() {
+        for {
+            var conn, err = ln.Accept()
+            if err != nil {
+                return
+            }
+            go r.serve(conn)
+        }
+    }()
+    return r
+}
+
+// reply makes the relay answer the next RCPT TOs for to with replies, one
+// per attempt.
+func (r *relay) reply(to string, replies ...string) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    r.rcpt[to] = append(r.rcpt[to], replies...)
+}
+
+func (r *relay) deliveries() []delivery {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    return append([]delivery(nil), r.delivered...)
+}
+
+func (r *relay) serve(conn net.Conn) {
+    defer conn.Close()
+    conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+    var tp = textproto.NewConn(conn)
+    var from, to string
+    tp.PrintfLine("220 relay.test ESMTP")
+
+    for {
+        var line, err = tp.ReadLine()
+        if err != nil {
+            return
+        }
+        var verb = strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+        var arg = strings.TrimSpace(line[len(verb):])
+
+        switch verb {
+        case "EHLO", "HELO":
+            tp.PrintfLine("250 relay.test")
+        case "MAIL":
+            from = addressOf(arg)
+            tp.PrintfLine("250 2.1.0 OK")
+        case "RCPT":
+            to = addressOf(arg)
+            r.mu.Lock()
+            var replies = r.rcpt[to]
+            if len(replies) != 0 {
+                r.rcpt[to] = replies[1:]
+            }
+            r.mu.Unlock()
+
+            if len(replies) != 0 {
+                tp.PrintfLine("%s", replies[0])
+            } else {
+                tp.PrintfLine("250 2.1.5 OK")
+            }
+        case "DATA":
+            tp.PrintfLine("354 go ahead")
+            var data, err = tp.ReadDotBytes()
+            if err != nil {
+                return
+            }
+
+            r.mu.Lock()
+            var reject = r.rejectData
+            if len(reject) == 0 {
+                r.delivered = append(r.delivered, delivery{from: from, to: to, data: string(data)})
+            }
+            r.mu.Unlock()
+
+            if len(reject) != 0 {
+                tp.PrintfLine("%s", reject)
+            } else {
+                tp.PrintfLine("250 2.0.0 queued")
+            }
+        case "RSET", "NOOP":
+            tp.PrintfLine("250 OK")
+        case "QUIT":
+            tp.PrintfLine("221 bye")
+            return
+        default:
+            tp.PrintfLine("502 5.5.2 unknown command")
+        }
+    }
+}
+
+func addressOf(arg string) string {
+    var start, end = strings.IndexByte(arg, '<'), strings.IndexByte(arg, '>')
+    if start < 0 || end < start {
+        return ""
+    }
+    return arg[start+1 : end]
+}
+
+func (r *relay) mailer(t *testing.T, retries int) *Mailer {
+    t.Helper()
+
+    var _, port, _ = net.SplitHostPort(r.ln.Addr().String())
+    var m, err = New(Config{
+        Host:         "127.0.0.1",
+        Port:         port,
+        From:         "Jelius <newsletter@jelius.test>",
+        Retries:      retries,
+        RetryDelay:   time.Minute,
+        Timeout:      5 * time.Second,
+        PollInterval: time.Hour,
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+    return m
+}
+
+// reset empties the tables the newsletter uses between tests.
+func reset(t *testing.T) {
+    t.Helper()
+    for _, table := range []string{"newsletter_queue", "newsletter_subscribers", "blogs"} {
+        if _, err := db.DB.Exec("DELETE FROM " + table); err != nil {
+            t.Fatal(err)
+        }
+    }
+}
+
+// subscriber signs email up and confirms it, skipping the confirmation mail.
+func subscriber(t *testing.T, email string) {
+    t.Helper()
+
+    var ctx = context.Background()
+    if err := Subscribe(ctx, email); err != nil {
+        t.Fatal(err)
+    }
+    var token string
+    if err := db.DB.QueryRow(`SELECT confirm_token FROM newsletter_subscribers WHERE email = ?`, email).Scan(&token); err != nil {
+        t.Fatal(err)
+    }
+    if err := Confirm(ctx, token); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := db.DB.Exec(`DELETE FROM newsletter_queue WHERE kind = ?`, types.NMConfirm); err != nil {
+        t.Fatal(err)
+    }
+}
+
+func post(t *testing.T, id, title string, draft bool) {
+    t.Helper()
+    if _, err := db.DB.Exec(`INSERT INTO blogs (id, title, excerpt, draft) VALUES (?, ?, ?, ?)`, id, title, "An excerpt", draft); err != nil {
+        t.Fatal(err)
+    }
+}
+
+// queueRow is how a queued mail ended up.
+type queueRow struct {
+    attempts  int
+    sent      bool
+    failed    bool
+    due       bool
+    lastError string
+}
+
+func queued(t *testing.T, email string, kind types.NewsletterMail) queueRow {
+    t.Helper()
+
+    var q queueRow
+    var lastError *string
+    if err := db.DB.QueryRow(`
+        SELECT q.attempts, q.sent_at IS NOT NULL, q.failed_at IS NOT NULL,
+            q.next_attempt_at <= datetime('now'), q.last_error
+        FROM newsletter_queue q JOIN newsletter_subscribers s ON s.id = q.subscriber_id
+        WHERE s.email = ? AND q.kind = ?
+    `, email, kind).Scan(&q.attempts, &q.sent, &q.failed, &q.due, &lastError); err != nil {
+        t.Fatalf("queue row of %s: %v", email, err)
+    }
+    if lastError != nil {
+        q.lastError = *lastError
+    }
+    return q
+}
+
+func TestConfirmationMail(t *testing.T) {
+    reset(t)
+    var r = newRelay(t)
+    var m = r.mailer(t, 0)
+    var ctx = context.Background()
+
+    if err := Subscribe(ctx, "reader@example.test"); err != nil {
+        t.Fatal(err)
+    }
+    // Signing up again right away doesn't mail the address twice
+    if err := Subscribe(ctx, "reader@example.test"); err != nil {
+        t.Fatal(err)
+    }
+    if err := m.Run(ctx); err != nil {
+        t.Fatal(err)
+    }
+
+    var got = r.deliveries()
+    if len(got) != 1 {
+        t.Fatalf("relay got %d mails, want 1", len(got))
+    }
+    if got[0].from != "newsletter@jelius.test" || got[0].to != "reader@example.test" {
+        t.Errorf("envelope is %s -> %s", got[0].from, got[0].to)
+    }
+
+    var token string
+    if err := db.DB.QueryRow(`SELECT confirm_token FROM newsletter_subscribers`).Scan(&token); err != nil {
+        t.Fatal(err)
+    }
+    if text := got[0].text(t); !strings.Contains(text, "https://jelius.test/newsletter/confirm/"+token) {
+        t.Errorf("confirmation mail doesn't carry the confirm link:\n%s", text)
+    }
+    if q := queued(t, "reader@example.test", types.NMConfirm); !q.sent || q.attempts != 1 {
+        t.Errorf("confirmation is %+v, want sent after one attempt", q)
+    }
+
+    if err := Confirm(ctx, token); err != nil {
+        t.Fatal(err)
+    }
+    if err := Confirm(ctx, token); err != ErrInvalidToken {
+        t.Errorf("confirming twice gave %v, want ErrInvalidToken", err)
+    }
+}
+
+func TestPostMail(t *testing.T) {
+    reset(t)
+    var r = newRelay(t)
+    var m = r.mailer(t, 0)
+    var ctx = context.Background()
+
+    subscriber(t, "one@example.test")
+    subscriber(t, "two@example.test")
+    subscriber(t, "gone@example.test")
+    if err := Subscribe(ctx, "pending@example.test"); err != nil {
+        t.Fatal(err)
+    }
+
+    var token string
+    if err := db.DB.QueryRow(`SELECT unsubscribe_token FROM newsletter_subscribers WHERE email = ?`, "gone@example.test").Scan(&token); err != nil {
+        t.Fatal(err)
+    }
+    if err := Unsubscribe(ctx, token); err != nil {
+        t.Fatal(err)
+    }
+
+    post(t, "abc1234", "A New Post", false)
+    if err := QueuePost(ctx, "abc1234"); err != nil {
+        t.Fatal(err)
+    }
+    // Publishing the same post twice doesn't mail it twice
+    if err := QueuePost(ctx, "abc1234"); err != nil {
+        t.Fatal(err)
+    }
+    if err := m.Run(ctx); err != nil {
+        t.Fatal(err)
+    }
+
+    var posts = make(map[string]delivery)
+    for _, d := range r.deliveries() {
+        if strings.Contains(d.data, "Subject: A New Post") {
+            if _, ok := posts[d.to]; ok {
+                t.Errorf("%s got the post twice", d.to)
+            }
+            posts[d.to] = d
+        }
+    }
+    if len(posts) != 2 {
+        t.Fatalf("post went to %d addresses, want the 2 confirmed ones", len(posts))
+    }
+    for _, email := range []string{"one@example.test", "two@example.test"} {
+        var d, ok = posts[email]
+        if !ok {
+            t.Errorf("%s didn't get the post", email)
+            continue
+        }
+        if !strings.Contains(d.text(t), "https://jelius.test/blog/abc1234") {
+            t.Errorf("mail to %s doesn't link the post", email)
+        }
+        if !strings.Contains(d.data, "List-Unsubscribe: <https://jelius.test/newsletter/unsubscribe/") {
+            t.Errorf("mail to %s has no List-Unsubscribe header", email)
+        }
+    }
+}
+
+func TestPostMailSkipsUnpublished(t *testing.T) {
+    reset(t)
+    var r = newRelay(t)
+    var m = r.mailer(t, 0)
+    var ctx = context.Background()
+
+    subscriber(t, "reader@example.test")
+    post(t, "abc1234", "Not Yet", false)
+    if err := QueuePost(ctx, "abc1234"); err != nil {
+        t.Fatal(err)
+    }
+    // Taken back to a draft before the queue got to it
+    if _, err := db.DB.Exec(`UPDATE blogs SET draft = 1 WHERE id = ?`, "abc1234"); err != nil {
+        t.Fatal(err)
+    }
+    if err := m.Run(ctx); err != nil {
+        t.Fatal(err)
+    }
+
+    if got := r.deliveries(); len(got) != 0 {
+        t.Errorf("relay got %d mails for a draft", len(got))
+    }
+    if q := queued(t, "reader@example.test", types.NMPost); q.sent || !q.failed {
+        t.Errorf("mail for a draft is %+v, want it taken off the queue unsent", q)
+    }
+}
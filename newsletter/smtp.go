@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package newsletter
+
+import (
+    "context"
+    "crypto/tls"
+    "errors"
+    "net"
+    "net/smtp"
+    "net/textproto"
+    "net/url"
+    "time"
+
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+)
+
+// deliveryError is a failure the relay answered with. Permanent ones (5xx)
+// are not retried, and when it was the recipient that got refused the
+// address is flagged as bounced.
+type deliveryError struct {
+    err       error
+    permanent bool
+    bounce    bool
+}
+
+func (e *deliveryError) Error() string {
+    return e.err.Error()
+}
+
+func (e *deliveryError) Unwrap() error {
+    return e.err
+}
+
+func classify(err error, recipient bool) error {
+    var tpErr *textproto.Error
+    if errors.As(err, &tpErr) {
+        var permanent = tpErr.Code >= 500
+        return &deliveryError{err: err, permanent: permanent, bounce: permanent && recipient}
+    }
+    return err
+}
+
+// send hands msg for to over to the relay. Port 465 speaks TLS from the
+// start, anything else is upgraded with STARTTLS when the relay offers it.
+func (m *Mailer) send(ctx context.Context, to string, msg *message) error {
+    var data, err = m.encode(ctx, to, msg)
+    if err != nil {
+        return err
+    }
+
+    var addr = net.JoinHostPort(m.cfg.Host, m.cfg.Port)
+    var tlsConfig = &tls.Config{ServerName: m.cfg.Host}
+    var dialer = &net.Dialer{Timeout: m.cfg.Timeout}
+
+    var conn net.Conn
+    if m.cfg.Port == "465" {
+        conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+    } else {
+        conn, err = dialer.DialContext(ctx, "tcp", addr)
+    }
+    if err != nil {
+        return err
+    }
+    conn.SetDeadline(time.Now().Add(m.cfg.Timeout))
+
+    c, err := smtp.NewClient(conn, m.cfg.Host)
+    if err != nil {
+        conn.Close()
+        return err
+    }
+    defer c.Close()
+
+    if u, err := url.Parse(types.EVHostname.Get().Value); err == nil && len(u.Hostname()) != 0 {
+        if err := c.Hello(u.Hostname()); err != nil {
+            return err
+        }
+    }
+
+    if ok, _ := c.Extension("STARTTLS"); ok && m.cfg.Port != "465" {
+        if err := c.StartTLS(tlsConfig); err != nil {
+            return err
+        }
+    }
+
+    if len(m.cfg.Username) != 0 {
+        if err := c.Auth(smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)); err != nil {
+            return err
+        }
+    }
+
+    // A refused sender is a problem with the setup, not with this mail
+    if err := c.Mail(m.from.Address); err != nil {
+        return err
+    }
+    if err := c.Rcpt(to); err != nil {
+        return classify(err, true)
+    }
+
+    w, err := c.Data()
+    if err != nil {
+        return classify(err, false)
+    }
+    if _, err := w.Write(data); err != nil {
+        return err
+    }
+    if err := w.Close(); err != nil {
+        return classify(err, false)
+    }
+
+    return c.Quit()
+}
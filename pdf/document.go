@@ -0,0 +1,320 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+// Package pdf writes PDF documents without any dependency outside the
+// standard library. Document is a small page description layer (text, lines,
+// rectangles, images and links on pages), Render typesets a blog post or a
+// series on top of it.
+package pdf
+
+import (
+    "bufio"
+    "bytes"
+    "compress/zlib"
+    "fmt"
+    "io"
+    "strconv"
+    "strings"
+    "time"
+    "unicode/utf16"
+)
+
+// A4 in points
+const (
+    PageWidth  = 595.28
+    PageHeight = 841.89
+)
+
+type Color struct {
+    R, G, B float64
+}
+
+var (
+    Black = Color{0, 0, 0}
+    White = Color{1, 1, 1}
+)
+
+// Info ends up in the document information dictionary.
+type Info struct {
+    Title    string
+    Author   string
+    Subject  string
+    Keywords string
+    Creator  string
+    Lang     string
+    Created  time.Time
+    Modified time.Time
+}
+
+type Document struct {
+    Info   Info
+    Width  float64
+    Height float64
+    pages  []*Page
+    images []*Image
+}
+
+// Page is drawn in PDF user space: points, origin at the bottom left.
+type Page struct {
+    // Fills go underneath everything else so backgrounds can be drawn
+    // after the text sitting on them has been laid out.
+    background bytes.Buffer
+    content    bytes.Buffer
+    links      []link
+}
+
+type link struct {
+    x, y, w, h float64
+    uri        string
+}
+
+func New(width, height float64) *Document {
+    return &Document{Width: width, Height: height}
+}
+
+func (d *Document) AddPage() *Page {
+    var p = &Page{}
+    d.pages = append(d.pages, p)
+    return p
+}
+
+func (d *Document) Pages() []*Page {
+    return d.pages
+}
+
+// num formats a coordinate compactly, PDF has no use for 15 decimals.
+func num(f float64) string {
+    return strconv.FormatFloat(f, 'f', -1, 32)
+}
+
+func (c Color) fill() string {
+    return num(c.R) + " " + num(c.G) + " " + num(c.B) + " rg"
+}
+
+func (c Color) stroke() string {
+    return num(c.R) + " " + num(c.G) + " " + num(c.B) + " RG"
+}
+
+// Text draws s with its baseline starting at x, y.
+func (p *Page) Text(x, y float64, font Font, size float64, color Color, s string) {
+    if len(s) == 0 {
+        return
+    }
+    fmt.Fprintf(&p.content, "BT /%s %s Tf %s %s %s Td %s Tj ET\n",
+        font.resource(), num(size), color.fill(), num(x), num(y), literal(encode(s)))
+}
+
+func (p *Page) Line(x1, y1, x2, y2, width float64, color Color) {
+    fmt.Fprintf(&p.content, "q %s %s w %s %s m %s %s l S Q\n",
+        color.stroke(), num(width), num(x1), num(y1), num(x2), num(y2))
+}
+
+// Rect fills a rectangle underneath everything else on the page.
+func (p *Page) Rect(x, y, w, h float64, color Color) {
+    fmt.Fprintf(&p.background, "q %s %s %s %s %s re f Q\n",
+        color.fill(), num(x), num(y), num(w), num(h))
+}
+
+// Image draws img stretched over the rectangle at x, y.
+func (p *Page) Image(img *Image, x, y, w, h float64) {
+    fmt.Fprintf(&p.content, "q %s 0 0 %s %s %s cm /%s Do Q\n", num(w), num(h), num(x), num(y), img.resource())
+}
+
+// Link makes the rectangle at x, y open uri when clicked.
+func (p *Page) Link(x, y, w, h float64, uri string) {
+    p.links = append(p.links, link{x, y, w, h, uri})
+}
+
+// literal writes b as a PDF string literal.
+func literal(b []byte) string {
+    var sb strings.Builder
+    sb.WriteByte('(')
+    for _, c := range b {
+        switch c {
+        case '(', ')', '\\':
+            sb.WriteByte('\\')
+            sb.WriteByte(c)
+        default:
+            sb.WriteByte(c)
+        }
+    }
+    sb.WriteByte(')')
+    return sb.String()
+}
+
+// textString encodes s for the info dictionary and annotations, which unlike
+// page content can carry any Unicode as UTF-16BE.
+func textString(s string) string {
+    var sb strings.Builder
+    sb.WriteString("<FEFF")
+    for _, u := range utf16.Encode([]rune(s)) {
+        fmt.Fprintf(&sb, "%04X", u)
+    }
+    sb.WriteByte('>')
+    return sb.String()
+}
+
+func date(t time.Time) string {
+    return literal([]byte(t.UTC().Format("D:20060102150405Z")))
+}
+
+// writer numbers objects and remembers where each one starts for the xref table.
+type writer struct {
+    w       *bufio.Writer
+    n       int64
+    offsets []int64
+    err     error
+}
+
+func (w *writer) printf(format string, args ...any) {
+    if w.err != nil {
+        return
+    }
+    var n int
+    n, w.err = fmt.Fprintf(w.w, format, args...)
+    w.n += int64(n)
+}
+
+func (w *writer) write(b []byte) {
+    if w.err != nil {
+        return
+    }
+    var n int
+    n, w.err = w.w.Write(b)
+    w.n += int64(n)
+}
+
+// reserve hands out the next object number.
+func (w *writer) reserve() int {
+    w.offsets = append(w.offsets, 0)
+    return len(w.offsets)
+}
+
+func (w *writer) begin(id int) {
+    w.offsets[id-1] = w.n
+    w.printf("%d 0 obj\n", id)
+}
+
+func (w *writer) object(id int, body string) {
+    w.begin(id)
+    w.printf("%s\nendobj\n", body)
+}
+
+// stream writes a deflated stream object.
+func (w *writer) stream(id int, dict string, data []byte) {
+    var buf bytes.Buffer
+    var zw = zlib.NewWriter(&buf)
+    zw.Write(data)
+    zw.Close()
+
+    w.begin(id)
+    w.printf("<< %s /Filter /FlateDecode /Length %d >>\nstream\n", dict, buf.Len())
+    w.write(buf.Bytes())
+    w.printf("\nendstream\nendobj\n")
+}
+
+// WriteTo serializes the document.
+func (d *Document) WriteTo(out io.Writer) (int64, error) {
+    var w = &writer{w: bufio.NewWriter(out)}
+    w.printf("%%PDF-1.7\n%%\xE2\xE3\xCF\xD3\n")
+
+    var catalogID = w.reserve()
+    var pagesID = w.reserve()
+    var infoID = w.reserve()
+
+    var fontIDs = make(map[Font]int)
+    for _, f := range allFonts {
+        fontIDs[f] = w.reserve()
+        w.object(fontIDs[f], fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", f))
+    }
+
+    for _, img := range d.images {
+        img.write(w)
+    }
+
+    var resources strings.Builder
+    resources.WriteString("<< /Font <<")
+    for _, f := range allFonts {
+        fmt.Fprintf(&resources, " /%s %d 0 R", f.resource(), fontIDs[f])
+    }
+    resources.WriteString(" >>")
+    if len(d.images) != 0 {
+        resources.WriteString(" /XObject <<")
+        for _, img := range d.images {
+            fmt.Fprintf(&resources, " /%s %d 0 R", img.resource(), img.id)
+        }
+        resources.WriteString(" >>")
+    }
+    resources.WriteString(" /ProcSet [/PDF /Text /ImageB /ImageC] >>")
+    var resourcesID = w.reserve()
+    w.object(resourcesID, resources.String())
+
+    var kids []string
+    for _, p := range d.pages {
+        var contentID = w.reserve()
+        w.stream(contentID, "", bytes.Join([][]byte{p.background.Bytes(), p.content.Bytes()}, nil))
+
+        var annots []string
+        for _, l := range p.links {
+            var id = w.reserve()
+            w.object(id, fmt.Sprintf(
+                "<< /Type /Annot /Subtype /Link /Rect [%s %s %s %s] /Border [0 0 0] /A << /S /URI /URI %s >> >>",
+                num(l.x), num(l.y), num(l.x+l.w), num(l.y+l.h), literal([]byte(l.uri)),
+            ))
+            annots = append(annots, fmt.Sprintf("%d 0 R", id))
+        }
+
+        var pageID = w.reserve()
+        var dict = fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources %d 0 R /Contents %d 0 R",
+            pagesID, num(d.Width), num(d.Height), resourcesID, contentID)
+        if len(annots) != 0 {
+            dict += " /Annots [" + strings.Join(annots, " ") + "]"
+        }
+        w.object(pageID, dict+" >>")
+        kids = append(kids, fmt.Sprintf("%d 0 R", pageID))
+    }
+
+    w.object(pagesID, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids)))
+
+    var catalog = fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R", pagesID)
+    if len(d.Info.Lang) != 0 {
+        catalog += " /Lang " + textString(d.Info.Lang)
+    }
+    if len(d.Info.Title) != 0 {
+        catalog += " /ViewerPreferences << /DisplayDocTitle true >>"
+    }
+    w.object(catalogID, catalog+" >>")
+
+    var info = []string{"/Producer " + textString("jelius.dev")}
+    for _, field := range []struct{ key, value string }{
+        {"Title", d.Info.Title},
+        {"Author", d.Info.Author},
+        {"Subject", d.Info.Subject},
+        {"Keywords", d.Info.Keywords},
+        {"Creator", d.Info.Creator},
+    } {
+        if len(field.value) != 0 {
+            info = append(info, "/"+field.key+" "+textString(field.value))
+        }
+    }
+    if !d.Info.Created.IsZero() {
+        info = append(info, "/CreationDate "+date(d.Info.Created))
+    }
+    if !d.Info.Modified.IsZero() {
+        info = append(info, "/ModDate "+date(d.Info.Modified))
+    }
+    w.object(infoID, "<< "+strings.Join(info, " ")+" >>")
+
+    var xref = w.n
+    w.printf("xref\n0 %d\n0000000000 65535 f \n", len(w.offsets)+1)
+    for _, off := range w.offsets {
+        w.printf("%010d 00000 n \n", off)
+    }
+    w.printf("trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+        len(w.offsets)+1, catalogID, infoID, xref)
+
+    if w.err != nil {
+        return w.n, w.err
+    }
+    return w.n, w.w.Flush()
+}
@@ -0,0 +1,194 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package pdf
+
+import (
+    "errors"
+    "unicode"
+)
+
+// ErrUnsupportedText is returned by Render for a book written in a script the
+// standard fonts can't set.
+var ErrUnsupportedText = errors.New("text can't be set in the standard PDF fonts")
+
+// Font is one of the standard Type 1 fonts every PDF reader ships with, so
+// nothing has to be embedded. The catch is that text is limited to what
+// WinAnsiEncoding (roughly Windows-1252) can express.
+type Font uint8
+
+const (
+    FontRegular Font = iota
+    FontBold
+    FontItalic
+    FontBoldItalic
+    FontMono
+    FontMonoBold
+)
+
+var allFonts = []Font{FontRegular, FontBold, FontItalic, FontBoldItalic, FontMono, FontMonoBold}
+
+func (f Font) String() string {
+    switch f {
+    case FontRegular:
+        return "Helvetica"
+    case FontBold:
+        return "Helvetica-Bold"
+    case FontItalic:
+        return "Helvetica-Oblique"
+    case FontBoldItalic:
+        return "Helvetica-BoldOblique"
+    case FontMono:
+        return "Courier"
+    case FontMonoBold:
+        return "Courier-Bold"
+    default:
+        return ""
+    }
+}
+
+// resource is the name the font goes by in a page's resource dictionary.
+func (f Font) resource() string {
+    return string([]byte{'F', '1' + byte(f)})
+}
+
+func (f Font) IsMono() bool {
+    return f == FontMono || f == FontMonoBold
+}
+
+func (f Font) IsBold() bool {
+    return f == FontBold || f == FontBoldItalic || f == FontMonoBold
+}
+
+// Width returns the width of s set in f at size points.
+func (f Font) Width(s string, size float64) float64 {
+    var total int
+    for _, b := range encode(s) {
+        total += f.glyphWidth(b)
+    }
+    return float64(total) * size / 1000
+}
+
+func (f Font) glyphWidth(b byte) int {
+    if f.IsMono() {
+        return 600
+    }
+
+    var widths = &helveticaWidths
+    if f.IsBold() {
+        widths = &helveticaBoldWidths
+    }
+
+    switch {
+    case b >= 32 && b <= 126:
+        return int(widths[b-32])
+    case b >= 0xC0:
+        // Accented letters are as wide as the letter they are built on
+        if base := latin1Base[b-0xC0]; base != 0 {
+            return int(widths[base-32])
+        }
+    }
+
+    switch b {
+    case 0x95: // bullet
+        return 350
+    case 0x96: // en dash
+        return 556
+    case 0x97, 0x85, 0x89: // em dash, ellipsis, per mille
+        return 1000
+    case 0x91, 0x92, 0x82: // single quotes
+        return 222
+    case 0x93, 0x94, 0x84: // double quotes
+        return 333
+    case 0xA0: // no-break space
+        return 278
+    }
+    return 556
+}
+
+// helveticaWidths and helveticaBoldWidths hold the advance widths of the
+// printable ASCII range (32-126), in 1/1000 em, from the Adobe AFM files.
+// The oblique cuts share the widths of their upright counterparts.
+var helveticaWidths = [95]uint16{
+    278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+    556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+    1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+    667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+    333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+    556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]uint16{
+    278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+    556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+    975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+    667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+    333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+    611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
+
+// latin1Base maps 0xC0-0xFF to the ASCII letter each accented form is built
+// on, or 0 for the few that aren't.
+var latin1Base = [64]byte{
+    'A', 'A', 'A', 'A', 'A', 'A', 0, 'C', 'E', 'E', 'E', 'E', 'I', 'I', 'I', 'I',
+    'D', 'N', 'O', 'O', 'O', 'O', 'O', 0, 'O', 'U', 'U', 'U', 'U', 'Y', 'P', 0,
+    'a', 'a', 'a', 'a', 'a', 'a', 0, 'c', 'e', 'e', 'e', 'e', 'i', 'i', 'i', 'i',
+    'o', 'n', 'o', 'o', 'o', 'o', 'o', 0, 'o', 'u', 'u', 'u', 'u', 'y', 'p', 'y',
+}
+
+// winAnsiSpecials covers the part of WinAnsiEncoding (0x80-0x9F) that
+// differs from Latin-1.
+var winAnsiSpecials = map[rune]byte{
+    '€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+    'ˆ': 0x88, '‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91,
+    '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98,
+    '™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// encodable reports whether every character of s is in WinAnsiEncoding.
+func encodable(s string) bool {
+    for _, r := range s {
+        if _, ok := winAnsiSpecials[r]; !ok && (r < 32 || r > 126) && (r < 0xA0 || r > 0xFF) {
+            return false
+        }
+    }
+    return true
+}
+
+// settable reports whether the standard fonts can set s well enough to read.
+// A few letters outside WinAnsiEncoding, like the ř of a name, are fine; text
+// in another script would come out as a page of '?'.
+func settable(s string) bool {
+    var letters, missing int
+    for _, r := range s {
+        if !unicode.IsLetter(r) {
+            continue
+        }
+        letters++
+        if !encodable(string(r)) {
+            missing++
+        }
+    }
+    return missing*10 <= letters
+}
+
+// encode converts s to WinAnsiEncoding. Characters it can't express come out
+// as '?'.
+func encode(s string) []byte {
+    var out = make([]byte, 0, len(s))
+    for _, r := range s {
+        switch {
+        case r >= 32 && r <= 126, r >= 0xA0 && r <= 0xFF:
+            out = append(out, byte(r))
+        case r == '\t':
+            out = append(out, ' ')
+        default:
+            if b, ok := winAnsiSpecials[r]; ok {
+                out = append(out, b)
+            } else {
+                out = append(out, '?')
+            }
+        }
+    }
+    return out
+}
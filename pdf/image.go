@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package pdf
+
+import (
+    "bytes"
+    "errors"
+    "fmt"
+    "image"
+    "image/color"
+    "image/draw"
+    "strconv"
+
+    _ "image/gif"
+    _ "image/jpeg"
+    _ "image/png"
+)
+
+var ErrUnsupportedImage = errors.New("unsupported image format")
+
+// Image is an image XObject. Width and Height are in pixels.
+type Image struct {
+    Width  int
+    Height int
+
+    index int
+    id    int
+    // JPEGs are passed through as they are, everything else is stored as
+    // deflated 8-bit RGB samples with an optional soft mask for transparency.
+    jpeg       []byte
+    colorSpace string
+    samples    []byte
+    alpha      []byte
+}
+
+func (img *Image) resource() string {
+    return "Im" + strconv.Itoa(img.index+1)
+}
+
+// AddImage decodes a JPEG, PNG or GIF so it can be drawn on any page.
+func (d *Document) AddImage(data []byte) (*Image, error) {
+    var cfg, format, err = image.DecodeConfig(bytes.NewReader(data))
+    if err != nil {
+        if errors.Is(err, image.ErrFormat) {
+            return nil, ErrUnsupportedImage
+        }
+        return nil, err
+    }
+
+    var img = &Image{Width: cfg.Width, Height: cfg.Height, index: len(d.images)}
+
+    // Gray and YCbCr JPEGs can be embedded without decoding them. CMYK ones
+    // take the slow path since Adobe writes their samples inverted.
+    if format == "jpeg" && cfg.ColorModel != color.CMYKModel {
+        img.jpeg = data
+        img.colorSpace = "/DeviceRGB"
+        if cfg.ColorModel == color.GrayModel {
+            img.colorSpace = "/DeviceGray"
+        }
+        d.images = append(d.images, img)
+        return img, nil
+    }
+
+    decoded, _, err := image.Decode(bytes.NewReader(data))
+    if err != nil {
+        return nil, err
+    }
+
+    var bounds = decoded.Bounds()
+    var nrgba = image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+    draw.Draw(nrgba, nrgba.Bounds(), decoded, bounds.Min, draw.Src)
+
+    var pixels = bounds.Dx() * bounds.Dy()
+    var alpha = make([]byte, 0, pixels)
+    var opaque = true
+
+    img.colorSpace = "/DeviceRGB"
+    img.samples = make([]byte, 0, pixels*3)
+    for i := 0; i < len(nrgba.Pix); i += 4 {
+        img.samples = append(img.samples, nrgba.Pix[i], nrgba.Pix[i+1], nrgba.Pix[i+2])
+        alpha = append(alpha, nrgba.Pix[i+3])
+        opaque = opaque && nrgba.Pix[i+3] == 0xFF
+    }
+    if !opaque {
+        img.alpha = alpha
+    }
+
+    d.images = append(d.images, img)
+    return img, nil
+}
+
+func (img *Image) write(w *writer) {
+    var smask string
+    if img.alpha != nil {
+        var maskID = w.reserve()
+        w.stream(maskID, fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceGray /BitsPerComponent 8",
+            img.Width, img.Height), img.alpha)
+        smask = fmt.Sprintf(" /SMask %d 0 R", maskID)
+    }
+
+    img.id = w.reserve()
+    var dict = fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace %s /BitsPerComponent 8%s",
+        img.Width, img.Height, img.colorSpace, smask)
+
+    if img.jpeg == nil {
+        w.stream(img.id, dict, img.samples)
+        return
+    }
+
+    w.begin(img.id)
+    w.printf("<< %s /Filter /DCTDecode /Length %d >>\nstream\n", dict, len(img.jpeg))
+    w.write(img.jpeg)
+    w.printf("\nendstream\nendobj\n")
+}
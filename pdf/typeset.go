@@ -0,0 +1,993 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package pdf
+
+import (
+    "fmt"
+    "io"
+    "net/url"
+    "strings"
+
+    "git.jelius.dev/jelius-sama/Portfolio/markdown"
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+)
+
+const (
+    marginX      = 64.0
+    marginTop    = 72.0
+    marginBottom = 72.0
+
+    bodySize    = 11.0
+    bodyLeading = bodySize * 1.45
+    codeSize    = 9.0
+    codeLeading = codeSize * 1.45
+    tableSize   = 10.0
+    captionSize = 9.0
+
+    blockGap = 8.0
+    listGap  = 3.0
+)
+
+var headingSizes = [7]float64{0, 22, 18, 15, 13, 12, 11}
+
+var (
+    textColor       = Color{0.13, 0.13, 0.14}
+    mutedColor      = Color{0.42, 0.42, 0.46}
+    linkColor       = Color{0.1, 0.36, 0.75}
+    ruleColor       = Color{0.82, 0.82, 0.85}
+    codeBackground  = Color{0.955, 0.955, 0.965}
+    tableBackground = Color{0.93, 0.93, 0.95}
+)
+
+// alerts maps the GitHub alert kinds to their label and accent color.
+var alerts = map[string]struct {
+    label string
+    color Color
+}{
+    "note":      {"Note", Color{0.12, 0.44, 0.85}},
+    "info":      {"Info", Color{0.12, 0.44, 0.85}},
+    "tip":       {"Tip", Color{0.16, 0.6, 0.3}},
+    "important": {"Important", Color{0.5, 0.32, 0.85}},
+    "warning":   {"Warning", Color{0.75, 0.5, 0.05}},
+    "caution":   {"Caution", Color{0.82, 0.2, 0.2}},
+    "danger":    {"Danger", Color{0.82, 0.2, 0.2}},
+}
+
+type Options struct {
+    // LoadImage returns the bytes of the image an `![alt](src)` points to.
+    // Images that can't be loaded or decoded are replaced by their alt text.
+    LoadImage func(src string) ([]byte, error)
+}
+
+// Render typesets book as an A4 PDF: a title page, then every chapter
+// starting on a page of its own.
+//
+// Chapters are read with markdown.Parse, not the react-markdown pipeline the
+// site renders posts with, so the two can disagree on corner cases of the
+// syntax. Math goes through markdown.RenderMath like it does on the site and
+// is set as plain text (see markdown.MathText); an expression the PDF fonts
+// can't spell out is set as its TeX source.
+//
+// Only the standard fonts are available, so a book written mostly in a script
+// they don't cover fails with ErrUnsupportedText before anything is typeset.
+func Render(w io.Writer, book *types.ExportBook, opts Options) error {
+    var text = []string{book.Title}
+    for _, chapter := range book.Chapters {
+        text = append(text, chapter.Title, chapter.Markdown)
+    }
+    if !settable(strings.Join(text, "\n")) {
+        return ErrUnsupportedText
+    }
+
+    var doc = New(PageWidth, PageHeight)
+    doc.Info = Info{
+        Title:    book.Title,
+        Author:   book.Author,
+        Subject:  book.Description,
+        Creator:  book.Site,
+        Lang:     book.Lang,
+        Created:  book.Published,
+        Modified: book.Updated,
+    }
+
+    var t = &typesetter{
+        doc:     doc,
+        opts:    opts,
+        images:  make(map[string]*Image),
+        left:    marginX,
+        right:   PageWidth - marginX,
+        spacing: blockGap,
+        color:   textColor,
+    }
+
+    t.titlePage(book)
+    for i := range book.Chapters {
+        var chapter = &book.Chapters[i]
+        t.newPage()
+        t.base, _ = url.Parse(chapter.URL)
+        if len(book.Chapters) > 1 {
+            t.chapterHeading(i+1, chapter)
+        }
+
+        var src, math, _ = markdown.RenderMath(chapter.Markdown)
+        t.math = math
+        t.blocks(markdown.Parse(src).Blocks)
+    }
+    t.footers(book.Title)
+
+    var _, err = doc.WriteTo(w)
+    return err
+}
+
+type typesetter struct {
+    doc    *Document
+    opts   Options
+    page   *Page
+    images map[string]*Image
+    // base resolves relative links of the chapter being set
+    base *url.URL
+    // math maps the placeholders markdown.RenderMath left in the chapter
+    // being set to their MathML
+    math map[string]string
+
+    // y is the top of the space left on the page, left and right bound the
+    // current text column.
+    y, left, right float64
+    spacing        float64
+    color          Color
+
+    decorations []*decoration
+    markers     []marker
+}
+
+// decoration is something drawn alongside a block that may span pages, like
+// the background of a code block. It is drawn once per page the block is on.
+type decoration struct {
+    draw func(p *Page, top, bottom float64)
+    top  float64
+}
+
+// marker is a list item bullet waiting for the first line of its item.
+type marker struct {
+    text string
+    font Font
+    x    float64
+}
+
+func (t *typesetter) width() float64 {
+    return t.right - t.left
+}
+
+func (t *typesetter) atPageTop() bool {
+    return t.y >= t.doc.Height-marginTop
+}
+
+func (t *typesetter) newPage() {
+    if t.page != nil {
+        for _, d := range t.decorations {
+            d.draw(t.page, d.top, t.y)
+        }
+    }
+    t.page = t.doc.AddPage()
+    t.y = t.doc.Height - marginTop
+    for _, d := range t.decorations {
+        d.top = t.y
+    }
+}
+
+// ensure starts a new page unless h more points fit on this one.
+func (t *typesetter) ensure(h float64) {
+    if t.y-h < marginBottom && !t.atPageTop() {
+        t.newPage()
+    }
+}
+
+func (t *typesetter) gap(h float64) {
+    if !t.atPageTop() {
+        t.y = max(t.y-h, marginBottom)
+    }
+}
+
+func (t *typesetter) begin(draw func(p *Page, top, bottom float64)) *decoration {
+    var d = &decoration{draw: draw, top: t.y}
+    t.decorations = append(t.decorations, d)
+    return d
+}
+
+func (t *typesetter) end(d *decoration) {
+    d.draw(t.page, d.top, t.y)
+    t.decorations = t.decorations[:len(t.decorations)-1]
+}
+
+// advance takes a line of height h for text of the given size and returns
+// its baseline, drawing any list markers still waiting for a line.
+func (t *typesetter) advance(h, size float64) float64 {
+    t.ensure(h)
+    var baseline = t.y - (h-size)/2 - size*0.8
+    t.drawMarkers(baseline)
+    t.y -= h
+    return baseline
+}
+
+func (t *typesetter) drawMarkers(baseline float64) {
+    for _, m := range t.markers {
+        t.page.Text(m.x, baseline, m.font, bodySize, textColor, m.text)
+    }
+    t.markers = t.markers[:0]
+}
+
+// style is the inline formatting in effect while flattening inlines.
+type style struct {
+    bold, italic, mono, strike bool
+    size                       float64
+    color                      Color
+    uri                        string
+}
+
+func (s style) font() Font {
+    switch {
+    case s.mono && s.bold:
+        return FontMonoBold
+    case s.mono:
+        return FontMono
+    case s.bold && s.italic:
+        return FontBoldItalic
+    case s.bold:
+        return FontBold
+    case s.italic:
+        return FontItalic
+    default:
+        return FontRegular
+    }
+}
+
+func (t *typesetter) bodyStyle() style {
+    return style{size: bodySize, color: t.color}
+}
+
+type segment struct {
+    text   string
+    font   Font
+    size   float64
+    color  Color
+    uri    string
+    strike bool
+}
+
+func (s segment) width() float64 {
+    return s.font.Width(s.text, s.size)
+}
+
+// word is a run of segments with no space in between, the unit lines are
+// broken at. A hard line break is a word of its own.
+type word struct {
+    segs   []segment
+    width  float64
+    space  float64 // width of the space in front of it
+    spaced bool
+    brk    bool
+}
+
+type line struct {
+    words []word
+    width float64
+}
+
+type wordBuilder struct {
+    words  []word
+    cur    word
+    spaced bool
+}
+
+func (b *wordBuilder) flush() {
+    if len(b.cur.segs) == 0 {
+        return
+    }
+    for _, s := range b.cur.segs {
+        b.cur.width += s.width()
+    }
+    var first = b.cur.segs[0]
+    b.cur.space = first.font.Width(" ", first.size)
+    b.words = append(b.words, b.cur)
+    b.cur = word{}
+}
+
+func (b *wordBuilder) text(s string, st style) {
+    s = strings.NewReplacer("\n", " ", "\t", " ").Replace(s)
+    for len(s) != 0 {
+        if s[0] == ' ' {
+            b.flush()
+            b.spaced = true
+            s = s[1:]
+            continue
+        }
+
+        var end = strings.IndexByte(s, ' ')
+        if end < 0 {
+            end = len(s)
+        }
+        if len(b.cur.segs) == 0 {
+            b.cur.spaced = b.spaced
+            b.spaced = false
+        }
+        b.cur.segs = append(b.cur.segs, segment{
+            text: s[:end], font: st.font(), size: st.size, color: st.color, uri: st.uri, strike: st.strike,
+        })
+        s = s[end:]
+    }
+}
+
+func (b *wordBuilder) lineBreak() {
+    b.flush()
+    b.words = append(b.words, word{brk: true})
+    b.spaced = false
+}
+
+// words flattens inlines into words set in st and whatever formatting the
+// inlines add on top of it.
+func (t *typesetter) words(inlines []markdown.Inline, st style) []word {
+    var b wordBuilder
+    var walk func(inlines []markdown.Inline, st style)
+    walk = func(inlines []markdown.Inline, st style) {
+        for _, in := range inlines {
+            var inner = st
+            switch in.Kind {
+            case markdown.IKText:
+                b.text(in.Text, st)
+            case markdown.IKCode:
+                if mathml, ok := t.math[in.Text]; ok {
+                    var text, tex = markdown.MathText(mathml)
+                    if encodable(text) {
+                        inner.italic = true
+                        b.text(text, inner)
+                        break
+                    }
+                    in.Text = tex
+                }
+                inner.mono = true
+                b.text(in.Text, inner)
+            case markdown.IKEmphasis:
+                inner.italic = true
+                walk(in.Children, inner)
+            case markdown.IKStrong:
+                inner.bold = true
+                walk(in.Children, inner)
+            case markdown.IKStrike:
+                inner.strike = true
+                walk(in.Children, inner)
+            case markdown.IKLink:
+                inner.uri = t.resolve(in.URL)
+                inner.color = linkColor
+                walk(in.Children, inner)
+            case markdown.IKImage:
+                // Images inside running text can't be placed sensibly,
+                // their alt text stands in for them.
+                if alt := markdown.PlainText(in.Children); len(alt) != 0 {
+                    inner.italic = true
+                    b.text(alt, inner)
+                }
+            case markdown.IKLineBreak:
+                b.lineBreak()
+            }
+        }
+    }
+    walk(inlines, st)
+    b.flush()
+    return b.words
+}
+
+// plainWords is words for text that has no markup.
+func plainWords(s string, st style) []word {
+    var b wordBuilder
+    b.text(s, st)
+    b.flush()
+    return b.words
+}
+
+// resolve turns a link target into an absolute URL, or "" for links within
+// the page, which go nowhere on paper.
+func (t *typesetter) resolve(href string) string {
+    if len(href) == 0 || href[0] == '#' {
+        return ""
+    }
+    var u, err = url.Parse(href)
+    if err != nil {
+        return ""
+    }
+    if t.base != nil {
+        u = t.base.ResolveReference(u)
+    }
+    if !u.IsAbs() {
+        return ""
+    }
+    return u.String()
+}
+
+// wrap breaks words into lines no wider than width. Words that don't fit on
+// a line of their own are split wherever they overflow.
+func wrap(words []word, width float64) []line {
+    var lines []line
+    var cur line
+
+    var push = func(w word) {
+        var gap float64
+        if len(cur.words) != 0 && w.spaced {
+            gap = w.space
+        }
+        if len(cur.words) != 0 && cur.width+gap+w.width > width {
+            lines = append(lines, cur)
+            cur, gap = line{}, 0
+        }
+        w.space = gap
+        cur.words = append(cur.words, w)
+        cur.width += gap + w.width
+    }
+
+    for _, w := range words {
+        if w.brk {
+            lines = append(lines, cur)
+            cur = line{}
+            continue
+        }
+        if w.width <= width {
+            push(w)
+            continue
+        }
+        for i, part := range splitWord(w, width) {
+            if i != 0 {
+                lines = append(lines, cur)
+                cur = line{}
+            }
+            push(part)
+        }
+    }
+    if len(cur.words) != 0 {
+        lines = append(lines, cur)
+    }
+    return lines
+}
+
+// splitWord cuts w into pieces no wider than width, between characters.
+func splitWord(w word, width float64) []word {
+    var parts []word
+    var cur = word{spaced: w.spaced, space: w.space}
+
+    for _, seg := range w.segs {
+        var start int
+        var segWidth float64
+        for i, r := range seg.text {
+            var rw = seg.font.Width(string(r), seg.size)
+            if cur.width+segWidth+rw > width && (cur.width+segWidth) > 0 {
+                if i > start {
+                    var piece = seg
+                    piece.text = seg.text[start:i]
+                    cur.segs = append(cur.segs, piece)
+                }
+                cur.width += segWidth
+                parts = append(parts, cur)
+                cur = word{}
+                start, segWidth = i, 0
+            }
+            segWidth += rw
+        }
+        if start < len(seg.text) {
+            var piece = seg
+            piece.text = seg.text[start:]
+            cur.segs = append(cur.segs, piece)
+            cur.width += segWidth
+        }
+    }
+    if len(cur.segs) != 0 {
+        parts = append(parts, cur)
+    }
+    return parts
+}
+
+func (t *typesetter) drawLine(l line, x, baseline float64) {
+    // Neighbouring segments set alike are drawn in one go, spaces included
+    var runs []segment
+    var starts []float64
+    for _, w := range l.words {
+        for i, seg := range w.segs {
+            var gap float64
+            if i == 0 {
+                gap = w.space
+            }
+            if n := len(runs); n != 0 {
+                var last = &runs[n-1]
+                if last.font == seg.font && last.size == seg.size && last.color == seg.color &&
+                    last.uri == seg.uri && last.strike == seg.strike &&
+                    (gap == 0 || gap == seg.font.Width(" ", seg.size)) {
+                    if gap != 0 {
+                        last.text += " "
+                    }
+                    last.text += seg.text
+                    x += gap + seg.width()
+                    continue
+                }
+            }
+            x += gap
+            runs = append(runs, seg)
+            starts = append(starts, x)
+            x += seg.width()
+        }
+    }
+
+    for i, run := range runs {
+        var x, w = starts[i], run.width()
+        t.page.Text(x, baseline, run.font, run.size, run.color, run.text)
+        if run.strike {
+            t.page.Line(x, baseline+run.size*0.3, x+w, baseline+run.size*0.3, run.size*0.07, run.color)
+        }
+        if len(run.uri) != 0 {
+            t.page.Link(x, baseline-run.size*0.25, w, run.size*1.2, run.uri)
+        }
+    }
+}
+
+// text sets words as a left aligned paragraph in the current column.
+func (t *typesetter) text(words []word, size, leading float64) {
+    for _, l := range wrap(words, t.width()) {
+        t.drawLine(l, t.left, t.advance(leading, size))
+    }
+}
+
+// centered sets words centered in the current column.
+func (t *typesetter) centered(words []word, size, leading float64) {
+    for _, l := range wrap(words, t.width()) {
+        t.drawLine(l, t.left+(t.width()-l.width)/2, t.advance(leading, size))
+    }
+}
+
+func (t *typesetter) blocks(blocks []*markdown.Block) {
+    for i, b := range blocks {
+        if i != 0 {
+            t.gap(t.spacing)
+        }
+        t.block(b)
+    }
+}
+
+func (t *typesetter) block(b *markdown.Block) {
+    switch b.Kind {
+    case markdown.BKParagraph:
+        if images, ok := onlyImages(b.Inlines); ok {
+            for i, img := range images {
+                if i != 0 {
+                    t.gap(t.spacing)
+                }
+                t.image(img)
+            }
+            return
+        }
+        if t.displayMath(b.Inlines) {
+            t.centered(t.words(b.Inlines, t.bodyStyle()), bodySize, bodyLeading)
+            return
+        }
+        t.text(t.words(b.Inlines, t.bodyStyle()), bodySize, bodyLeading)
+    case markdown.BKHeading:
+        t.heading(b)
+    case markdown.BKCodeBlock:
+        t.code(b.Literal)
+    case markdown.BKQuote:
+        t.quote(b)
+    case markdown.BKList:
+        t.list(b)
+    case markdown.BKRule:
+        t.rule()
+    case markdown.BKTable:
+        t.table(b)
+    case markdown.BKHTML:
+        // Raw HTML means nothing on paper
+    }
+}
+
+// displayMath reports whether a paragraph is a `$$display$$` expression on
+// its own, which is centered like it is on the site.
+func (t *typesetter) displayMath(inlines []markdown.Inline) bool {
+    if len(inlines) != 1 || inlines[0].Kind != markdown.IKCode {
+        return false
+    }
+    var mathml, ok = t.math[inlines[0].Text]
+    return ok && strings.Contains(mathml, `display="block"`)
+}
+
+// onlyImages reports whether a paragraph holds nothing but images, which are
+// then set as figures rather than running text.
+func onlyImages(inlines []markdown.Inline) ([]markdown.Inline, bool) {
+    var images []markdown.Inline
+    for _, in := range inlines {
+        switch {
+        case in.Kind == markdown.IKImage:
+            images = append(images, in)
+        case in.Kind == markdown.IKLineBreak:
+        case in.Kind == markdown.IKText && len(strings.TrimSpace(in.Text)) == 0:
+        default:
+            return nil, false
+        }
+    }
+    return images, len(images) != 0
+}
+
+func (t *typesetter) heading(b *markdown.Block) {
+    var size = headingSizes[min(max(b.Level, 1), 6)]
+    var leading = size * 1.3
+    var lines = wrap(t.words(b.Inlines, style{bold: true, size: size, color: textColor}), t.width())
+
+    t.gap(size * 0.6)
+    // Keep the heading together with the first lines under it
+    t.ensure(float64(len(lines))*leading + size*0.35 + bodyLeading*2)
+    for _, l := range lines {
+        t.drawLine(l, t.left, t.advance(leading, size))
+    }
+    t.gap(size * 0.35)
+}
+
+func (t *typesetter) code(literal string) {
+    const pad = 8.0
+    var st = style{mono: true, size: codeSize, color: textColor}
+    var width = t.width() - 2*pad
+
+    var lines []line
+    for _, src := range strings.Split(strings.TrimSuffix(literal, "\n"), "\n") {
+        if len(strings.TrimSpace(src)) == 0 {
+            lines = append(lines, line{})
+            continue
+        }
+        // Each source line is one word so its indentation survives, lines
+        // too long for the page are split by character.
+        var w = word{segs: []segment{{text: strings.ReplaceAll(src, "\t", "    "), font: st.font(), size: codeSize, color: textColor}}}
+        w.width = w.segs[0].width()
+        if w.width <= width {
+            lines = append(lines, line{words: []word{w}})
+            continue
+        }
+        for _, part := range splitWord(w, width) {
+            lines = append(lines, line{words: []word{part}})
+        }
+    }
+
+    t.ensure(pad + codeLeading*float64(min(len(lines), 3)))
+    var left, right = t.left, t.right
+    var d = t.begin(func(p *Page, top, bottom float64) {
+        p.Rect(left, bottom, right-left, top-bottom, codeBackground)
+    })
+    t.y -= pad
+    for _, l := range lines {
+        t.drawLine(l, left+pad, t.advance(codeLeading, codeSize))
+    }
+    t.y -= pad
+    t.end(d)
+}
+
+func (t *typesetter) quote(b *markdown.Block) {
+    var bar = ruleColor
+    var alert, isAlert = alerts[b.Alert]
+    if isAlert {
+        bar = alert.color
+    }
+
+    var left = t.left
+    var d = t.begin(func(p *Page, top, bottom float64) {
+        p.Rect(left, bottom, 3, top-bottom, bar)
+    })
+    var color = t.color
+    t.left += 14
+    t.color = mutedColor
+
+    if isAlert {
+        t.text(plainWords(alert.label, style{bold: true, size: bodySize, color: alert.color}), bodySize, bodyLeading)
+        t.color = textColor
+    }
+    t.blocks(b.Children)
+
+    t.left = left
+    t.color = color
+    t.end(d)
+}
+
+func (t *typesetter) list(b *markdown.Block) {
+    var spacing = t.spacing
+    t.spacing = listGap
+
+    var number = b.Start
+    var indent = 18.0
+    if b.Ordered {
+        indent = max(indent, FontRegular.Width(fmt.Sprintf("%d.", number+len(b.Children)-1), bodySize)+8)
+    }
+
+    for i, item := range b.Children {
+        if i != 0 {
+            t.gap(listGap)
+        }
+
+        var m = marker{text: "•", font: FontRegular, x: t.left + 4}
+        switch {
+        case item.Checked != nil:
+            m.text, m.font, m.x = "[ ]", FontMono, t.left
+            if *item.Checked {
+                m.text = "[x]"
+            }
+            indent = max(indent, FontMono.Width("[x]", bodySize)+6)
+        case b.Ordered:
+            m.text = fmt.Sprintf("%d.", number+i)
+            m.x = t.left + indent - 5 - FontRegular.Width(m.text, bodySize)
+        }
+        t.markers = append(t.markers, m)
+
+        t.left += indent
+        t.blocks(item.Children)
+        t.left -= indent
+
+        // An empty item still gets its marker
+        if len(t.markers) != 0 {
+            t.advance(bodyLeading, bodySize)
+        }
+    }
+
+    t.spacing = spacing
+}
+
+func (t *typesetter) rule() {
+    t.ensure(bodyLeading)
+    var y = t.y - bodyLeading/2
+    t.page.Line(t.left, y, t.right, y, 0.75, ruleColor)
+    t.y -= bodyLeading
+}
+
+func (t *typesetter) table(b *markdown.Block) {
+    const pad = 5.0
+    const leading = tableSize * 1.4
+    if len(b.Rows) == 0 {
+        return
+    }
+
+    var cols = len(b.Rows[0])
+    var cells = make([][][]word, len(b.Rows))
+    var natural = make([]float64, cols)
+    var longest = make([]float64, cols)
+    for r, row := range b.Rows {
+        cells[r] = make([][]word, cols)
+        for c := 0; c < cols && c < len(row); c++ {
+            var words = t.words(row[c], style{bold: r == 0, size: tableSize, color: textColor})
+            cells[r][c] = words
+
+            var lineWidth float64
+            for _, w := range words {
+                longest[c] = max(longest[c], w.width)
+                if w.brk {
+                    lineWidth = 0
+                    continue
+                }
+                lineWidth += w.width + w.space
+                natural[c] = max(natural[c], lineWidth)
+            }
+        }
+    }
+
+    // Columns get their natural width when the table fits, otherwise the
+    // width is shared out in proportion to it.
+    var widths = make([]float64, cols)
+    var total float64
+    for c := range natural {
+        natural[c] += 2 * pad
+        total += natural[c]
+    }
+    for c := range widths {
+        widths[c] = natural[c]
+        if total > t.width() {
+            widths[c] = max(t.width()*natural[c]/total, min(longest[c]+2*pad, t.width()/float64(cols)))
+        }
+    }
+    var tableWidth float64
+    for _, w := range widths {
+        tableWidth += w
+    }
+    if tableWidth > t.width() {
+        for c := range widths {
+            widths[c] *= t.width() / tableWidth
+        }
+        tableWidth = t.width()
+    }
+
+    var drawRow func(r int)
+    drawRow = func(r int) {
+        var lines = make([][]line, cols)
+        var height float64
+        for c := range cols {
+            lines[c] = wrap(cells[r][c], widths[c]-2*pad)
+            height = max(height, float64(len(lines[c]))*leading)
+        }
+        height += 2 * pad
+
+        if t.y-height < marginBottom && !t.atPageTop() {
+            t.newPage()
+            // Repeat the header on every page the table spans
+            if r != 0 {
+                drawRow(0)
+            }
+        }
+
+        var top = t.y
+        var left = t.left
+        if r == 0 {
+            t.page.Rect(left, top-height, tableWidth, height, tableBackground)
+        }
+        t.drawMarkers(top - pad - (leading-tableSize)/2 - tableSize*0.8)
+
+        var x = left
+        for c := range cols {
+            var align = markdown.AlignNone
+            if c < len(b.Align) {
+                align = b.Align[c]
+            }
+            for i, l := range lines[c] {
+                var baseline = top - pad - float64(i)*leading - (leading-tableSize)/2 - tableSize*0.8
+                var lx = x + pad
+                switch align {
+                case markdown.AlignCenter:
+                    lx += (widths[c] - 2*pad - l.width) / 2
+                case markdown.AlignRight:
+                    lx += widths[c] - 2*pad - l.width
+                }
+                t.drawLine(l, lx, baseline)
+            }
+            t.page.Line(x, top, x, top-height, 0.5, ruleColor)
+            x += widths[c]
+        }
+        t.page.Line(x, top, x, top-height, 0.5, ruleColor)
+        t.page.Line(left, top, left+tableWidth, top, 0.5, ruleColor)
+        t.page.Line(left, top-height, left+tableWidth, top-height, 0.5, ruleColor)
+        t.y -= height
+    }
+
+    for r := range b.Rows {
+        drawRow(r)
+    }
+}
+
+func (t *typesetter) loadImage(src string) *Image {
+    if img, ok := t.images[src]; ok {
+        return img
+    }
+
+    var img *Image
+    if t.opts.LoadImage != nil {
+        if data, err := t.opts.LoadImage(src); err == nil {
+            img, _ = t.doc.AddImage(data)
+        }
+    }
+    t.images[src] = img
+    return img
+}
+
+func (t *typesetter) image(in markdown.Inline) {
+    var alt = markdown.PlainText(in.Children)
+    var caption = style{italic: true, size: captionSize, color: mutedColor}
+
+    var img = t.loadImage(in.URL)
+    if img == nil {
+        if len(alt) != 0 {
+            t.centered(plainWords("["+alt+"]", caption), captionSize, captionSize*1.4)
+        }
+        return
+    }
+
+    // Pixels are taken to be at 96 dpi
+    var w, h = float64(img.Width) * 0.75, float64(img.Height) * 0.75
+    var maxHeight = (t.doc.Height - marginTop - marginBottom) * 0.7
+    var scale = min(1, t.width()/w, maxHeight/h)
+    w, h = w*scale, h*scale
+
+    t.ensure(h)
+    t.drawMarkers(t.y - bodySize)
+    t.page.Image(img, t.left+(t.width()-w)/2, t.y-h, w, h)
+    t.y -= h
+
+    if len(alt) != 0 {
+        t.y -= 4
+        t.centered(plainWords(alt, caption), captionSize, captionSize*1.4)
+    }
+}
+
+func (t *typesetter) titlePage(book *types.ExportBook) {
+    t.newPage()
+
+    if len(book.Site) != 0 {
+        t.text(plainWords(book.Site, style{size: 10, color: mutedColor}), 10, 14)
+    }
+
+    t.y -= 150
+    t.text(plainWords(book.Title, style{bold: true, size: 28, color: textColor}), 28, 34)
+    if len(book.Description) != 0 {
+        t.y -= 10
+        t.text(plainWords(book.Description, style{size: 13, color: mutedColor}), 13, 19)
+    }
+
+    t.y -= 24
+    t.page.Line(t.left, t.y, t.right, t.y, 0.75, ruleColor)
+    t.y -= 16
+
+    var field = func(label, value, uri string) {
+        if len(value) == 0 {
+            return
+        }
+        var words = plainWords(label+":", style{bold: true, size: 10, color: mutedColor})
+        var st = style{size: 10, color: textColor, uri: uri}
+        if len(uri) != 0 {
+            st.color = linkColor
+        }
+        var rest = plainWords(value, st)
+        if len(rest) != 0 {
+            rest[0].spaced = true
+        }
+        t.text(append(words, rest...), 10, 16)
+    }
+
+    field("Author", book.Author, "")
+    if !book.Published.IsZero() {
+        field("Published", book.Published.Format("January 2, 2006"), "")
+    }
+    if book.Updated.After(book.Published) {
+        field("Updated", book.Updated.Format("January 2, 2006"), "")
+    }
+    field("Language", book.Lang, "")
+    field("Source", book.URL, book.URL)
+
+    if len(book.Chapters) > 1 {
+        field("Parts", fmt.Sprint(len(book.Chapters)), "")
+
+        t.y -= 24
+        t.text(plainWords("Contents", style{bold: true, size: 13, color: textColor}), 13, 20)
+        for i, chapter := range book.Chapters {
+            var words = plainWords(fmt.Sprintf("%d.", i+1), style{size: bodySize, color: mutedColor})
+            var title = plainWords(chapter.Title, style{size: bodySize, color: textColor})
+            if len(title) != 0 {
+                title[0].spaced = true
+            }
+            t.text(append(words, title...), bodySize, bodyLeading)
+        }
+    }
+}
+
+func (t *typesetter) chapterHeading(part int, chapter *types.ExportChapter) {
+    t.text(plainWords(fmt.Sprintf("Part %d", part), style{bold: true, size: 10, color: mutedColor}), 10, 16)
+    t.text(plainWords(chapter.Title, style{bold: true, size: 22, color: textColor}), 22, 28)
+    if len(chapter.Excerpt) != 0 {
+        t.y -= 4
+        t.text(plainWords(chapter.Excerpt, style{italic: true, size: bodySize, color: mutedColor}), bodySize, bodyLeading)
+    }
+    t.y -= 10
+    t.page.Line(t.left, t.y, t.right, t.y, 0.75, ruleColor)
+    t.y -= 18
+}
+
+// footers adds the running title and page numbers once the page count is
+// known. The title page goes without.
+func (t *typesetter) footers(title string) {
+    const size = 8.0
+    var pages = t.doc.Pages()
+    var y = marginBottom / 2
+
+    for i, p := range pages {
+        if i == 0 {
+            continue
+        }
+        var number = fmt.Sprintf("%d / %d", i+1, len(pages))
+        var numberWidth = FontRegular.Width(number, size)
+
+        // Cut the title short rather than run into the page number
+        var room = t.right - t.left - numberWidth - 24
+        var runes = []rune(title)
+        var text = title
+        for len(runes) != 0 && FontRegular.Width(text, size) > room {
+            runes = runes[:len(runes)-1]
+            text = strings.TrimSpace(string(runes)) + "…"
+        }
+
+        p.Line(marginX, y+12, t.doc.Width-marginX, y+12, 0.5, ruleColor)
+        p.Text(marginX, y, FontRegular, size, mutedColor, text)
+        p.Text(t.doc.Width-marginX-numberWidth, y, FontRegular, size, mutedColor, number)
+    }
+}
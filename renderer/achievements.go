@@ -8,12 +8,8 @@ import (
     "io"
     "os"
     "path/filepath"
-    "strconv"
-    "strings"
     "time"
 
-    "git.jelius.dev/jelius-sama/Portfolio/api/analytics"
-    "git.jelius.dev/jelius-sama/Portfolio/api/blogs"
     "git.jelius.dev/jelius-sama/Portfolio/template/pages"
     "git.jelius.dev/jelius-sama/Portfolio/types"
     "github.com/gofiber/fiber/v3"
@@ -58,22 +54,17 @@ func (v *ViewManager) RenderAchievements(c fiber.Ctx) error {
         logger.Error(c.Path(), err.Error())
         return fiber.NewError(fiber.StatusInternalServerError, "Internal Server Error")
     } else {
-        var stream io.ReadCloser = io.NopCloser(strings.NewReader("achievements"))
-        if err := blogs.GetBlogMarkdown(nil, &stream); err != nil {
+        var stream, err = v.Blogs.OpenMarkdown("achievements")
+        if err != nil {
             logger.Error("Failed to fetch markdown content:", err.Error())
             return fiber.NewError(fiber.StatusInternalServerError, "Internal Server Error")
         }
         defer stream.Close()
 
-        var viewCountBuf strings.Builder
-        viewCountBuf.WriteString(c.Path())
-        if err := analytics.GetPageVisitCount(nil, &viewCountBuf); err != nil {
-            logger.Error(c.Path(), err)
-            viewCountBuf.Reset()
-            viewCountBuf.WriteString("0")
+        var views, viewsErr = v.Analytics.PageVisitCount(c.RequestCtx(), c.Path())
+        if viewsErr != nil {
+            logger.Error(c.Path(), viewsErr)
         }
-
-        var views, _ = strconv.Atoi(viewCountBuf.String())
         var filePath = filepath.Join(types.EVDataDir.Get().Value, "blogs", "achievements.md")
         var stats basicFileStat
 
@@ -91,7 +82,7 @@ func (v *ViewManager) RenderAchievements(c fiber.Ctx) error {
             Sequel:      nil,
             Title:       "My Achievements",
             Excerpt:     "A chronological record of my academic, professional, and personal achievements.\nThis page will be continuously updated as I progress through my journey.",
-            Views:       uint(views),
+            Views:       views,
         }
 
         var markdownContent string
@@ -99,9 +90,11 @@ func (v *ViewManager) RenderAchievements(c fiber.Ctx) error {
             logger.Error("Failed to read markdown content:", err.Error())
             return fiber.NewError(fiber.StatusInternalServerError, "Internal Server Error")
         } else {
-            markdownContent = string(content)
+            var math map[string]string
+            markdownContent, math = renderMath(c, string(content))
             c.Locals("context", "achievement")
-            return Renderer(c, metadata, pages.BlogPost(c, &achievementMetadata, &markdownContent))
+            c.Locals("pageview_path", "/achievements")
+            return Renderer(c, metadata, pages.BlogPost(c, &achievementMetadata, &markdownContent, math))
         }
 
     }
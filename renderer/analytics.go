@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package renderer
+
+import (
+    "errors"
+    "time"
+
+    "git.jelius.dev/jelius-sama/Portfolio/admin"
+    "git.jelius.dev/jelius-sama/Portfolio/template/pages"
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+
+    "github.com/a-h/templ"
+    "github.com/gofiber/fiber/v3"
+    "github.com/jelius-sama/logger"
+)
+
+// RenderAnalytics is the analytics dashboard, or the form to sign in to it
+// for anyone who hasn't.
+func (v *ViewManager) RenderAnalytics(c fiber.Ctx) error {
+    if !admin.HasSession(c) {
+        return renderAnalyticsSignIn(c, "")
+    }
+
+    var args pages.AnalyticsArgs
+    if dashboard, err := v.Analytics.Dashboard(c); err != nil {
+        var badRange *fiber.Error
+        if !errors.As(err, &badRange) {
+            logger.Error(c.Path(), err.Error())
+            return fiber.ErrInternalServerError
+        }
+
+        args = pages.AnalyticsArgs{
+            Error:    badRange.Message,
+            From:     c.Query("from"),
+            To:       c.Query("to"),
+            TimeZone: c.Query("tz"),
+            Bots:     c.Query("include_bots") == "1",
+        }
+        c.Status(badRange.Code)
+    } else {
+        args = pages.AnalyticsArgs{
+            Dashboard: dashboard,
+            From:      dashboard.From.Format(time.DateOnly),
+            // To isn't part of the range, the day before it is the last
+            To:       dashboard.To.Add(-time.Nanosecond).Format(time.DateOnly),
+            TimeZone: dashboard.Location.String(),
+            Bots:     dashboard.Bots,
+        }
+    }
+
+    return renderAnalyticsPage(c, pages.Analytics(args))
+}
+
+// AnalyticsLogin signs in to the dashboard with the token posted by its
+// sign in form.
+func (v *ViewManager) AnalyticsLogin(c fiber.Ctx) error {
+    if !admin.ValidToken(c.FormValue("token")) {
+        c.Status(fiber.StatusUnauthorized)
+        return renderAnalyticsSignIn(c, "That's not the admin token.")
+    }
+
+    admin.StartSession(c)
+    return c.Redirect().Status(fiber.StatusSeeOther).To("/analytics")
+}
+
+func (v *ViewManager) AnalyticsLogout(c fiber.Ctx) error {
+    admin.EndSession(c)
+    return c.Redirect().Status(fiber.StatusSeeOther).To("/analytics")
+}
+
+func renderAnalyticsSignIn(c fiber.Ctx, message string) error {
+    return renderAnalyticsPage(c, pages.AnalyticsSignIn(message, len(types.EVAdminToken.Get().Value) != 0))
+}
+
+func renderAnalyticsPage(c fiber.Ctx, body templ.Component) error {
+    c.Locals("pseudo_path", "*")
+    if metadata, err := GetMetadata(c); err != nil {
+        logger.Error(c.Path(), err.Error())
+        return fiber.ErrInternalServerError
+    } else {
+        c.Locals("title", "Analytics | Jelius")
+        c.Locals("description", "Visits to the site over time.")
+        GetDynamicRouteMetadata(c, metadata)
+        noIndex(metadata)
+        return Renderer(c, metadata, body)
+    }
+}
@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package renderer
+
+import (
+    "database/sql"
+    "errors"
+    "fmt"
+
+    "git.jelius.dev/jelius-sama/Portfolio/template/pages"
+    "git.jelius.dev/jelius-sama/Portfolio/types"
+    "github.com/gofiber/fiber/v3"
+    "github.com/jelius-sama/logger"
+)
+
+// RenderAuthor is the page of an author, listing the posts they wrote.
+func (v *ViewManager) RenderAuthor(c fiber.Ctx) error {
+    var author, err = v.Blogs.Author(c.RequestCtx(), c.Params("handle"))
+    if err != nil {
+        if errors.Is(err, sql.ErrNoRows) {
+            return fiber.ErrNotFound
+        }
+        logger.Error(c.Path(), err.Error())
+        return fiber.ErrInternalServerError
+    }
+
+    posts, err := v.Blogs.AuthorPosts(c.RequestCtx(), author.Handle)
+    if err != nil {
+        logger.Error(c.Path(), err.Error())
+        return fiber.ErrInternalServerError
+    }
+
+    c.Locals("pseudo_path", "*")
+    c.Locals("canonical_path", author.Path())
+    c.Locals("authors", []types.Author{*author})
+    c.Locals("pageview_path", author.Path())
+    if metadata, err := GetMetadata(c); err != nil {
+        logger.Error(c.Path(), err.Error())
+        return fiber.ErrInternalServerError
+    } else {
+        var description = author.Bio
+        if len(description) == 0 {
+            description = fmt.Sprintf("Blog posts by %s.", author.Name)
+        }
+
+        c.Locals("title", fmt.Sprintf("%s | Jelius", author.Name))
+        c.Locals("description", description)
+        GetDynamicRouteMetadata(c, metadata)
+        return Renderer(c, metadata, pages.Author(author, posts))
+    }
+}
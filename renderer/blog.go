@@ -4,14 +4,14 @@
 package renderer
 
 import (
-    "bytes"
     "database/sql"
-    "encoding/gob"
+    "errors"
     "fmt"
     "io"
-    "strings"
+    "net/url"
 
     "git.jelius.dev/jelius-sama/Portfolio/api/blogs"
+    "git.jelius.dev/jelius-sama/Portfolio/markdown"
     "git.jelius.dev/jelius-sama/Portfolio/template/pages"
     "git.jelius.dev/jelius-sama/Portfolio/types"
     "github.com/gofiber/fiber/v3"
@@ -19,30 +19,54 @@ import (
 )
 
 func (v *ViewManager) RenderBlog(c fiber.Ctx) error {
-    var buf = bytes.NewBufferString(c.Params("id"))
-    if err := blogs.GetBlog(nil, buf); err != nil {
+    var notFound = func() error {
+        c.Locals("pseudo_path", "#not_found")
+        if metadata, err := GetMetadata(c); err != nil {
+            logger.Error(c.Path(), err.Error())
+            return fiber.NewError(fiber.StatusInternalServerError, "Internal Server Error")
+        } else {
+            return Renderer(c, metadata, pages.BlogPost(c, nil, nil, nil))
+        }
+    }
+
+    // A signed `?preview=` shows drafts too. The HTMX cache is bypassed for
+    // these, and nothing further down the line may keep them either.
+    var preview = len(c.Query("preview")) != 0
+    var post *types.BlogResponse
+    var err error
+    if preview {
+        c.Set(fiber.HeaderCacheControl, "no-store")
+        if err = blogs.VerifyPreview(c.RequestCtx(), c.Params("id"), c.Query("preview")); err == nil {
+            post, err = v.Blogs.GetDraft(c.RequestCtx(), c.Params("id"))
+        } else if errors.Is(err, blogs.ErrInvalidPreview) {
+            err = sql.ErrNoRows
+        }
+    } else {
+        post, err = v.Blogs.Get(c.RequestCtx(), c.Params("id"))
+    }
+    if err != nil {
         if err == sql.ErrNoRows {
-            c.Locals("pseudo_path", "#not_found")
-            if metadata, metadataErr := GetMetadata(c); metadataErr != nil {
-                logger.Error(c.Path(), err.Error())
-                return fiber.NewError(fiber.StatusInternalServerError, "Internal Server Error")
-            } else {
-                return Renderer(c, metadata, pages.BlogPost(c, nil, nil))
-            }
+            return notFound()
         }
         logger.Error("Failed to fetch blog post data:", err.Error())
         return fiber.NewError(fiber.StatusInternalServerError, "Internal Server Error")
     }
 
-    var decodedResponse types.BlogResponse
-    if decErr := gob.NewDecoder(buf).Decode(&decodedResponse); decErr != nil {
-        logger.Error("Failed to decode Gob data:", decErr.Error())
-        return fiber.NewError(fiber.StatusInternalServerError, "Internal Server Error")
+    // /:lang/blog/:id has to exist in that language, a `?lang=` we don't
+    // have falls back to negotiating like a plain /blog/:id would.
+    var lang, _ = blogs.NormalizeLang(c.Params("lang"))
+    if len(c.Params("lang")) != 0 {
+        if !blogs.ApplyTranslation(post, lang) {
+            return notFound()
+        }
+    } else if lang, _ = blogs.NormalizeLang(c.Query("lang")); !blogs.ApplyTranslation(post, lang) {
+        lang = blogs.NegotiateLanguage(c, post)
+        blogs.ApplyTranslation(post, lang)
     }
 
-    var stream io.ReadCloser = io.NopCloser(strings.NewReader(c.Params("id")))
-    if err := blogs.GetBlogMarkdown(nil, &stream); err != nil {
-        logger.Error("Failed to fetch markdown content:", err.Error())
+    var stream, streamErr = v.Blogs.OpenMarkdown(blogs.MarkdownName(post, lang))
+    if streamErr != nil {
+        logger.Error("Failed to fetch markdown content:", streamErr.Error())
         return fiber.NewError(fiber.StatusInternalServerError, "Internal Server Error")
     }
     defer stream.Close()
@@ -52,19 +76,68 @@ func (v *ViewManager) RenderBlog(c fiber.Ctx) error {
         logger.Error("Failed to read markdown content:", err.Error())
         return fiber.NewError(fiber.StatusInternalServerError, "Internal Server Error")
     } else {
-        markdownContent = string(content)
+        var math map[string]string
+        markdownContent, math = renderMath(c, string(content))
         c.Locals("context", "blog")
         c.Locals("pseudo_path", "*")
+        c.Locals("lang", lang)
+        c.Locals("canonical_path", post.LocalizedPath(lang))
+        c.Locals("authors", post.Authors)
+        if !preview {
+            // Every language variant counts as a view of the one post
+            c.Locals("pageview_path", "/blog/"+post.ID)
+        }
 
         if metadata, metadataErr := GetMetadata(c); metadataErr != nil {
             logger.Error(c.Path(), metadataErr.Error())
             return fiber.NewError(fiber.StatusInternalServerError, "Internal Server Error")
         } else {
-            c.Locals("title", fmt.Sprintf("%s | Jelius", decodedResponse.Title))
-            c.Locals("description", decodedResponse.Excerpt)
+            c.Locals("title", fmt.Sprintf("%s | Jelius", post.Title))
+            c.Locals("description", post.Excerpt)
             GetDynamicRouteMetadata(c, metadata)
-            return Renderer(c, metadata, pages.BlogPost(c, &decodedResponse, &markdownContent))
+            if preview {
+                noIndex(metadata)
+            } else {
+                appendLanguageAlternates(metadata, post)
+            }
+            return Renderer(c, metadata, pages.BlogPost(c, post, &markdownContent, math))
         }
     }
 }
 
+// appendLanguageAlternates points search engines at every language variant
+// of post, the original doubling as the x-default.
+func appendLanguageAlternates(metadata *types.Metadata, post *types.BlogResponse) {
+    if len(post.Translations) == 0 {
+        return
+    }
+
+    var host, err = url.Parse(types.EVHostname.Get().Value)
+    if err != nil {
+        logger.Error(err)
+        return
+    }
+
+    for _, lang := range post.Languages() {
+        metadata.Links = append(metadata.Links, types.MLink{
+            Rel:      "alternate",
+            Href:     host.JoinPath(post.LocalizedPath(lang)).String(),
+            Hreflang: new(lang),
+        })
+    }
+    metadata.Links = append(metadata.Links, types.MLink{
+        Rel:      "alternate",
+        Href:     host.JoinPath(post.LocalizedPath(post.Lang)).String(),
+        Hreflang: new("x-default"),
+    })
+}
+
+// renderMath swaps math in content for placeholders the client renderer
+// replaces with MathML, logging every expression that had to stay as source.
+func renderMath(c fiber.Ctx, content string) (string, map[string]string) {
+    var out, math, warnings = markdown.RenderMath(content)
+    for _, w := range warnings {
+        logger.Warning(c.Path(), fmt.Sprintf("line %d: math %s left as source: %s", w.Line, w.Source, w.Err))
+    }
+    return out, math
+}
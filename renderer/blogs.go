@@ -4,10 +4,6 @@
 package renderer
 
 import (
-    "bytes"
-    "encoding/gob"
-
-    "git.jelius.dev/jelius-sama/Portfolio/api/blogs"
     "git.jelius.dev/jelius-sama/Portfolio/template/pages"
     "git.jelius.dev/jelius-sama/Portfolio/types"
 
@@ -20,25 +16,20 @@ func (v *ViewManager) RenderBlogs(c fiber.Ctx) error {
         logger.Error(c.Path(), err.Error())
         return fiber.NewError(fiber.StatusInternalServerError, "Internal Server Error")
     } else {
-        var buf = bytes.NewBufferString("page=1sort=0")
-        if err := blogs.GetAllBlogs(nil, buf); err != nil {
-            logger.Error(c.Path(), err.Error())
-            return fiber.NewError(fiber.StatusInternalServerError, "Internal Server Error")
-        }
-
-        var decodedResponse types.PaginatedBlogsResponse
-        if err := gob.NewDecoder(buf).Decode(&decodedResponse); err != nil {
+        var resp, err = v.Blogs.List(c.RequestCtx(), 1, types.BSONew)
+        if err != nil {
             logger.Error(c.Path(), err.Error())
             return fiber.NewError(fiber.StatusInternalServerError, "Internal Server Error")
         }
 
+        c.Locals("pageview_path", "/blogs")
         return Renderer(c, metadata, pages.Blogs(pages.BlogsSectionArgs{
-            Post:        decodedResponse.Data,
-            HasMore:     decodedResponse.HasMore,
-            TotalPosts:  decodedResponse.TotalRows,
-            LoadedPages: decodedResponse.Page,
-            TotalPages:  max(1, (decodedResponse.TotalRows+decodedResponse.Limit-1)/decodedResponse.Limit),
-            Sort:        decodedResponse.Sort,
+            Post:        resp.Data,
+            HasMore:     resp.HasMore,
+            TotalPosts:  resp.TotalRows,
+            LoadedPages: resp.Page,
+            TotalPages:  max(1, (resp.TotalRows+resp.Limit-1)/resp.Limit),
+            Sort:        resp.Sort,
         }))
     }
 }
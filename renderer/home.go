@@ -16,6 +16,10 @@ import (
     "github.com/jelius-sama/logger"
 )
 
+// featuredPostCount is how many featured posts the home page has room for,
+// one row of cards.
+const featuredPostCount = 3
+
 func getHomePageData(ctx context.Context) (pages.HomeData, error) {
     var homeData pages.HomeData
     var homePageID int
@@ -232,12 +236,16 @@ func (v *ViewManager) RenderHome(c fiber.Ctx) error {
         logger.Error(c.Path(), err.Error())
         return fiber.NewError(fiber.StatusInternalServerError, "Internal Server Error")
     } else {
-        if data, err := getHomePageData(c.RequestCtx()); err == nil {
-            return Renderer(c, metadata, pages.Home(data))
-        } else {
+        var data, err = getHomePageData(c.RequestCtx())
+        if err == nil {
+            data.FeaturedPosts, err = v.Blogs.Featured(c.RequestCtx(), featuredPostCount)
+        }
+        if err != nil {
             logger.Error(c.Path(), err.Error())
             return fiber.NewError(fiber.StatusInternalServerError, "Internal Server Error")
         }
+        c.Locals("pageview_path", "/")
+        return Renderer(c, metadata, pages.Home(data))
     }
 }
 
@@ -96,6 +96,7 @@ func (v *ViewManager) RenderLinks(c fiber.Ctx) error {
         return fiber.NewError(fiber.StatusInternalServerError, "Internal Server Error")
     } else {
         if data, err := getLinksPageData(c.RequestCtx()); err == nil {
+            c.Locals("pageview_path", "/links")
             return Renderer(c, metadata, pages.Links(data))
         } else {
             logger.Error(c.Path(), err.Error())
@@ -174,6 +174,22 @@ func GetDynamicRouteMetadata(c fiber.Ctx, metadata *types.Metadata) {
         // }
     }
 
+    var canonicalPath = c.Path()
+    if cp, ok := c.Locals("canonical_path").(string); ok && len(cp) != 0 {
+        canonicalPath = cp
+    }
+
+    // Pages about a post or an author credit them, everything else (and
+    // posts without authors) the site owner
+    var authors, _ = c.Locals("authors").([]types.Author)
+    var creator = "@jelius_sama"
+    if len(authors) != 0 {
+        creator = ""
+        if len(authors[0].Twitter) != 0 {
+            creator = "@" + authors[0].Twitter
+        }
+    }
+
     metadata.Title = c.Locals("title").(string)
     metadata.Description = c.Locals("description").(string)
     metadata.Meta = append(metadata.Meta,
@@ -188,23 +204,37 @@ func GetDynamicRouteMetadata(c fiber.Ctx, metadata *types.Metadata) {
 
         types.MMeta{Property: new("og:title"), Content: c.Locals("title").(string)},
         types.MMeta{Property: new("og:description"), Content: c.Locals("description").(string)},
-        types.MMeta{Property: new("og:url"), Content: host.JoinPath(c.Path()).String()},
+        types.MMeta{Property: new("og:url"), Content: host.JoinPath(canonicalPath).String()},
         types.MMeta{Property: new("og:site_name"), Content: "Jelius Basumatary"},
         types.MMeta{Property: new("og:image"), Content: "/compressed/jelius.webp"},
         types.MMeta{Property: new("og:type"), Content: "article"},
 
         types.MMeta{Name: new("twitter:card"), Content: "summary"},
         types.MMeta{Name: new("twitter:site"), Content: "@jelius_sama"},
-        types.MMeta{Name: new("twitter:creator"), Content: "@jelius_sama"},
         types.MMeta{Name: new("twitter:title"), Content: c.Locals("title").(string)},
         types.MMeta{Name: new("twitter:description"), Content: c.Locals("description").(string)},
         types.MMeta{Name: new("twitter:image"), Content: "/compressed/jelius.webp"},
     )
+    if len(creator) != 0 {
+        metadata.Meta = append(metadata.Meta, types.MMeta{Name: new("twitter:creator"), Content: creator})
+    }
+    for _, a := range authors {
+        metadata.Meta = append(metadata.Meta, types.MMeta{Property: new("article:author"), Content: host.JoinPath(a.Path()).String()})
+    }
 
     metadata.Links = append(metadata.Links,
-        types.MLink{Rel: "canonical", Href: host.JoinPath(c.Path()).String()},
+        types.MLink{Rel: "canonical", Href: host.JoinPath(canonicalPath).String()},
     )
 
     preProcessMetadata(metadata)
 }
 
+// noIndex keeps search engines away from a page that only makes sense to
+// whoever got the link.
+func noIndex(metadata *types.Metadata) {
+    for i := range metadata.Meta {
+        if metadata.Meta[i].Name != nil && *metadata.Meta[i].Name == "robots" {
+            metadata.Meta[i].Content = "noindex, nofollow"
+        }
+    }
+}
@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package renderer
+
+import (
+    "context"
+    "errors"
+
+    "git.jelius.dev/jelius-sama/Portfolio/newsletter"
+    "git.jelius.dev/jelius-sama/Portfolio/template/pages"
+    "github.com/gofiber/fiber/v3"
+    "github.com/jelius-sama/logger"
+)
+
+// RenderNewsletterConfirm is where the link in a confirmation mail leads.
+func (v *ViewManager) RenderNewsletterConfirm(c fiber.Ctx) error {
+    return renderNewsletterAction(c, newsletter.Confirm,
+        "newsletter confirm",
+        "Subscription confirmed, new posts will land in your inbox.",
+        "Confirm your subscription | Jelius",
+    )
+}
+
+// RenderNewsletterUnsubscribe is where the unsubscribe link in a newsletter
+// mail leads.
+func (v *ViewManager) RenderNewsletterUnsubscribe(c fiber.Ctx) error {
+    return renderNewsletterAction(c, newsletter.Unsubscribe,
+        "newsletter unsubscribe",
+        "You're unsubscribed and won't get any more mails.",
+        "Unsubscribe | Jelius",
+    )
+}
+
+func renderNewsletterAction(c fiber.Ctx, action func(context.Context, string) error, command, done, title string) error {
+    var message, ok = done, true
+    if err := action(c.RequestCtx(), c.Params("token")); err != nil {
+        if !errors.Is(err, newsletter.ErrInvalidToken) {
+            logger.Error(c.Path(), err.Error())
+            return fiber.ErrInternalServerError
+        }
+        message, ok = "This link is invalid or has expired.", false
+        c.Status(fiber.StatusNotFound)
+    }
+
+    c.Locals("pseudo_path", "*")
+    if metadata, err := GetMetadata(c); err != nil {
+        logger.Error(c.Path(), err.Error())
+        return fiber.ErrInternalServerError
+    } else {
+        c.Locals("title", title)
+        c.Locals("description", "Manage your subscription to new blog posts.")
+        GetDynamicRouteMetadata(c, metadata)
+        noIndex(metadata)
+        return Renderer(c, metadata, pages.NewsletterStatus(command, message, ok))
+    }
+}
@@ -6,6 +6,8 @@ package renderer
 import (
     "strings"
 
+    "git.jelius.dev/jelius-sama/Portfolio/api/analytics"
+    "git.jelius.dev/jelius-sama/Portfolio/api/blogs"
     "git.jelius.dev/jelius-sama/Portfolio/template"
     "git.jelius.dev/jelius-sama/Portfolio/template/components"
     "git.jelius.dev/jelius-sama/Portfolio/types"
@@ -15,10 +17,13 @@ import (
     "github.com/jelius-sama/logger"
 )
 
-type ViewManager struct{}
+type ViewManager struct {
+    Blogs     *blogs.BlogService
+    Analytics *analytics.AnalyticsService
+}
 
-func New() *ViewManager {
-    return &ViewManager{}
+func New(blogService *blogs.BlogService, analyticsService *analytics.AnalyticsService) *ViewManager {
+    return &ViewManager{Blogs: blogService, Analytics: analyticsService}
 }
 
 func Renderer(c fiber.Ctx, metadata *types.Metadata, bodyContent templ.Component) error {
@@ -47,6 +52,9 @@ func Renderer(c fiber.Ctx, metadata *types.Metadata, bodyContent templ.Component
                 logger.Error(err)
                 return fiber.NewError(fiber.StatusInternalServerError, "Internal Server Error")
             }
+
+            c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+            return c.SendString(buf.String())
         case types.TPBoth:
             if err := bodyContent.Render(c.RequestCtx(), &buf); err != nil {
                 return fiber.NewError(fiber.StatusInternalServerError, "Internal Server Error")
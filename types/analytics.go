@@ -3,10 +3,17 @@
 
 package types
 
+import (
+    "encoding/json"
+    "time"
+)
+
 type TopCountryResponse struct {
-    CountryCode string  `json:"country_code"`
-    VisitCount  int     `json:"visit_count"`
-    Percentage  float64 `json:"percentage"`
+    CountryCode    string  `json:"country_code"`
+    VisitCount     int     `json:"visit_count"`
+    UniqueVisitors int     `json:"unique_visitors"`
+    Sessions       int     `json:"sessions"`
+    Percentage     float64 `json:"percentage"`
 }
 
 type PaginatedTopCountriesResponse struct {
@@ -38,6 +45,8 @@ type AnalyticsEventResponse struct {
     CountryCode  string `json:"country_code"`
     PagePath     string `json:"page_path"`
     TimestampUTC string `json:"timestamp"`
+    // Bot is only ever set with `include_bots=1`
+    Bot bool `json:"bot,omitempty"`
 }
 
 type PaginatedEventsResponse struct {
@@ -48,10 +57,88 @@ type PaginatedEventsResponse struct {
     TotalRows int                      `json:"total_rows"`
 }
 
+// ExportFormat is what analytics events are exported as.
+type ExportFormat uint8
+
+const (
+    EFCSV ExportFormat = iota
+    EFNDJSON
+)
+
+func (ef ExportFormat) String() string {
+    switch ef {
+    case EFCSV:
+        return "csv"
+    case EFNDJSON:
+        return "ndjson"
+    default:
+        return ""
+    }
+}
+
+func (ef ExportFormat) MarshalText() ([]byte, error) {
+    return []byte(ef.String()), nil
+}
+
+// AnalyticsEventExport is one event of an export, with everything recorded
+// for it. Fields that weren't recorded are null, or empty in CSV: visitors
+// and sessions for the oldest events, where the visit came from for all but
+// its landing page, and engagement for pageviews the tracking script didn't
+// report on. BotReason and BotDetail are only set for bots.
+type AnalyticsEventExport struct {
+    EventID        int64          `json:"event_id"`
+    TimestampUTC   string         `json:"timestamp"`
+    PagePath       string         `json:"page_path"`
+    CountryCode    string         `json:"country_code"`
+    VisitorID      *string        `json:"visitor_id"`
+    SessionID      *string        `json:"session_id"`
+    Referrer       *string        `json:"referrer"`
+    ReferrerKind   *ReferrerKind  `json:"referrer_kind"`
+    UTMSource      *string        `json:"utm_source"`
+    UTMMedium      *string        `json:"utm_medium"`
+    UTMCampaign    *string        `json:"utm_campaign"`
+    UTMTerm        *string        `json:"utm_term"`
+    UTMContent     *string        `json:"utm_content"`
+    Browser        *string        `json:"browser"`
+    BrowserVersion *int           `json:"browser_version"`
+    OS             *string        `json:"os"`
+    Device         *DeviceClass   `json:"device"`
+    Source         PageviewSource `json:"source"`
+    EngagedSeconds *int           `json:"engaged_seconds"`
+    MaxScroll      *int           `json:"max_scroll"`
+    Bot            bool           `json:"bot"`
+    BotReason      *BotReason     `json:"bot_reason,omitempty"`
+    BotDetail      *string        `json:"bot_detail,omitempty"`
+}
+
 type TopPageResponse struct {
-    PagePath   string  `json:"page_path"`
-    VisitCount int     `json:"visit_count"`
-    Percentage float64 `json:"percentage"`
+    PagePath       string  `json:"page_path"`
+    VisitCount     int     `json:"visit_count"`
+    UniqueVisitors int     `json:"unique_visitors"`
+    Sessions       int     `json:"sessions"`
+    Percentage     float64 `json:"percentage"`
+    PageEngagement
+}
+
+// PageStatsResponse counts the views of a page. Visitors are told apart per
+// UTC day only, someone coming back the next day counts again.
+type PageStatsResponse struct {
+    PagePath       string `json:"page_path"`
+    VisitCount     uint   `json:"visit_count"`
+    UniqueVisitors uint   `json:"unique_visitors"`
+    Sessions       uint   `json:"sessions"`
+    PageEngagement
+}
+
+// PageEngagement is how the views of a page were engaged with, out of the
+// EngagedViews the tracking script reported on. ReadRate is the percentage
+// of them scrolled at least 75% of the way down, the share of readers who
+// got to the end of a post.
+type PageEngagement struct {
+    EngagedViews      int     `json:"engaged_views"`
+    AvgEngagedSeconds float64 `json:"avg_engaged_seconds"`
+    AvgScrollDepth    float64 `json:"avg_scroll_depth"`
+    ReadRate          float64 `json:"read_rate"`
 }
 
 type PaginatedTopPagesResponse struct {
@@ -63,8 +150,364 @@ type PaginatedTopPagesResponse struct {
 }
 
 type TrackAnalyticsRequest struct {
+    // Type is a TrackType, a pageview when it is left out
+    Type         string `json:"type"`
     UserTimeZone string `json:"user_time_zone"`
     PagePath     string `json:"page_path"`
+    // Referrer and the UTM parameters are only sent with the first pageview
+    // of a visit, navigating within the site doesn't change where it came
+    // from. Referrer is empty rather than missing for direct visits.
+    Referrer    *string `json:"referrer"`
+    UTMSource   string  `json:"utm_source"`
+    UTMMedium   string  `json:"utm_medium"`
+    UTMCampaign string  `json:"utm_campaign"`
+    UTMTerm     string  `json:"utm_term"`
+    UTMContent  string  `json:"utm_content"`
+    // Webdriver is `navigator.webdriver`, set in browsers under automation
+    Webdriver bool `json:"webdriver"`
+    // EngagedSeconds and MaxScroll are what an engagement report is of: how
+    // long the page has been looked at and how far down it has been scrolled,
+    // in percent, since it was viewed
+    EngagedSeconds int `json:"engaged_seconds"`
+    MaxScroll      int `json:"max_scroll"`
+    // Name and Properties are the custom event that was sent, properties
+    // are optional but have to be in the event's schema
+    Name       string         `json:"name"`
+    Properties map[string]any `json:"properties"`
+    // PageviewID is what TrackAnalyticsResponse answered a pageview with,
+    // engagement reports are for that pageview and custom events go in its
+    // session
+    PageviewID string `json:"pageview_id"`
+}
+
+// TrackAnalyticsResponse answers a pageview the tracking script reported.
+type TrackAnalyticsResponse struct {
+    PageviewID string `json:"pageview_id"`
+}
+
+// TrackType is what the tracking script reports.
+type TrackType uint8
+
+const (
+    // TTPageview is for a view of a page
+    TTPageview TrackType = iota
+    // TTEngagement is for how a page that was viewed has been read since
+    TTEngagement
+    // TTEvent is for a custom event, such as a click on an outbound link
+    TTEvent
+)
+
+func (tt TrackType) String() string {
+    switch tt {
+    case TTPageview:
+        return "pageview"
+    case TTEngagement:
+        return "engagement"
+    case TTEvent:
+        return "event"
+    default:
+        return ""
+    }
+}
+
+func (tt TrackType) MarshalText() ([]byte, error) {
+    return []byte(tt.String()), nil
+}
+
+// ReferrerKind groups where a visit came from.
+type ReferrerKind uint8
+
+const (
+    RKDirect ReferrerKind = iota
+    RKSearch
+    RKSocial
+    RKWebsite
+)
+
+func (rk ReferrerKind) String() string {
+    switch rk {
+    case RKDirect:
+        return "direct"
+    case RKSearch:
+        return "search"
+    case RKSocial:
+        return "social"
+    case RKWebsite:
+        return "website"
+    default:
+        return ""
+    }
+}
+
+func (rk ReferrerKind) MarshalText() ([]byte, error) {
+    return []byte(rk.String()), nil
+}
+
+// TopReferrerResponse is one source of traffic. Referrer is the name of the
+// search engine or social network, the host of any other website, and empty
+// for direct visits.
+type TopReferrerResponse struct {
+    Referrer       string       `json:"referrer"`
+    Kind           ReferrerKind `json:"kind"`
+    VisitCount     int          `json:"visit_count"`
+    UniqueVisitors int          `json:"unique_visitors"`
+    Sessions       int          `json:"sessions"`
+    Percentage     float64      `json:"percentage"`
+}
+
+type PaginatedTopReferrersResponse struct {
+    Data      []TopReferrerResponse `json:"data"`
+    Page      int                   `json:"page"`
+    Limit     int                   `json:"limit"`
+    HasMore   bool                  `json:"has_more"`
+    TotalRows int                   `json:"total_rows"`
+}
+
+type TopCampaignResponse struct {
+    Source         string  `json:"utm_source"`
+    Medium         string  `json:"utm_medium"`
+    Campaign       string  `json:"utm_campaign"`
+    VisitCount     int     `json:"visit_count"`
+    UniqueVisitors int     `json:"unique_visitors"`
+    Sessions       int     `json:"sessions"`
+    Percentage     float64 `json:"percentage"`
+}
+
+type PaginatedTopCampaignsResponse struct {
+    Data      []TopCampaignResponse `json:"data"`
+    Page      int                   `json:"page"`
+    Limit     int                   `json:"limit"`
+    HasMore   bool                  `json:"has_more"`
+    TotalRows int                   `json:"total_rows"`
+}
+
+// DeviceClass is the kind of device a visit was made with.
+type DeviceClass uint8
+
+const (
+    DCDesktop DeviceClass = iota
+    DCMobile
+    DCTablet
+    DCBot
+    // DCTerminal is for text browsers and command line clients, which read
+    // pages without running scripts
+    DCTerminal
+)
+
+func (dc DeviceClass) String() string {
+    switch dc {
+    case DCDesktop:
+        return "desktop"
+    case DCMobile:
+        return "mobile"
+    case DCTablet:
+        return "tablet"
+    case DCBot:
+        return "bot"
+    case DCTerminal:
+        return "terminal"
+    default:
+        return ""
+    }
+}
+
+func (dc DeviceClass) MarshalText() ([]byte, error) {
+    return []byte(dc.String()), nil
+}
+
+// TopBrowserResponse is one browser, or one major version of it when
+// versions are asked for. Browser is empty for visits recorded before
+// browsers were.
+type TopBrowserResponse struct {
+    Browser        string  `json:"browser"`
+    Version        *int    `json:"version,omitempty"`
+    VisitCount     int     `json:"visit_count"`
+    UniqueVisitors int     `json:"unique_visitors"`
+    Sessions       int     `json:"sessions"`
+    Percentage     float64 `json:"percentage"`
+}
+
+type PaginatedTopBrowsersResponse struct {
+    Data      []TopBrowserResponse `json:"data"`
+    Page      int                  `json:"page"`
+    Limit     int                  `json:"limit"`
+    HasMore   bool                 `json:"has_more"`
+    TotalRows int                  `json:"total_rows"`
+}
+
+type TopOSResponse struct {
+    OS             string  `json:"os"`
+    VisitCount     int     `json:"visit_count"`
+    UniqueVisitors int     `json:"unique_visitors"`
+    Sessions       int     `json:"sessions"`
+    Percentage     float64 `json:"percentage"`
+}
+
+type PaginatedTopOSResponse struct {
+    Data      []TopOSResponse `json:"data"`
+    Page      int             `json:"page"`
+    Limit     int             `json:"limit"`
+    HasMore   bool            `json:"has_more"`
+    TotalRows int             `json:"total_rows"`
+}
+
+type DeviceResponse struct {
+    Device         DeviceClass `json:"device"`
+    VisitCount     int         `json:"visit_count"`
+    UniqueVisitors int         `json:"unique_visitors"`
+    Sessions       int         `json:"sessions"`
+    Percentage     float64     `json:"percentage"`
+}
+
+type PaginatedDevicesResponse struct {
+    Data      []DeviceResponse `json:"data"`
+    Page      int              `json:"page"`
+    Limit     int              `json:"limit"`
+    HasMore   bool             `json:"has_more"`
+    TotalRows int              `json:"total_rows"`
+}
+
+// BotReason is why a hit was taken for a bot and kept out of the reports.
+type BotReason uint8
+
+const (
+    // BRDenylist is for addresses in BOT_IP_DENYLIST
+    BRDenylist BotReason = iota
+    // BRUserAgent is for User-Agents of known crawlers and HTTP clients
+    BRUserAgent
+    // BRHeadless is for automated and headless browsers
+    BRHeadless
+    // BRRate is for addresses sending more hits than a person could
+    BRRate
+)
+
+func (br BotReason) String() string {
+    switch br {
+    case BRDenylist:
+        return "denylist"
+    case BRUserAgent:
+        return "user_agent"
+    case BRHeadless:
+        return "headless"
+    case BRRate:
+        return "rate"
+    default:
+        return ""
+    }
+}
+
+func (br BotReason) MarshalText() ([]byte, error) {
+    return []byte(br.String()), nil
+}
+
+// PageviewSource is how a pageview was recorded.
+type PageviewSource uint8
+
+const (
+    // PSBeacon is for pageviews the tracking script sent
+    PSBeacon PageviewSource = iota
+    // PSServer is for full-page loads recorded by the server itself
+    PSServer
+    // PSConfirmed is for PSServer pageviews the tracking script then sent as
+    // well, which were merged into them rather than counted twice
+    PSConfirmed
+)
+
+func (ps PageviewSource) String() string {
+    switch ps {
+    case PSBeacon:
+        return "beacon"
+    case PSServer:
+        return "server"
+    case PSConfirmed:
+        return "confirmed"
+    default:
+        return ""
+    }
+}
+
+func (ps PageviewSource) MarshalText() ([]byte, error) {
+    return []byte(ps.String()), nil
+}
+
+// TimeseriesInterval is how long each bucket of a time series is.
+type TimeseriesInterval uint8
+
+const (
+    TIHour TimeseriesInterval = iota
+    TIDay
+    TIWeek
+    TIMonth
+)
+
+func (ti TimeseriesInterval) String() string {
+    switch ti {
+    case TIHour:
+        return "hour"
+    case TIDay:
+        return "day"
+    case TIWeek:
+        return "week"
+    case TIMonth:
+        return "month"
+    default:
+        return ""
+    }
+}
+
+func (ti TimeseriesInterval) MarshalText() ([]byte, error) {
+    return []byte(ti.String()), nil
+}
+
+// TimeseriesGroup is the dimension a time series is split by.
+type TimeseriesGroup uint8
+
+const (
+    TGNone TimeseriesGroup = iota
+    TGPage
+    TGCountry
+    TGReferrer
+)
+
+func (tg TimeseriesGroup) String() string {
+    switch tg {
+    case TGNone:
+        return ""
+    case TGPage:
+        return "page"
+    case TGCountry:
+        return "country"
+    case TGReferrer:
+        return "referrer"
+    default:
+        return ""
+    }
+}
+
+func (tg TimeseriesGroup) MarshalText() ([]byte, error) {
+    return []byte(tg.String()), nil
+}
+
+// TimeseriesResponse is a series of pageviews and unique visitors per
+// bucket, one for every value of the dimension it is grouped by. Every
+// series has a value for every bucket, zero where there was no traffic.
+type TimeseriesResponse struct {
+    Interval TimeseriesInterval `json:"interval"`
+    GroupBy  TimeseriesGroup    `json:"group_by,omitempty"`
+    // Buckets are the start of each bucket, in the requested time zone
+    Buckets []time.Time        `json:"buckets"`
+    Series  []TimeseriesSeries `json:"series"`
+}
+
+// TimeseriesSeries is one line of a TimeseriesResponse. Key is the value of
+// the dimension, empty when the series isn't grouped and for direct visits
+// when it is grouped by referrer. Other is the series the values outside the
+// top ones are added up in.
+type TimeseriesSeries struct {
+    Key       string `json:"key"`
+    Other     bool   `json:"other,omitempty"`
+    Pageviews []int  `json:"pageviews"`
+    Uniques   []int  `json:"uniques"`
 }
 
 type SortOrder uint8
@@ -74,3 +517,84 @@ const (
     SODesc
 )
 
+// AnalyticsDashboard is everything the analytics dashboard shows for a
+// range of time. Visits, Visitors and Sessions add up the days of the range
+// like the top reports do.
+type AnalyticsDashboard struct {
+    // From and To are the range in Location, To itself excluded
+    From     time.Time
+    To       time.Time
+    Location *time.Location
+    Bots     bool
+
+    Visits   int
+    Visitors int
+    Sessions int
+
+    Series       *TimeseriesResponse
+    TopPages     []TopPageResponse
+    TopCountries []TopCountryResponse
+    TopReferrers []TopReferrerResponse
+}
+
+// LivePageview is a pageview as the live feed shows it, with nothing that
+// tells visitors apart.
+type LivePageview struct {
+    PagePath    string    `json:"page_path"`
+    CountryCode string    `json:"country_code"`
+    Timestamp   time.Time `json:"timestamp"`
+}
+
+// LiveActiveResponse is how many visitors the live feed counts as active,
+// those with a pageview in the last WindowSeconds.
+type LiveActiveResponse struct {
+    Active        int `json:"active"`
+    WindowSeconds int `json:"window_seconds"`
+}
+
+// PropertyType is the type a property of a custom event has to be of.
+type PropertyType uint8
+
+const (
+    PTString PropertyType = iota
+    PTNumber
+    PTBool
+)
+
+func (pt PropertyType) String() string {
+    switch pt {
+    case PTString:
+        return "string"
+    case PTNumber:
+        return "number"
+    case PTBool:
+        return "bool"
+    default:
+        return ""
+    }
+}
+
+func (pt PropertyType) MarshalText() ([]byte, error) {
+    return []byte(pt.String()), nil
+}
+
+// CustomEventResponse counts the custom events of one name that had one
+// value of one of their properties. Property is empty and Value left out for
+// the events that were sent without any. Percentage is of all the events of
+// that name.
+type CustomEventResponse struct {
+    Event          string          `json:"event"`
+    Property       string          `json:"property"`
+    Value          json.RawMessage `json:"value,omitempty"`
+    Count          int             `json:"count"`
+    UniqueVisitors int             `json:"unique_visitors"`
+    Percentage     float64         `json:"percentage"`
+}
+
+type PaginatedCustomEventsResponse struct {
+    Data      []CustomEventResponse `json:"data"`
+    Page      int                   `json:"page"`
+    Limit     int                   `json:"limit"`
+    HasMore   bool                  `json:"has_more"`
+    TotalRows int                   `json:"total_rows"`
+}
@@ -0,0 +1,35 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package types
+
+// Author is someone posts are credited to. Posts without any are by the
+// site owner, as they were before authors existed.
+type Author struct {
+    Handle string `json:"handle"`
+    Name   string `json:"name"`
+    Bio    string `json:"bio,omitempty"`
+    Avatar string `json:"avatar,omitempty"`
+    // Social handles, Twitter without the leading @ and Mastodon as
+    // `user@instance`
+    Twitter  string `json:"twitter,omitempty"`
+    GitHub   string `json:"github,omitempty"`
+    Mastodon string `json:"mastodon,omitempty"`
+    Website  string `json:"website,omitempty"`
+}
+
+// Path is the page listing the author's posts.
+func (a *Author) Path() string {
+    return "/authors/" + a.Handle
+}
+
+type CreateAuthor struct {
+    Handle   string `json:"handle" form:"handle"`
+    Name     string `json:"name" form:"name"`
+    Bio      string `json:"bio" form:"bio"`
+    Avatar   string `json:"avatar" form:"avatar"`
+    Twitter  string `json:"twitter" form:"twitter"`
+    GitHub   string `json:"github" form:"github"`
+    Mastodon string `json:"mastodon" form:"mastodon"`
+    Website  string `json:"website" form:"website"`
+}
@@ -9,10 +9,15 @@ type BlogsSortOrder uint8
 
 const PostPerPage = 5
 
+// DefaultBlogLang is the language a post is assumed to be written in when it
+// doesn't say otherwise.
+const DefaultBlogLang = "en"
+
 const (
     BSONew BlogsSortOrder = iota
     BSOOld
     BSOPopular
+    BSOTrending
 )
 
 func (bso BlogsSortOrder) String() string {
@@ -23,11 +28,22 @@ func (bso BlogsSortOrder) String() string {
         return "Oldest"
     case BSOPopular:
         return "Most Viewed"
+    case BSOTrending:
+        return "Trending"
     default:
         return ""
     }
 }
 
+func (bso BlogsSortOrder) IsValid() bool {
+    switch bso {
+    case BSONew, BSOOld, BSOPopular, BSOTrending:
+        return true
+    default:
+        return false
+    }
+}
+
 // BlogPost is one entry in the blog list.
 type BlogPost struct {
     ID          string     `json:"id"`
@@ -39,6 +55,9 @@ type BlogPost struct {
     Title       string     `json:"title"`
     Excerpt     string     `json:"excerpt"`
     Views       uint       `json:"views"`
+    // Pinned posts come first in the blog list whatever it is sorted by
+    Pinned   bool `json:"pinned,omitempty"`
+    Featured bool `json:"featured,omitempty"`
 }
 
 type BlogResponse struct {
@@ -52,13 +71,88 @@ type BlogResponse struct {
     Title   string        `json:"title"`
     Excerpt string        `json:"excerpt"`
     Views   uint          `json:"views"`
+    // Lang is the language the post was originally written in, ContentLang
+    // the one Title and Excerpt are currently in.
+    Lang         string            `json:"lang"`
+    ContentLang  string            `json:"content_lang"`
+    Translations []BlogTranslation `json:"translations,omitempty"`
+    // Authors is empty for posts by the site owner
+    Authors []Author `json:"authors,omitempty"`
+    // Draft posts are only reachable through a preview link
+    Draft bool `json:"draft,omitempty"`
+}
+
+// Languages lists every language the post can be read in, its own first.
+func (b *BlogResponse) Languages() []string {
+    var langs = []string{b.Lang}
+    for _, t := range b.Translations {
+        langs = append(langs, t.Lang)
+    }
+    return langs
+}
+
+// LocalizedPath returns the page path of the post in lang. The original
+// lives at /blog/:id, translations at /:lang/blog/:id.
+func (b *BlogResponse) LocalizedPath(lang string) string {
+    if len(lang) == 0 || lang == b.Lang {
+        return "/blog/" + b.ID
+    }
+    return "/" + lang + "/blog/" + b.ID
+}
+
+// Series returns every part of the series the post belongs to in reading
+// order: it rewinds to the earliest Prequel, then walks forward via Sequel.
+// A post that is not part of a series is a series of one.
+func (b *BlogResponse) Series() []*BlogResponse {
+    var before []*BlogResponse
+    for p := b.Prequel; p != nil; p = p.Prequel {
+        before = append(before, p)
+    }
+    for i, j := 0, len(before)-1; i < j; i, j = i+1, j-1 {
+        before[i], before[j] = before[j], before[i]
+    }
+
+    var after []*BlogResponse
+    for s := b.Sequel; s != nil; s = s.Sequel {
+        after = append(after, s)
+    }
+
+    var series = make([]*BlogResponse, 0, len(before)+1+len(after))
+    series = append(series, before...)
+    series = append(series, b)
+    series = append(series, after...)
+    return series
+}
+
+// BlogTranslation is one language variant of a post. Its markdown lives next
+// to the original as `blogs/<id>.<lang>.md`.
+type BlogTranslation struct {
+    Lang      string    `json:"lang"`
+    Title     string    `json:"title"`
+    Excerpt   string    `json:"excerpt"`
+    UpdatedAt time.Time `json:"updated_at"`
 }
 
 type CreateBlogPost struct {
-    Title     string  `json:"title"`
-    Excerpt   string  `json:"excerpt"`
-    PrequelID *string `json:"prequel_id"`
-    SequelID  *string `json:"sequel_id"`
+    Title     string  `json:"title" form:"title"`
+    Lang      string  `json:"lang" form:"lang"`
+    Excerpt   string  `json:"excerpt" form:"excerpt"`
+    PrequelID *string `json:"prequel_id" form:"prequel_id"`
+    SequelID  *string `json:"sequel_id" form:"sequel_id"`
+    // Authors are handles, the author first and co-authors after
+    Authors []string `json:"authors" form:"authors"`
+    // Draft keeps the post unlisted until it is published
+    Draft bool `json:"draft" form:"draft"`
+    // Pinned keeps the post at the top of the blog list, Featured puts it
+    // on the home page
+    Pinned   bool `json:"pinned" form:"pinned"`
+    Featured bool `json:"featured" form:"featured"`
+}
+
+type CreateBlogTranslation struct {
+    Lang    string `json:"lang"`
+    Title   string `json:"title"`
+    Excerpt string `json:"excerpt"`
 }
 
 type PaginatedBlogsResponse struct {
@@ -70,3 +164,9 @@ type PaginatedBlogsResponse struct {
     Sort      BlogsSortOrder `json:"sort"`
 }
 
+// PreviewLink is a signed link to a post that may not be published yet.
+type PreviewLink struct {
+    URL       string    `json:"url"`
+    ExpiresAt time.Time `json:"expires_at"`
+}
+
@@ -38,6 +38,20 @@ const (
     EVVersion
     EVPort
     EVDataDir
+    EVTrendingWindow
+    EVTrendingGravity
+    EVLinkCheckInterval
+    EVSMTPHost
+    EVSMTPPort
+    EVSMTPUsername
+    EVSMTPPassword
+    EVSMTPFrom
+    EVPreviewSecret
+    EVAdminToken
+    EVProxyHeader
+    EVBotIPDenylist
+    EVCountryHeader
+    EVAnalyticsRetention
 )
 
 func (ek EnvVal) Get() Env {
@@ -56,6 +70,34 @@ func (ek EnvVal) Get() Env {
         return Env{Key: "PORT", Value: os.Getenv("PORT")}
     case EVDataDir:
         return Env{Key: "DATA_DIR", Value: os.Getenv("DATA_DIR")}
+    case EVTrendingWindow:
+        return Env{Key: "TRENDING_WINDOW", Value: os.Getenv("TRENDING_WINDOW")}
+    case EVTrendingGravity:
+        return Env{Key: "TRENDING_GRAVITY", Value: os.Getenv("TRENDING_GRAVITY")}
+    case EVLinkCheckInterval:
+        return Env{Key: "LINK_CHECK_INTERVAL", Value: os.Getenv("LINK_CHECK_INTERVAL")}
+    case EVSMTPHost:
+        return Env{Key: "SMTP_HOST", Value: os.Getenv("SMTP_HOST")}
+    case EVSMTPPort:
+        return Env{Key: "SMTP_PORT", Value: os.Getenv("SMTP_PORT")}
+    case EVSMTPUsername:
+        return Env{Key: "SMTP_USERNAME", Value: os.Getenv("SMTP_USERNAME")}
+    case EVSMTPPassword:
+        return Env{Key: "SMTP_PASSWORD", Value: os.Getenv("SMTP_PASSWORD")}
+    case EVSMTPFrom:
+        return Env{Key: "SMTP_FROM", Value: os.Getenv("SMTP_FROM")}
+    case EVPreviewSecret:
+        return Env{Key: "PREVIEW_SECRET", Value: os.Getenv("PREVIEW_SECRET")}
+    case EVAdminToken:
+        return Env{Key: "ADMIN_TOKEN", Value: os.Getenv("ADMIN_TOKEN")}
+    case EVProxyHeader:
+        return Env{Key: "PROXY_HEADER", Value: os.Getenv("PROXY_HEADER")}
+    case EVBotIPDenylist:
+        return Env{Key: "BOT_IP_DENYLIST", Value: os.Getenv("BOT_IP_DENYLIST")}
+    case EVCountryHeader:
+        return Env{Key: "COUNTRY_HEADER", Value: os.Getenv("COUNTRY_HEADER")}
+    case EVAnalyticsRetention:
+        return Env{Key: "ANALYTICS_RETENTION_DAYS", Value: os.Getenv("ANALYTICS_RETENTION_DAYS")}
     default:
         return Env{Key: "", Value: ""}
     }
@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package types
+
+import "time"
+
+// ExportBook is what the offline exports (PDF, EPUB) are built from: a single
+// post is a book with one chapter, a series has one chapter per part.
+type ExportBook struct {
+    ID          string
+    Title       string
+    Description string
+    Author      string
+    Lang        string
+    // Site is the host name printed on the title page, URL the page the
+    // book was exported from.
+    Site      string
+    URL       string
+    Published time.Time
+    Updated   time.Time
+    Chapters  []ExportChapter
+}
+
+type ExportChapter struct {
+    ID        string
+    Title     string
+    Excerpt   string
+    Lang      string
+    URL       string
+    Published time.Time
+    Updated   time.Time
+    Markdown  string
+}
@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package types
+
+type LinkState uint8
+
+const (
+    LSOk LinkState = iota
+    LSRedirected
+    LSBroken
+)
+
+func (ls LinkState) String() string {
+    switch ls {
+    case LSOk:
+        return "ok"
+    case LSRedirected:
+        return "redirected"
+    case LSBroken:
+        return "broken"
+    default:
+        return ""
+    }
+}
+
+func (ls LinkState) MarshalText() ([]byte, error) {
+    return []byte(ls.String()), nil
+}
+
+type LinkSource uint8
+
+const (
+    LSrcBlog LinkSource = iota
+    LSrcLinkEntry
+    LSrcHomeProjectLink
+)
+
+func (ls LinkSource) String() string {
+    switch ls {
+    case LSrcBlog:
+        return "blog"
+    case LSrcLinkEntry:
+        return "link_entries"
+    case LSrcHomeProjectLink:
+        return "home_project_link"
+    default:
+        return ""
+    }
+}
+
+// LinkOccurrence is one place a checked URL appears on the site.
+// Line is only meaningful for markdown sources and is 0 otherwise.
+type LinkOccurrence struct {
+    SourceKind string `json:"source_kind"`
+    SourceID   string `json:"source_id"`
+    Line       int    `json:"line,omitempty"`
+}
+
+type LinkReportEntry struct {
+    URL         string           `json:"url"`
+    State       LinkState        `json:"state"`
+    StatusCode  int              `json:"status_code,omitempty"`
+    FinalURL    string           `json:"final_url,omitempty"`
+    Error       string           `json:"error,omitempty"`
+    CheckedAt   string           `json:"checked_at"`
+    Occurrences []LinkOccurrence `json:"occurrences"`
+}
+
+type LinkReportResponse struct {
+    Broken     []LinkReportEntry `json:"broken"`
+    Redirected []LinkReportEntry `json:"redirected"`
+}
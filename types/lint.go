@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package types
+
+type DiagnosticSeverity uint8
+
+const (
+    DSError DiagnosticSeverity = iota
+    DSWarning
+)
+
+func (ds DiagnosticSeverity) String() string {
+    switch ds {
+    case DSError:
+        return "error"
+    case DSWarning:
+        return "warning"
+    default:
+        return ""
+    }
+}
+
+func (ds DiagnosticSeverity) MarshalText() ([]byte, error) {
+    return []byte(ds.String()), nil
+}
+
+// Diagnostic is one problem found in an uploaded markdown file. Line is
+// 1-based and 0 when the problem isn't tied to a line, like the file size or
+// the series a post is added to.
+type Diagnostic struct {
+    Severity DiagnosticSeverity `json:"severity"`
+    Rule     string             `json:"rule"`
+    Line     int                `json:"line,omitempty"`
+    Message  string             `json:"message"`
+}
+
+// LintErrorResp rejects an upload over its content, listing everything that
+// was found, warnings included, so it can all be fixed in one go.
+type LintErrorResp struct {
+    Code        int          `json:"code"`
+    Message     string       `json:"message"`
+    Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// UploadResp answers an accepted markdown upload.
+type UploadResp struct {
+    ID       string       `json:"id"`
+    Warnings []Diagnostic `json:"warnings"`
+}
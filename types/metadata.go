@@ -6,9 +6,10 @@ package types
 import "encoding/xml"
 
 type MLink struct {
-    Rel   string  `json:"rel"`
-    Href  string  `json:"href"`
-    Media *string `json:"media"`
+    Rel      string  `json:"rel"`
+    Href     string  `json:"href"`
+    Media    *string `json:"media"`
+    Hreflang *string `json:"hreflang"`
 }
 
 type MMeta struct {
@@ -26,16 +27,25 @@ type Metadata struct {
 }
 
 type SiteMapURLEntry struct {
-    Loc        string `xml:"loc"`
-    LastMod    string `xml:"lastmod,omitempty"`
-    ChangeFreq string `xml:"changefreq,omitempty"`
-    Priority   string `xml:"priority,omitempty"`
+    Loc        string             `xml:"loc"`
+    LastMod    string             `xml:"lastmod,omitempty"`
+    ChangeFreq string             `xml:"changefreq,omitempty"`
+    Priority   string             `xml:"priority,omitempty"`
+    Alternates []SiteMapAlternate `xml:"xhtml:link"`
+}
+
+// SiteMapAlternate points a sitemap entry at the same page in another language.
+type SiteMapAlternate struct {
+    Rel      string `xml:"rel,attr"`
+    Hreflang string `xml:"hreflang,attr"`
+    Href     string `xml:"href,attr"`
 }
 
 type SiteMapURLSet struct {
-    XMLName xml.Name          `xml:"urlset"`
-    Xmlns   string            `xml:"xmlns,attr"`
-    URLs    []SiteMapURLEntry `xml:"url"`
+    XMLName    xml.Name          `xml:"urlset"`
+    Xmlns      string            `xml:"xmlns,attr"`
+    XmlnsXhtml string            `xml:"xmlns:xhtml,attr,omitempty"`
+    URLs       []SiteMapURLEntry `xml:"url"`
 }
 
 type RobotsRule struct {
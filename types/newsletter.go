@@ -0,0 +1,30 @@
+// SPDX-License-Identifier: AGPL-3.0-or-later
+// Copyright (c) 2026 Jelius Basumatary
+
+package types
+
+// NewsletterMail is the kind of a queued newsletter mail.
+type NewsletterMail uint8
+
+const (
+    NMConfirm NewsletterMail = iota
+    NMPost
+)
+
+func (nm NewsletterMail) String() string {
+    switch nm {
+    case NMConfirm:
+        return "confirm"
+    case NMPost:
+        return "post"
+    default:
+        return ""
+    }
+}
+
+// NewsletterSignup is the body of the newsletter signup form.
+type NewsletterSignup struct {
+    Email string `json:"email" form:"email"`
+    // Website is a honeypot, people never see the field
+    Website string `json:"website" form:"website"`
+}
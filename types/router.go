@@ -14,13 +14,29 @@ const (
     MHStaticAsset
     MHHTMXCache
     MHStaticPages
+    MHDownloads
+    MHAdmin
+    MHPageviews
 )
 
 type MiddlewareHandlerMap map[MiddlewareHandler]fiber.Handler
 
+// TrackingMode is how the pageviews of a page are recorded.
+type TrackingMode uint8
+
+const (
+    // TMBeacon leaves it to the tracking script, so visitors without
+    // JavaScript aren't counted
+    TMBeacon TrackingMode = iota
+    // TMServer records full-page loads on the server as well, the tracking
+    // script's pageview for them is merged in rather than counted again
+    TMServer
+)
+
 type Page struct {
     Handler  any
     Handlers []any
+    Tracking TrackingMode
 }
 
 // Would contain all the register page routes